@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"baby-tracker-server/internal/postgres"
+)
+
+// envBool reads a boolean environment variable, falling back when unset.
+func envBool(name string, fallback bool) (bool, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// envDuration reads a duration (e.g. "30s") from the environment, falling
+// back when unset.
+func envDuration(name string, fallback time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// envPositiveInt reads a positive integer from the environment, falling
+// back when unset.
+func envPositiveInt(name string, fallback int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// envPositiveFloat reads a number greater than zero, returning fallback
+// when name is unset.
+func envPositiveFloat(name string, fallback float64) (float64, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed <= 0 || math.IsInf(parsed, 0) {
+		return 0, fmt.Errorf("%s must be a positive number, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// envLogLevel reads a slog level name (debug, info, warn, error). An
+// invalid value returns fallback together with an error, so the caller
+// can warn and keep running.
+func envLogLevel(name string, fallback slog.Level) (slog.Level, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return fallback, fmt.Errorf("%s must be debug, info, warn or error, got %q", name, value)
+	}
+	return level, nil
+}
+
+// envPath reads an absolute URL path such as "/api" from the environment.
+// A trailing slash is dropped so the value can be joined with route paths.
+func envPath(name string) (string, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return "", nil
+	}
+
+	if !strings.HasPrefix(value, "/") || strings.ContainsAny(value, " {}") {
+		return "", fmt.Errorf("%s must be a path starting with /, got %q", name, value)
+	}
+	return strings.TrimRight(value, "/"), nil
+}
+
+// envSchema reads a Postgres schema name, falling back when unset. Only
+// names postgres.WithSchema accepts are allowed, so the value cannot
+// smuggle SQL into the search_path.
+func envSchema(name, fallback string) (string, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+
+	if !postgres.ValidSchemaName(value) {
+		return "", fmt.Errorf("%s must be a lowercase identifier of letters, digits and underscores, got %q", name, value)
+	}
+	return value, nil
+}
+
+// envDetailsCompression reads a postgres.DetailsCompressionMethods entry,
+// returning "" when name is unset.
+func envDetailsCompression(name string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(name)))
+	if value == "" || slices.Contains(postgres.DetailsCompressionMethods, value) {
+		return value, nil
+	}
+	return "", fmt.Errorf("%s must be one of %s, got %q", name, strings.Join(postgres.DetailsCompressionMethods, ", "), value)
+}
+
+// envList reads a comma-separated list from the environment. Entries are
+// trimmed and lowercased; an unset variable returns nil.
+func envList(name string) []string {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envRequiredFields reads a comma-separated list of type.field pairs, e.g.
+// "diaper.kind,diaper.notes", into the fields each event type requires.
+// server.Config.Validate checks the types and fields; an unset variable
+// returns nil.
+func envRequiredFields(name string) (map[string][]string, error) {
+	items := envList(name)
+	if items == nil {
+		return nil, nil
+	}
+
+	fields := make(map[string][]string)
+	for _, item := range items {
+		eventType, field, ok := strings.Cut(item, ".")
+		if !ok || eventType == "" || field == "" {
+			return nil, fmt.Errorf("%s entries must look like type.field, e.g. diaper.kind, got %q", name, item)
+		}
+		fields[eventType] = append(fields[eventType], field)
+	}
+	return fields, nil
+}
+
+// postgresSSLModes lists the sslmode values libpq and pgx understand.
+var postgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// envDatabaseURL returns DATABASE_URL, or, when it is unset, a URL assembled
+// from DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME and DB_SSLMODE.
+// The parts are escaped by net/url, so a password may hold any character.
+// It returns "" when neither form is set.
+func envDatabaseURL() (string, error) {
+	if value := strings.TrimSpace(os.Getenv("DATABASE_URL")); value != "" {
+		return value, nil
+	}
+
+	host := strings.TrimSpace(os.Getenv("DB_HOST"))
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+	name := os.Getenv("DB_NAME")
+	port := strings.TrimSpace(os.Getenv("DB_PORT"))
+	sslMode := strings.ToLower(strings.TrimSpace(os.Getenv("DB_SSLMODE")))
+	if host == "" && user == "" && password == "" && name == "" && port == "" && sslMode == "" {
+		return "", nil
+	}
+
+	if host == "" || user == "" || name == "" {
+		return "", errors.New("DB_HOST, DB_USER and DB_NAME must all be set when DATABASE_URL is not")
+	}
+	if port == "" {
+		port = "5432"
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("DB_PORT must be a number from 1 to 65535, got %q", port)
+	}
+	if sslMode != "" && !slices.Contains(postgresSSLModes, sslMode) {
+		return "", fmt.Errorf("DB_SSLMODE must be one of %s, got %q", strings.Join(postgresSSLModes, ", "), sslMode)
+	}
+
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.User(user),
+		Host:   net.JoinHostPort(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"), port),
+		Path:   "/" + name,
+	}
+	if password != "" {
+		u.User = url.UserPassword(user, password)
+	}
+	if sslMode != "" {
+		u.RawQuery = url.Values{"sslmode": {sslMode}}.Encode()
+	}
+	return u.String(), nil
+}
+
+// listenAddr combines BIND_ADDR and PORT into a listen address. An empty
+// host listens on every interface; IPv6 hosts may be given with or
+// without brackets.
+func listenAddr(host, port string) (string, error) {
+	host = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(host), "["), "]")
+	port = strings.TrimSpace(port)
+
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("PORT must be a number from 1 to 65535, got %q", port)
+	}
+	if host != "" && net.ParseIP(host) == nil && !validHostname(host) {
+		return "", fmt.Errorf("BIND_ADDR must be an IP address or host name, got %q", host)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// tlsFiles checks TLS_CERT_FILE and TLS_KEY_FILE. Both empty means plain
+// HTTP; otherwise both must name existing files, so a typo fails at
+// startup rather than on the first handshake.
+func tlsFiles(certFile, keyFile string) (string, string, error) {
+	certFile, keyFile = strings.TrimSpace(certFile), strings.TrimSpace(keyFile)
+	if certFile == "" && keyFile == "" {
+		return "", "", nil
+	}
+	if certFile == "" || keyFile == "" {
+		return "", "", errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	for name, path := range map[string]string{"TLS_CERT_FILE": certFile, "TLS_KEY_FILE": keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", "", fmt.Errorf("%s: %w", name, err)
+		}
+		if info.IsDir() {
+			return "", "", fmt.Errorf("%s must be a file, got directory %q", name, path)
+		}
+	}
+	return certFile, keyFile, nil
+}
+
+// validHostname accepts dot-separated labels of letters, digits and
+// hyphens, such as "localhost".
+func validHostname(host string) bool {
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -2,47 +2,249 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 	"time"
+	_ "time/tzdata"
 
+	"baby-tracker-server/internal/inmemory"
 	"baby-tracker-server/internal/postgres"
 	"baby-tracker-server/internal/server"
 )
 
 func main() {
-	addr := os.Getenv("PORT")
-	if addr == "" {
-		addr = "8080"
+	level, levelErr := envLogLevel("LOG_LEVEL", slog.LevelInfo)
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+	if levelErr != nil {
+		slog.Warn("falling back to INFO logging", "error", levelErr)
 	}
 
-	databaseURL := os.Getenv("DATABASE_URL")
-	if databaseURL == "" {
-		log.Fatal("DATABASE_URL is required")
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	addr, err := listenAddr(os.Getenv("BIND_ADDR"), port)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// Serve HTTPS directly when both TLS files are set, for deployments
+	// without a terminating proxy.
+	certFile, keyFile, err := tlsFiles(os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE"))
+	if err != nil {
+		fatal("invalid configuration", "error", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// STORE=memory runs without Postgres, keeping everything in process
+	// memory until it exits, for demos and client development.
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("STORE")))
+	if backend != "" && backend != "postgres" && backend != "memory" {
+		fatal("invalid configuration", "error", fmt.Errorf("STORE must be postgres or memory, got %q", backend))
+	}
 
-	store, err := postgres.New(ctx, databaseURL)
+	databaseURL, err := envDatabaseURL()
+	if err != nil && backend != "memory" {
+		fatal("invalid configuration", "error", err)
+	}
+	if databaseURL == "" && backend != "memory" {
+		fatal("DATABASE_URL, or DB_HOST, DB_USER and DB_NAME, is required")
+	}
+
+	var cfg server.Config
+	cfg.RequireUserAgent, err = envBool("REQUIRE_USER_AGENT", false)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.DemoMode, err = envBool("DEMO_MODE", false)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.SecurityHeaders, err = envBool("SECURITY_HEADERS", true)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.PathPrefix, err = envPath("PATH_PREFIX")
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.HealthzPath, err = envPath("HEALTHZ_PATH")
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.ReadyTimeout, err = envDuration("READY_TIMEOUT", time.Second)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.DuplicateWindow, err = envDuration("DUPLICATE_WINDOW", 0)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.MaxWeightChangePerDay, err = envPositiveFloat("WEIGHT_MAX_DAILY_CHANGE_PERCENT", 0)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.MinWeightKg, err = envPositiveFloat("WEIGHT_MIN_KG", 0)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.MaxWeightKg, err = envPositiveFloat("WEIGHT_MAX_KG", 0)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.ImportChunkSize, err = envPositiveInt("IMPORT_CHUNK_SIZE", 0)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	cfg.ReportTitle = strings.TrimSpace(os.Getenv("REPORT_TITLE"))
+	cfg.ReportFooter = strings.TrimSpace(os.Getenv("REPORT_FOOTER"))
+	cfg.EventTypes = envList("EVENT_TYPES")
+	cfg.RequiredFields, err = envRequiredFields("REQUIRED_FIELDS")
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// Server timeouts. ReadTimeout bounds the whole request including the
+	// body (event payloads are small; the NDJSON import, whose body can be
+	// large, moves its own deadlines as it reads), WriteTimeout covers
+	// handler time plus the response write (the PDF report is rendered in
+	// memory, so it fits the same budget; the streamed CSV and NDJSON
+	// exports move their own deadline instead) and IdleTimeout closes idle
+	// keep-alives.
+	readTimeout, err := envDuration("READ_TIMEOUT", 10*time.Second)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	writeTimeout, err := envDuration("WRITE_TIMEOUT", 30*time.Second)
 	if err != nil {
-		log.Fatalf("failed to initialize postgres store: %v", err)
+		fatal("invalid configuration", "error", err)
 	}
-	defer func() {
-		if err := store.Close(); err != nil {
-			log.Printf("failed to close postgres store: %v", err)
+	idleTimeout, err := envDuration("IDLE_TIMEOUT", 60*time.Second)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// Postgres may still be starting during deploys; postgres.New keeps
+	// retrying until this deadline.
+	connectTimeout, err := envDuration("DB_CONNECT_TIMEOUT", 30*time.Second)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// Each store call gets at most this long, so a hung database answers
+	// 504 instead of holding the request until the client gives up.
+	queryTimeout, err := envDuration("DB_QUERY_TIMEOUT", 3*time.Second)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// Events older than EVENT_RETENTION_DAYS are deleted in the
+	// background. Unset keeps every event forever. Expired idempotency
+	// keys are deleted on the same schedule either way.
+	retentionDays, err := envPositiveInt("EVENT_RETENTION_DAYS", 0)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	purgeInterval, err := envDuration("EVENT_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// Event changes fail when their audit row cannot be written, unless
+	// AUDIT_BEST_EFFORT trades the audit trail for availability.
+	bestEffortAudit, err := envBool("AUDIT_BEST_EFFORT", false)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// DB_SCHEMA keeps each tenant's tables in its own Postgres schema.
+	schema, err := envSchema("DB_SCHEMA", "public")
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// DETAILS_COMPRESSION has Postgres compress event details once a row
+	// grows past DETAILS_COMPRESSION_MIN_BYTES.
+	detailsCompression, err := envDetailsCompression("DETAILS_COMPRESSION")
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	detailsCompressionMinBytes, err := envPositiveInt("DETAILS_COMPRESSION_MIN_BYTES", 0)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	var store interface {
+		server.BabyStore
+		eventPurger
+	}
+	if backend == "memory" {
+		memory := inmemory.New()
+		for _, name := range []string{"Alice", "Bob", "Charlie"} {
+			memory.AddBaby(name)
+		}
+		slog.Warn("using the in-memory store, data will be lost on exit")
+		store = memory
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+		defer cancel()
+
+		storeOpts := []postgres.Option{postgres.WithQueryTimeout(queryTimeout), postgres.WithSchema(schema)}
+		if bestEffortAudit {
+			storeOpts = append(storeOpts, postgres.WithBestEffortAudit())
+		}
+		if detailsCompression != "" {
+			storeOpts = append(storeOpts, postgres.WithDetailsCompression(detailsCompression, detailsCompressionMinBytes))
+		}
+		pg, err := postgres.New(ctx, databaseURL, storeOpts...)
+		if err != nil {
+			fatal("failed to initialize postgres store", "error", err)
 		}
-	}()
+		defer func() {
+			if err := pg.Close(); err != nil {
+				slog.Error("failed to close postgres store", "error", err)
+			}
+		}()
+		store = pg
+	}
+
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+	if retentionDays > 0 {
+		slog.Info("event retention enabled", "days", retentionDays, "interval", purgeInterval)
+	}
+	go runEventPurge(purgeCtx, store, retention, purgeInterval)
 
 	srv := &http.Server{
-		Addr:              ":" + addr,
-		Handler:           server.NewRouter(store),
+		Addr:              addr,
+		Handler:           server.NewRouterWithConfig(store, cfg),
 		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
 	}
 
-	log.Printf("baby-tracker-server listening on %s", srv.Addr)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("server failed: %v", err)
+	if certFile != "" {
+		slog.Info("baby-tracker-server listening with TLS", "addr", srv.Addr)
+		err = srv.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		slog.Info("baby-tracker-server listening", "addr", srv.Addr)
+		err = srv.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		fatal("server failed", "error", err)
 	}
 }
+
+// fatal logs at error level and exits. log.Fatal would go through the
+// slog default at info level and disappear when LOG_LEVEL is higher.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// eventPurger deletes events that occurred before cutoff and idempotency
+// keys past their TTL.
+type eventPurger interface {
+	PurgeEventsOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// runEventPurge deletes expired idempotency keys and, unless retention is
+// zero, events older than retention, once at start and then every
+// interval, until ctx is done. A failed run is logged and retried on the
+// next tick.
+func runEventPurge(ctx context.Context, store eventPurger, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if retention > 0 {
+			cutoff := time.Now().Add(-retention)
+			purged, err := store.PurgeEventsOlderThan(ctx, cutoff)
+			if err != nil {
+				slog.Error("event purge failed", "cutoff", cutoff, "error", err)
+			} else {
+				slog.Info("purged old events", "cutoff", cutoff, "rows", purged)
+			}
+		}
+
+		if purged, err := store.PurgeExpiredIdempotencyKeys(ctx); err != nil {
+			slog.Error("idempotency key purge failed", "error", err)
+		} else if purged > 0 {
+			slog.Info("purged expired idempotency keys", "rows", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
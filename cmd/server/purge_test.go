@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// recordingPurger records the purges runEventPurge asks for.
+type recordingPurger struct {
+	cutoffs []time.Time
+	keys    int
+}
+
+func (p *recordingPurger) PurgeEventsOlderThan(_ context.Context, cutoff time.Time) (int64, error) {
+	p.cutoffs = append(p.cutoffs, cutoff)
+	return 0, nil
+}
+
+func (p *recordingPurger) PurgeExpiredIdempotencyKeys(context.Context) (int64, error) {
+	p.keys++
+	return 0, nil
+}
+
+func TestRunEventPurge(t *testing.T) {
+	t.Parallel()
+
+	// A done context stops the loop after the run at start.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	withRetention := &recordingPurger{}
+	before := time.Now()
+	runEventPurge(ctx, withRetention, 48*time.Hour, time.Hour)
+	if len(withRetention.cutoffs) != 1 || withRetention.cutoffs[0].After(before.Add(-47*time.Hour)) {
+		t.Fatalf("expected one purge of events older than 48 hours, got %v", withRetention.cutoffs)
+	}
+	if withRetention.keys != 1 {
+		t.Fatalf("expected expired idempotency keys to be purged once, got %d", withRetention.keys)
+	}
+
+	keysOnly := &recordingPurger{}
+	runEventPurge(ctx, keysOnly, 0, time.Hour)
+	if len(keysOnly.cutoffs) != 0 {
+		t.Fatalf("expected events to be kept without retention, got purges at %v", keysOnly.cutoffs)
+	}
+	if keysOnly.keys != 1 {
+		t.Fatalf("expected expired idempotency keys to be purged without retention, got %d", keysOnly.keys)
+	}
+}
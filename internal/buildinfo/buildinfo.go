@@ -0,0 +1,50 @@
+// Package buildinfo reports what binary is running.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Commit and BuildTime are set at build time, e.g.
+//
+//	go build -ldflags "-X baby-tracker-server/internal/buildinfo.Commit=$(git rev-parse HEAD)"
+//
+// They are empty in builds that do not set them.
+var (
+	Commit    string
+	BuildTime string
+)
+
+// Info describes the running binary.
+type Info struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Read returns the build info. Fields not set with -ldflags fall back to
+// the VCS stamp the go command embeds when building inside a checkout;
+// the fallback build time is then the commit time. Fields are empty when
+// neither is available.
+func Read() Info {
+	info := Info{
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && info.Commit == "":
+			info.Commit = setting.Value
+		case setting.Key == "vcs.time" && info.BuildTime == "":
+			info.BuildTime = setting.Value
+		}
+	}
+	return info
+}
@@ -0,0 +1,23 @@
+package buildinfo_test
+
+import (
+	"runtime"
+	"testing"
+
+	"baby-tracker-server/internal/buildinfo"
+)
+
+func TestReadPrefersLinkerValues(t *testing.T) {
+	buildinfo.Commit = "abc123"
+	buildinfo.BuildTime = "2026-03-01T12:00:00Z"
+	t.Cleanup(func() {
+		buildinfo.Commit = ""
+		buildinfo.BuildTime = ""
+	})
+
+	got := buildinfo.Read()
+	want := buildinfo.Info{Commit: "abc123", BuildTime: "2026-03-01T12:00:00Z", GoVersion: runtime.Version()}
+	if got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
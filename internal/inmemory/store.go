@@ -0,0 +1,916 @@
+// Package inmemory implements server.BabyStore in process memory, for
+// tests and for running the server without Postgres. Everything is lost
+// when the process exits.
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"baby-tracker-server/internal/server"
+)
+
+// Store keeps babies, events and their audit trail behind one mutex. It is
+// safe for concurrent use. Records are copied in and out, so callers
+// cannot change stored data through a returned value.
+type Store struct {
+	mu          sync.RWMutex
+	babies      []server.Baby
+	events      []server.Event
+	nextEventID int64
+	keys        map[idempotencyKey]idempotencyClaim
+	audit       []auditEntry
+	now         func() time.Time
+}
+
+type idempotencyKey struct {
+	babyID int64
+	key    string
+}
+
+type idempotencyClaim struct {
+	eventID   int64
+	createdAt time.Time
+}
+
+type auditEntry struct {
+	babyID  int64
+	eventID int64
+	entry   server.EventAuditEntry
+}
+
+// details holds the event details fields the store reads.
+type details struct {
+	Notes           string     `json:"notes"`
+	Kind            string     `json:"kind"`
+	Side            string     `json:"side"`
+	DurationMinutes int        `json:"duration_minutes"`
+	StartAt         *time.Time `json:"start_at"`
+	EndAt           *time.Time `json:"end_at"`
+	WeightKg        *float64   `json:"weight_kg"`
+}
+
+// New returns an empty store.
+func New() *Store {
+	return &Store{
+		keys: make(map[idempotencyKey]idempotencyClaim),
+		now:  time.Now,
+	}
+}
+
+// AddBaby creates a baby. The API has no endpoint for it, so tests and the
+// server's startup seed babies through it.
+func (s *Store) AddBaby(name string) server.Baby {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addBaby(server.NewBabyInput{Name: name})
+}
+
+func (s *Store) addBaby(input server.NewBabyInput) server.Baby {
+	baby := cloneBaby(server.Baby{
+		ID:        int64(len(s.babies)) + 1,
+		Name:      input.Name,
+		CreatedAt: s.now(),
+		Color:     input.Color,
+		AvatarURL: input.AvatarURL,
+		Timezone:  input.Timezone,
+	})
+	s.babies = append(s.babies, baby)
+	return cloneBaby(baby)
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *Store) ListBabies(_ context.Context, filter server.BabyFilter) ([]server.Baby, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data := make([]server.Baby, 0, len(s.babies))
+	for _, baby := range s.babies {
+		if baby.DeletedAt == nil || filter.IncludeDeleted {
+			data = append(data, cloneBaby(baby))
+		}
+	}
+	slices.SortStableFunc(data, func(a, b server.Baby) int {
+		var c int
+		switch filter.Sort {
+		case server.BabySortName:
+			c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		case server.BabySortCreatedAt:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		c = cmp.Or(c, cmp.Compare(a.ID, b.ID))
+		if filter.Descending {
+			return -c
+		}
+		return c
+	})
+	return data, nil
+}
+
+func (s *Store) GetBaby(_ context.Context, id int64, filter server.BabyFilter) (server.Baby, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	baby := s.baby(id)
+	if baby == nil || (baby.DeletedAt != nil && !filter.IncludeDeleted) {
+		return server.Baby{}, server.ErrNotFound
+	}
+	return cloneBaby(*baby), nil
+}
+
+func (s *Store) UpdateBaby(_ context.Context, id int64, input server.UpdateBabyInput) (server.Baby, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	baby := s.baby(id)
+	if baby == nil || baby.DeletedAt != nil {
+		return server.Baby{}, server.ErrNotFound
+	}
+	if input.Name != nil {
+		baby.Name = *input.Name
+	}
+	if input.Color != nil {
+		baby.Color = nullIfEmpty(*input.Color)
+	}
+	if input.AvatarURL != nil {
+		baby.AvatarURL = nullIfEmpty(*input.AvatarURL)
+	}
+	if input.Timezone != nil {
+		baby.Timezone = nullIfEmpty(*input.Timezone)
+	}
+	return cloneBaby(*baby), nil
+}
+
+// DeleteBaby archives a baby. Its events are kept.
+func (s *Store) DeleteBaby(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	baby := s.baby(id)
+	if baby == nil || baby.DeletedAt != nil {
+		return server.ErrNotFound
+	}
+	now := s.now()
+	baby.DeletedAt = &now
+	return nil
+}
+
+// MergeBabies moves the source's events and their audit entries to the
+// target, auditing each move as an update, and archives the source.
+func (s *Store) MergeBabies(ctx context.Context, targetID, sourceID int64) (server.Baby, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, source := s.baby(targetID), s.baby(sourceID)
+	if target == nil || target.DeletedAt != nil || source == nil || source.DeletedAt != nil {
+		return server.Baby{}, server.ErrNotFound
+	}
+
+	for i := range s.audit {
+		if s.audit[i].babyID == sourceID {
+			s.audit[i].babyID = targetID
+		}
+	}
+	now := s.now()
+	for i := range s.events {
+		if s.events[i].BabyID == sourceID {
+			s.events[i].BabyID = targetID
+			s.events[i].UpdatedAt = now
+			s.recordAudit(ctx, "update", s.events[i])
+		}
+	}
+	for k := range s.keys {
+		if k.babyID == sourceID {
+			delete(s.keys, k)
+		}
+	}
+	source.DeletedAt = &now
+	return cloneBaby(*target), nil
+}
+
+func (s *Store) ListEvents(_ context.Context, babyID int64, filter server.EventFilter) ([]server.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := s.matching(babyID, filter)
+	if filter.Newest {
+		slices.Reverse(events)
+	}
+	if filter.After != nil {
+		after := *filter.After
+		events = slices.DeleteFunc(events, func(event server.Event) bool {
+			if filter.Newest {
+				return compareEvent(event, after) >= 0
+			}
+			return compareEvent(event, after) <= 0
+		})
+	}
+	events = events[min(filter.Offset, len(events)):]
+	if filter.Limit > 0 && filter.Limit < len(events) {
+		events = events[:filter.Limit]
+	}
+	return cloneEvents(events), nil
+}
+
+func (s *Store) GetEvent(_ context.Context, babyID, eventID int64) (server.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	event := s.event(babyID, eventID)
+	if event == nil {
+		return server.Event{}, server.ErrNotFound
+	}
+	return cloneEvent(*event), nil
+}
+
+// CountEvents counts the events matching filter. Its paging fields are
+// ignored, so the count covers every page.
+func (s *Store) CountEvents(_ context.Context, babyID int64, filter server.EventFilter) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.matching(babyID, filter))), nil
+}
+
+// StreamEvents calls fn with a snapshot of the baby's events, so fn may
+// call back into the store.
+func (s *Store) StreamEvents(ctx context.Context, babyID int64, fn func(server.Event) error) error {
+	s.mu.RLock()
+	events := cloneEvents(s.matching(babyID, server.EventFilter{}))
+	s.mu.RUnlock()
+
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) CreateEvent(ctx context.Context, input server.CreateEventInput) (server.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.baby(input.BabyID) == nil {
+		return server.Event{}, fmt.Errorf("insert event: %w", server.ErrNotFound)
+	}
+	return s.insertEvent(ctx, input), nil
+}
+
+func (s *Store) FindNearbyEvent(_ context.Context, babyID int64, eventType string, at time.Time, window time.Duration) (server.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var (
+		nearest  *server.Event
+		distance time.Duration
+	)
+	for _, event := range s.matching(babyID, server.EventFilter{Types: []string{eventType}}) {
+		d := event.OccurredAt.Sub(at).Abs()
+		if d <= window && (nearest == nil || d < distance) {
+			nearest, distance = &event, d
+		}
+	}
+	if nearest == nil {
+		return server.Event{}, server.ErrNotFound
+	}
+	return cloneEvent(*nearest), nil
+}
+
+func (s *Store) FindOverlappingEvent(_ context.Context, babyID int64, eventType string, start time.Time, end *time.Time) (server.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, event := range s.matching(babyID, server.EventFilter{Types: []string{eventType}}) {
+		d := decodeDetails(event)
+		eventEnd := d.EndAt
+		if event.Type == "nursing" {
+			nursingEnd := event.OccurredAt.Add(time.Duration(d.DurationMinutes) * time.Minute)
+			eventEnd = &nursingEnd
+		}
+		if overlaps(event.OccurredAt, eventEnd, start, end) {
+			return cloneEvent(event), nil
+		}
+	}
+	return server.Event{}, server.ErrNotFound
+}
+
+// overlaps reports whether the half-open spans [aStart, aEnd) and
+// [bStart, bEnd) share an instant, like tstzrange's && operator. A nil end
+// is unbounded, and an empty span overlaps nothing.
+func overlaps(aStart time.Time, aEnd *time.Time, bStart time.Time, bEnd *time.Time) bool {
+	if aEnd != nil && !aEnd.After(aStart) || bEnd != nil && !bEnd.After(bStart) {
+		return false
+	}
+	return (aEnd == nil || bStart.Before(*aEnd)) && (bEnd == nil || aStart.Before(*bEnd))
+}
+
+// CreateEventIdempotent claims key and inserts the event under the same
+// lock, so concurrent requests with one key create a single event.
+func (s *Store) CreateEventIdempotent(ctx context.Context, key string, input server.CreateEventInput) (server.Event, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := idempotencyKey{babyID: input.BabyID, key: key}
+	if claim, ok := s.keys[k]; ok && s.now().Sub(claim.createdAt) <= server.IdempotencyKeyTTL {
+		if event := s.event(input.BabyID, claim.eventID); event != nil {
+			return cloneEvent(*event), false, nil
+		}
+	}
+
+	if s.baby(input.BabyID) == nil {
+		return server.Event{}, false, fmt.Errorf("insert event: %w", server.ErrNotFound)
+	}
+	event := s.insertEvent(ctx, input)
+	s.keys[k] = idempotencyClaim{eventID: event.ID, createdAt: s.now()}
+	return event, true, nil
+}
+
+func (s *Store) GetIdempotentEvent(_ context.Context, babyID int64, key string) (server.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	claim, ok := s.keys[idempotencyKey{babyID: babyID, key: key}]
+	if !ok || s.now().Sub(claim.createdAt) > server.IdempotencyKeyTTL {
+		return server.Event{}, server.ErrNotFound
+	}
+	event := s.event(babyID, claim.eventID)
+	if event == nil {
+		return server.Event{}, server.ErrNotFound
+	}
+	return cloneEvent(*event), nil
+}
+
+// CreateEvents inserts all inputs or, when one names a missing baby, none
+// of them.
+func (s *Store) CreateEvents(ctx context.Context, inputs []server.CreateEventInput) ([]server.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, input := range inputs {
+		if s.baby(input.BabyID) == nil {
+			return nil, fmt.Errorf("insert event %d: %w", i, server.ErrNotFound)
+		}
+	}
+
+	events := make([]server.Event, 0, len(inputs))
+	for _, input := range inputs {
+		events = append(events, s.insertEvent(ctx, input))
+	}
+	return events, nil
+}
+
+func (s *Store) ImportBaby(ctx context.Context, input server.NewBabyInput, inputs []server.CreateEventInput) (server.Baby, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	baby := s.addBaby(input)
+	for _, input := range inputs {
+		input.BabyID = baby.ID
+		s.insertEvent(ctx, input)
+	}
+	return baby, nil
+}
+
+func (s *Store) ListWeightEntries(_ context.Context, babyID int64) ([]server.WeightEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.weights(babyID), nil
+}
+
+func (s *Store) CreateWeightEntries(ctx context.Context, babyID int64, entries []server.NewWeightEntry) ([]server.WeightEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.baby(babyID) == nil {
+		return nil, server.ErrNotFound
+	}
+
+	data := make([]server.WeightEntry, 0, len(entries))
+	for _, entry := range entries {
+		details, err := json.Marshal(map[string]float64{"weight_kg": entry.WeightKg})
+		if err != nil {
+			return nil, fmt.Errorf("encode weight: %w", err)
+		}
+		event := s.insertEvent(ctx, server.CreateEventInput{
+			BabyID:     babyID,
+			Type:       "weight",
+			OccurredAt: entry.OccurredAt,
+			Details:    details,
+		})
+		data = append(data, server.WeightEntry{ID: event.ID, OccurredAt: event.OccurredAt, WeightKg: entry.WeightKg})
+	}
+	return data, nil
+}
+
+func (s *Store) DeleteWeightEntry(ctx context.Context, babyID, weightID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.events, func(event server.Event) bool {
+		return event.ID == weightID && event.BabyID == babyID && event.Type == "weight"
+	})
+	if i < 0 {
+		return server.ErrNotFound
+	}
+	s.recordAudit(ctx, "delete", s.events[i])
+	s.events = slices.Delete(s.events, i, i+1)
+	return nil
+}
+
+func (s *Store) PreviousWeight(_ context.Context, babyID int64, at time.Time) (server.WeightEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	weights := s.weights(babyID)
+	for i := len(weights) - 1; i >= 0; i-- {
+		if !weights[i].OccurredAt.After(at) {
+			return weights[i], nil
+		}
+	}
+	return server.WeightEntry{}, server.ErrNotFound
+}
+
+func (s *Store) LatestWeight(_ context.Context, babyID int64) (server.LatestWeight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	weights := s.weights(babyID)
+	if len(weights) == 0 {
+		return server.LatestWeight{}, server.ErrNotFound
+	}
+
+	last := weights[len(weights)-1]
+	latest := server.LatestWeight{OccurredAt: last.OccurredAt, WeightKg: last.WeightKg}
+	if len(weights) > 1 {
+		delta := last.WeightKg - weights[len(weights)-2].WeightKg
+		latest.DeltaKg = &delta
+	}
+	return latest, nil
+}
+
+func (s *Store) ListSleepSessions(_ context.Context, babyID int64, from, to time.Time) ([]server.SleepSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data := make([]server.SleepSession, 0)
+	for _, event := range s.matching(babyID, server.EventFilter{Types: []string{"sleep"}}) {
+		d := decodeDetails(event)
+		if d.StartAt == nil {
+			continue
+		}
+		end := d.EndAt
+		if end == nil {
+			// A sleep in progress runs until now or the end of the range.
+			open := s.now()
+			if open.After(to) {
+				open = to
+			}
+			if !open.After(*d.StartAt) {
+				continue
+			}
+			end = &open
+		}
+		if !d.StartAt.Before(to) || !end.After(from) {
+			continue
+		}
+		data = append(data, server.SleepSession{StartAt: *d.StartAt, EndAt: *end})
+	}
+	return data, nil
+}
+
+func (s *Store) GetBabyStatus(_ context.Context, babyID int64) (server.BabyStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var status server.BabyStatus
+	latest := func(current *time.Time, at time.Time) *time.Time {
+		if current == nil || at.After(*current) {
+			return &at
+		}
+		return current
+	}
+	for _, event := range s.matching(babyID, server.EventFilter{}) {
+		switch event.Type {
+		case "nursing":
+			status.LastFeedingAt = latest(status.LastFeedingAt, event.OccurredAt)
+		case "diaper":
+			status.LastDiaperAt = latest(status.LastDiaperAt, event.OccurredAt)
+		case "sleep":
+			if decodeDetails(event).EndAt == nil {
+				status.AsleepSince = latest(status.AsleepSince, event.OccurredAt)
+			}
+		}
+	}
+	status.Asleep = status.AsleepSince != nil
+	return status, nil
+}
+
+func (s *Store) RecentSideBalance(_ context.Context, babyID int64, limit int) (server.SideBalance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var balance server.SideBalance
+	feeds := s.matching(babyID, server.EventFilter{Types: []string{"nursing"}})
+	for i := len(feeds) - 1; i >= 0 && len(feeds)-i <= limit; i-- {
+		side := decodeDetails(feeds[i]).Side
+		if i == len(feeds)-1 {
+			balance.LastSide = side
+		}
+		switch side {
+		case "left":
+			balance.Left++
+		case "right":
+			balance.Right++
+		}
+	}
+	return balance, nil
+}
+
+func (s *Store) NursingMinutesBySide(_ context.Context, babyID int64, from, to time.Time) (server.SideMinutes, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var minutes server.SideMinutes
+	for _, event := range s.between(babyID, "nursing", from, to) {
+		d := decodeDetails(event)
+		switch d.Side {
+		case "left":
+			minutes.Left += d.DurationMinutes
+		case "right":
+			minutes.Right += d.DurationMinutes
+		}
+	}
+	return minutes, nil
+}
+
+func (s *Store) FeedingIntervals(_ context.Context, babyID int64, from, to time.Time) (server.FeedingIntervals, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	feeds := s.between(babyID, "nursing", from, to)
+	intervals := server.FeedingIntervals{Feeds: len(feeds)}
+	if len(feeds) < 2 {
+		return intervals, nil
+	}
+
+	var total, shortest, longest float64
+	shortest = math.Inf(1)
+	for i := 1; i < len(feeds); i++ {
+		gap := feeds[i].OccurredAt.Sub(feeds[i-1].OccurredAt).Minutes()
+		total += gap
+		shortest = min(shortest, gap)
+		longest = max(longest, gap)
+	}
+	average := total / float64(len(feeds)-1)
+	intervals.AverageMinutes = &average
+	intervals.MinMinutes = &shortest
+	intervals.MaxMinutes = &longest
+	return intervals, nil
+}
+
+func (s *Store) BabyStats(_ context.Context, babyID int64) (server.BabyStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := server.BabyStats{EventCounts: make(map[string]int64)}
+	var (
+		nursingMinutes, nursingSessions int
+		longestSleep                    *float64
+	)
+	for _, event := range s.matching(babyID, server.EventFilter{}) {
+		stats.EventCounts[event.Type]++
+		d := decodeDetails(event)
+		switch event.Type {
+		case "nursing":
+			nursingMinutes += d.DurationMinutes
+			nursingSessions++
+		case "sleep":
+			if d.StartAt != nil && d.EndAt != nil {
+				minutes := d.EndAt.Sub(*d.StartAt).Minutes()
+				if longestSleep == nil || minutes > *longestSleep {
+					longestSleep = &minutes
+				}
+			}
+		}
+	}
+	if nursingSessions > 0 {
+		average := float64(nursingMinutes) / float64(nursingSessions)
+		stats.AverageNursingMinutes = &average
+	}
+	stats.LongestSleepMinutes = longestSleep
+	return stats, nil
+}
+
+func (s *Store) DailyCounts(_ context.Context, babyID int64, from, to time.Time, tz string) (map[string]server.DailyCounts, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("load time zone: %w", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data := make(map[string]server.DailyCounts)
+	for _, event := range s.between(babyID, "", from, to) {
+		date := event.OccurredAt.In(loc).Format(time.DateOnly)
+		counts := data[date]
+		d := decodeDetails(event)
+		switch event.Type {
+		case "diaper":
+			counts.Diapers++
+			switch d.Kind {
+			case "wet":
+				counts.WetDiapers++
+			case "dirty":
+				counts.DirtyDiapers++
+			case "mixed":
+				counts.MixedDiapers++
+			}
+		case "nursing":
+			counts.Feeds++
+			counts.NursingMinutes += d.DurationMinutes
+		}
+		data[date] = counts
+	}
+	return data, nil
+}
+
+func (s *Store) EventCountsByLocalDate(_ context.Context, babyID int64, from, to time.Time, tz string) (map[string]int64, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("load time zone: %w", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data := make(map[string]int64)
+	for _, event := range s.between(babyID, "", from, to) {
+		data[event.OccurredAt.In(loc).Format(time.DateOnly)]++
+	}
+	return data, nil
+}
+
+// EventHistory lists the audit entries of one of a baby's events, oldest
+// first. The history of a deleted event is kept.
+func (s *Store) EventHistory(_ context.Context, babyID, eventID int64) ([]server.EventAuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]server.EventAuditEntry, 0)
+	for _, audit := range s.audit {
+		if audit.babyID == babyID && audit.eventID == eventID {
+			entries = append(entries, audit.entry)
+		}
+	}
+	if len(entries) == 0 && s.event(babyID, eventID) == nil {
+		return nil, server.ErrNotFound
+	}
+	return entries, nil
+}
+
+func (s *Store) EndSleep(ctx context.Context, babyID, eventID int64, endAt time.Time) (server.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event := s.event(babyID, eventID)
+	if event == nil || event.Type != "sleep" {
+		return server.Event{}, server.ErrNotFound
+	}
+
+	d := decodeDetails(*event)
+	if d.EndAt != nil {
+		return server.Event{}, server.ErrSleepEnded
+	}
+	if d.StartAt == nil || !endAt.After(*d.StartAt) {
+		return server.Event{}, server.ErrSleepEndBeforeStart
+	}
+
+	fields := make(map[string]any)
+	if err := json.Unmarshal(event.Details, &fields); err != nil {
+		return server.Event{}, fmt.Errorf("decode sleep details: %w", err)
+	}
+	fields["end_at"] = endAt.Format(time.RFC3339)
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return server.Event{}, fmt.Errorf("encode sleep details: %w", err)
+	}
+
+	event.Details = payload
+	event.UpdatedAt = s.now()
+	s.recordAudit(ctx, "update", *event)
+	return cloneEvent(*event), nil
+}
+
+// PurgeEventsOlderThan deletes every event that occurred before cutoff,
+// for all babies, along with idempotency keys pointing at them.
+func (s *Store) PurgeEventsOlderThan(_ context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.events)
+	s.events = slices.DeleteFunc(s.events, func(event server.Event) bool {
+		return event.OccurredAt.Before(cutoff)
+	})
+	for k, claim := range s.keys {
+		if !slices.ContainsFunc(s.events, func(event server.Event) bool { return event.ID == claim.eventID }) {
+			delete(s.keys, k)
+		}
+	}
+	return int64(before - len(s.events)), nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes keys claimed more than
+// server.IdempotencyKeyTTL ago.
+func (s *Store) PurgeExpiredIdempotencyKeys(context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for k, claim := range s.keys {
+		if s.now().Sub(claim.createdAt) > server.IdempotencyKeyTTL {
+			delete(s.keys, k)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// insertEvent stores input, whose baby must exist, and audits it. The
+// caller holds the write lock.
+func (s *Store) insertEvent(ctx context.Context, input server.CreateEventInput) server.Event {
+	s.nextEventID++
+	now := s.now()
+	event := server.Event{
+		ID:         s.nextEventID,
+		BabyID:     input.BabyID,
+		Type:       input.Type,
+		OccurredAt: input.OccurredAt,
+		Details:    slices.Clone(input.Details),
+		Tags:       slices.Clone(input.Tags),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if len(event.Details) == 0 {
+		event.Details = json.RawMessage("{}")
+	}
+	if event.Tags == nil {
+		event.Tags = []string{}
+	}
+	s.events = append(s.events, event)
+	s.recordAudit(ctx, "create", event)
+	return cloneEvent(event)
+}
+
+// recordAudit logs action on event, attributed to the actor of ctx.
+func (s *Store) recordAudit(ctx context.Context, action string, event server.Event) {
+	entry := server.EventAuditEntry{Action: action, ChangedAt: s.now()}
+	if actor := server.ActorFromContext(ctx); actor != "" {
+		entry.Actor = &actor
+	}
+	s.audit = append(s.audit, auditEntry{babyID: event.BabyID, eventID: event.ID, entry: entry})
+}
+
+func (s *Store) baby(id int64) *server.Baby {
+	if id < 1 || id > int64(len(s.babies)) {
+		return nil
+	}
+	return &s.babies[id-1]
+}
+
+func (s *Store) event(babyID, eventID int64) *server.Event {
+	i := slices.IndexFunc(s.events, func(event server.Event) bool {
+		return event.ID == eventID && event.BabyID == babyID
+	})
+	if i < 0 {
+		return nil
+	}
+	return &s.events[i]
+}
+
+// matching returns the baby's events matching the Types, Tags and Notes of
+// filter, in occurred_at and then id order. They share Details and Tags
+// with the store.
+func (s *Store) matching(babyID int64, filter server.EventFilter) []server.Event {
+	notes := strings.ToLower(filter.Notes)
+	var events []server.Event
+	for _, event := range s.events {
+		if event.BabyID != babyID {
+			continue
+		}
+		if len(filter.Types) > 0 && !slices.Contains(filter.Types, event.Type) {
+			continue
+		}
+		if !containsAll(event.Tags, filter.Tags) {
+			continue
+		}
+		if notes != "" && !strings.Contains(strings.ToLower(decodeDetails(event).Notes), notes) {
+			continue
+		}
+		events = append(events, event)
+	}
+	slices.SortFunc(events, func(a, b server.Event) int {
+		return compareEvent(a, server.EventCursor{OccurredAt: b.OccurredAt, ID: b.ID})
+	})
+	return events
+}
+
+// between returns the baby's events of eventType in [from, to), or of
+// every type when eventType is empty.
+func (s *Store) between(babyID int64, eventType string, from, to time.Time) []server.Event {
+	var filter server.EventFilter
+	if eventType != "" {
+		filter.Types = []string{eventType}
+	}
+	return slices.DeleteFunc(s.matching(babyID, filter), func(event server.Event) bool {
+		return event.OccurredAt.Before(from) || !event.OccurredAt.Before(to)
+	})
+}
+
+func (s *Store) weights(babyID int64) []server.WeightEntry {
+	data := make([]server.WeightEntry, 0)
+	for _, event := range s.matching(babyID, server.EventFilter{Types: []string{"weight"}}) {
+		if kg := decodeDetails(event).WeightKg; kg != nil {
+			data = append(data, server.WeightEntry{ID: event.ID, OccurredAt: event.OccurredAt, WeightKg: *kg})
+		}
+	}
+	return data
+}
+
+func compareEvent(event server.Event, cursor server.EventCursor) int {
+	return cmp.Or(event.OccurredAt.Compare(cursor.OccurredAt), cmp.Compare(event.ID, cursor.ID))
+}
+
+func containsAll(tags, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeDetails reads event's details. Stores do not validate details, so
+// fields that do not decode are left zero, as Postgres would read them as
+// missing.
+func decodeDetails(event server.Event) details {
+	var d details
+	_ = json.Unmarshal(event.Details, &d)
+	return d
+}
+
+func cloneBaby(baby server.Baby) server.Baby {
+	if baby.DeletedAt != nil {
+		deletedAt := *baby.DeletedAt
+		baby.DeletedAt = &deletedAt
+	}
+	if baby.Color != nil {
+		baby.Color = nullIfEmpty(*baby.Color)
+	}
+	if baby.AvatarURL != nil {
+		baby.AvatarURL = nullIfEmpty(*baby.AvatarURL)
+	}
+	if baby.Timezone != nil {
+		baby.Timezone = nullIfEmpty(*baby.Timezone)
+	}
+	return baby
+}
+
+// nullIfEmpty returns a pointer to a copy of value, or nil for "", as
+// NULLIF does in the Postgres store.
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func cloneEvent(event server.Event) server.Event {
+	event.Details = slices.Clone(event.Details)
+	event.Tags = slices.Clone(event.Tags)
+	return event
+}
+
+func cloneEvents(events []server.Event) []server.Event {
+	data := make([]server.Event, 0, len(events))
+	for _, event := range events {
+		data = append(data, cloneEvent(event))
+	}
+	return data
+}
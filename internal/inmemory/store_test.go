@@ -0,0 +1,624 @@
+package inmemory_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"baby-tracker-server/internal/inmemory"
+	"baby-tracker-server/internal/server"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", value, err)
+	}
+	return parsed
+}
+
+func seedEvents(t *testing.T, store *inmemory.Store, inputs ...server.CreateEventInput) []server.Event {
+	t.Helper()
+
+	events, err := store.CreateEvents(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("failed to seed events: %v", err)
+	}
+	return events
+}
+
+func TestStoreListBabies(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	store.AddBaby("Alice")
+	bob := store.AddBaby("Bob")
+
+	got, err := store.ListBabies(ctx, server.BabyFilter{})
+	if err != nil {
+		t.Fatalf("failed to list babies: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "Alice" || got[1].Name != "Bob" {
+		t.Fatalf("expected Alice and Bob, got %+v", got)
+	}
+	if got[0].CreatedAt.IsZero() {
+		t.Fatal("expected created_at to be set")
+	}
+
+	if err := store.DeleteBaby(ctx, bob.ID); err != nil {
+		t.Fatalf("failed to delete baby: %v", err)
+	}
+	if got, _ := store.ListBabies(ctx, server.BabyFilter{}); len(got) != 1 {
+		t.Fatalf("expected the archived baby to be hidden, got %+v", got)
+	}
+	if got, _ := store.ListBabies(ctx, server.BabyFilter{IncludeDeleted: true}); len(got) != 2 || got[1].DeletedAt == nil {
+		t.Fatalf("expected the archived baby with deleted_at, got %+v", got)
+	}
+	if _, err := store.GetBaby(ctx, bob.ID, server.BabyFilter{}); !errors.Is(err, server.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an archived baby, got %v", err)
+	}
+	if err := store.DeleteBaby(ctx, bob.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound archiving twice, got %v", err)
+	}
+}
+
+func TestStoreCreateEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+
+	occurredAt := time.Now().UTC().Truncate(time.Second)
+	got, err := store.CreateEvent(ctx, server.CreateEventInput{
+		BabyID:     baby.ID,
+		Type:       "diaper",
+		OccurredAt: occurredAt,
+		Details:    json.RawMessage(`{"notes":"first change"}`),
+	})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	if got.ID == 0 || got.BabyID != baby.ID || got.Type != "diaper" || !got.OccurredAt.Equal(occurredAt) {
+		t.Fatalf("unexpected event %+v", got)
+	}
+	if got.Tags == nil || !got.CreatedAt.Equal(got.UpdatedAt) {
+		t.Fatalf("expected empty tags and updated_at equal to created_at, got %+v", got)
+	}
+
+	// The returned event is a copy.
+	got.Details[2] = 'X'
+	stored, err := store.GetEvent(ctx, baby.ID, got.ID)
+	if err != nil {
+		t.Fatalf("failed to get event: %v", err)
+	}
+	if string(stored.Details) != `{"notes":"first change"}` {
+		t.Fatalf("expected stored details to be unchanged, got %s", stored.Details)
+	}
+
+	history, err := store.EventHistory(ctx, baby.ID, got.ID)
+	if err != nil || len(history) != 1 || history[0].Action != "create" {
+		t.Fatalf("expected a create audit entry, got %+v, %v", history, err)
+	}
+
+	if _, err := store.CreateEvent(ctx, server.CreateEventInput{BabyID: 99, Type: "diaper", OccurredAt: occurredAt}); !errors.Is(err, server.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing baby, got %v", err)
+	}
+}
+
+func TestStoreMergeBabies(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	mila := store.AddBaby("Mila")
+	duplicate := store.AddBaby("Mila 2")
+	archived := store.AddBaby("Noah")
+	if err := store.DeleteBaby(ctx, archived.ID); err != nil {
+		t.Fatalf("failed to archive baby: %v", err)
+	}
+
+	seedEvents(t, store,
+		server.CreateEventInput{BabyID: mila.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-02-26T08:00:00Z")},
+		server.CreateEventInput{BabyID: duplicate.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-02-26T09:00:00Z")},
+	)
+	if _, err := store.CreateWeightEntries(ctx, duplicate.ID, []server.NewWeightEntry{{OccurredAt: mustTime(t, "2026-02-26T10:00:00Z"), WeightKg: 4.2}}); err != nil {
+		t.Fatalf("failed to seed weight: %v", err)
+	}
+	keyed := server.CreateEventInput{BabyID: duplicate.ID, Type: "feeding", OccurredAt: mustTime(t, "2026-02-26T11:00:00Z")}
+	if _, _, err := store.CreateEventIdempotent(ctx, "tap", keyed); err != nil {
+		t.Fatalf("failed to seed keyed event: %v", err)
+	}
+
+	got, err := store.MergeBabies(ctx, mila.ID, duplicate.ID)
+	if err != nil {
+		t.Fatalf("failed to merge babies: %v", err)
+	}
+	if got.ID != mila.ID || got.Name != "Mila" {
+		t.Fatalf("expected the target baby, got %+v", got)
+	}
+
+	if count, _ := store.CountEvents(ctx, mila.ID, server.EventFilter{}); count != 4 {
+		t.Fatalf("expected 4 events after the merge, got %d", count)
+	}
+	if _, err := store.GetIdempotentEvent(ctx, duplicate.ID, "tap"); !errors.Is(err, server.ErrNotFound) {
+		t.Fatalf("expected the source's key to be dropped, got %v", err)
+	}
+	if weights, _ := store.ListWeightEntries(ctx, mila.ID); len(weights) != 1 {
+		t.Fatalf("expected the weight to move, got %+v", weights)
+	}
+	if _, err := store.GetBaby(ctx, duplicate.ID, server.BabyFilter{}); !errors.Is(err, server.ErrNotFound) {
+		t.Fatalf("expected the source to be archived, got %v", err)
+	}
+
+	moved, _ := store.ListEvents(ctx, mila.ID, server.EventFilter{})
+	history, err := store.EventHistory(ctx, mila.ID, moved[1].ID)
+	if err != nil || len(history) != 2 || history[0].Action != "create" || history[1].Action != "update" {
+		t.Fatalf("expected the moved event's history under the target, got %+v, %v", history, err)
+	}
+
+	for _, ids := range [][2]int64{{mila.ID, duplicate.ID}, {mila.ID, archived.ID}, {archived.ID, mila.ID}, {mila.ID, 99}} {
+		if _, err := store.MergeBabies(ctx, ids[0], ids[1]); !errors.Is(err, server.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound merging %d into %d, got %v", ids[1], ids[0], err)
+		}
+	}
+}
+
+func TestStoreCreateEventsAllOrNothing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+
+	at := mustTime(t, "2026-02-26T10:00:00Z")
+	_, err := store.CreateEvents(ctx, []server.CreateEventInput{
+		{BabyID: baby.ID, Type: "diaper", OccurredAt: at},
+		{BabyID: 99, Type: "diaper", OccurredAt: at},
+	})
+	if !errors.Is(err, server.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if count, _ := store.CountEvents(ctx, baby.ID, server.EventFilter{}); count != 0 {
+		t.Fatalf("expected no events after a failed batch, got %d", count)
+	}
+}
+
+func TestStoreListBabiesSort(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	for _, name := range []string{"charlie", "Alice", "bob", "alice"} {
+		store.AddBaby(name)
+	}
+
+	for _, tt := range []struct {
+		filter server.BabyFilter
+		want   []int64
+	}{
+		{filter: server.BabyFilter{}, want: []int64{1, 2, 3, 4}},
+		{filter: server.BabyFilter{Descending: true}, want: []int64{4, 3, 2, 1}},
+		{filter: server.BabyFilter{Sort: server.BabySortName}, want: []int64{2, 4, 3, 1}},
+		{filter: server.BabyFilter{Sort: server.BabySortName, Descending: true}, want: []int64{1, 3, 4, 2}},
+	} {
+		got, err := store.ListBabies(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("failed to list babies: %v", err)
+		}
+		ids := make([]int64, 0, len(got))
+		for _, baby := range got {
+			ids = append(ids, baby.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Fatalf("%+v: expected %v, got %v", tt.filter, tt.want, ids)
+		}
+	}
+}
+
+func TestStoreCreateWeightEntries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+
+	got, err := store.CreateWeightEntries(ctx, baby.ID, []server.NewWeightEntry{
+		{OccurredAt: mustTime(t, "2026-02-26T10:00:00Z"), WeightKg: 4.2},
+		{OccurredAt: mustTime(t, "2026-02-20T10:00:00Z"), WeightKg: 3.9},
+	})
+	if err != nil {
+		t.Fatalf("failed to create weight entries: %v", err)
+	}
+	if len(got) != 2 || got[0].WeightKg != 4.2 || got[1].WeightKg != 3.9 || got[0].ID == got[1].ID {
+		t.Fatalf("unexpected weight entries %+v", got)
+	}
+
+	listed, err := store.ListWeightEntries(ctx, baby.ID)
+	if err != nil || len(listed) != 2 || listed[0].ID != got[1].ID {
+		t.Fatalf("expected both weights listed oldest first, got %+v, %v", listed, err)
+	}
+
+	if _, err := store.CreateWeightEntries(ctx, 99, []server.NewWeightEntry{{OccurredAt: mustTime(t, "2026-02-26T10:00:00Z"), WeightKg: 4}}); !errors.Is(err, server.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing baby, got %v", err)
+	}
+}
+
+func TestStoreEventCountsByLocalDate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	mila := store.AddBaby("Mila")
+	noah := store.AddBaby("Noah")
+
+	// 03:00Z is still the previous evening in New York.
+	seedEvents(t, store,
+		server.CreateEventInput{BabyID: mila.ID, Type: "nursing", OccurredAt: mustTime(t, "2026-02-26T03:00:00Z")},
+		server.CreateEventInput{BabyID: mila.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-02-26T15:00:00Z")},
+		server.CreateEventInput{BabyID: mila.ID, Type: "weight", OccurredAt: mustTime(t, "2026-02-26T16:00:00Z")},
+		server.CreateEventInput{BabyID: mila.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-03-05T10:00:00Z")},
+		server.CreateEventInput{BabyID: noah.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-02-26T15:00:00Z")},
+	)
+
+	got, err := store.EventCountsByLocalDate(ctx, mila.ID, mustTime(t, "2026-02-25T05:00:00Z"), mustTime(t, "2026-02-28T05:00:00Z"), "America/New_York")
+	if err != nil {
+		t.Fatalf("failed to count events by local date: %v", err)
+	}
+	if len(got) != 2 || got["2026-02-25"] != 1 || got["2026-02-26"] != 2 {
+		t.Fatalf("expected 1 event on the 25th and 2 on the 26th, got %v", got)
+	}
+}
+
+func TestStoreListEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	mila := store.AddBaby("Mila")
+	noah := store.AddBaby("Noah")
+
+	seedEvents(t, store,
+		server.CreateEventInput{BabyID: mila.ID, Type: "nursing", OccurredAt: mustTime(t, "2026-02-26T12:00:00Z"), Details: json.RawMessage(`{"side":"left","duration_minutes":10}`)},
+		server.CreateEventInput{BabyID: mila.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-02-26T10:00:00Z"), Details: json.RawMessage(`{}`), Tags: []string{"daycare"}},
+		server.CreateEventInput{BabyID: mila.ID, Type: "sleep", OccurredAt: mustTime(t, "2026-02-26T13:00:00Z"), Details: json.RawMessage(`{"start_at":"2026-02-26T13:00:00Z"}`)},
+		server.CreateEventInput{BabyID: noah.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-02-26T09:00:00Z"), Details: json.RawMessage(`{"notes":"Small RASH on the leg"}`)},
+	)
+
+	all, err := store.ListEvents(ctx, mila.ID, server.EventFilter{})
+	if err != nil {
+		t.Fatalf("failed to list events: %v", err)
+	}
+	if len(all) != 3 || all[0].Type != "diaper" || all[2].Type != "sleep" {
+		t.Fatalf("expected events ordered by occurred_at, got %+v", all)
+	}
+
+	filtered, err := store.ListEvents(ctx, mila.ID, server.EventFilter{Types: []string{"diaper", "sleep"}})
+	if err != nil {
+		t.Fatalf("failed to list filtered events: %v", err)
+	}
+	if len(filtered) != 2 || filtered[0].Type != "diaper" || filtered[1].Type != "sleep" {
+		t.Fatalf("expected diaper and sleep events, got %+v", filtered)
+	}
+
+	page, err := store.ListEvents(ctx, mila.ID, server.EventFilter{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("failed to list a page of events: %v", err)
+	}
+	if len(page) != 1 || page[0].Type != "nursing" {
+		t.Fatalf("expected the nursing event, got %+v", page)
+	}
+
+	after, err := store.ListEvents(ctx, mila.ID, server.EventFilter{After: &server.EventCursor{OccurredAt: all[0].OccurredAt, ID: all[0].ID}})
+	if err != nil {
+		t.Fatalf("failed to list events after a cursor: %v", err)
+	}
+	if len(after) != 2 || after[0].Type != "nursing" || after[1].Type != "sleep" {
+		t.Fatalf("expected the events after the diaper, got %+v", after)
+	}
+
+	newest, err := store.ListEvents(ctx, mila.ID, server.EventFilter{Limit: 2, Newest: true})
+	if err != nil {
+		t.Fatalf("failed to list the newest events: %v", err)
+	}
+	if len(newest) != 2 || newest[0].Type != "sleep" || newest[1].Type != "nursing" {
+		t.Fatalf("expected the sleep and then the nursing event, got %+v", newest)
+	}
+	older, err := store.ListEvents(ctx, mila.ID, server.EventFilter{Newest: true, After: &server.EventCursor{OccurredAt: newest[1].OccurredAt, ID: newest[1].ID}})
+	if err != nil {
+		t.Fatalf("failed to list events older than a cursor: %v", err)
+	}
+	if len(older) != 1 || older[0].Type != "diaper" {
+		t.Fatalf("expected the diaper before the nursing event, got %+v", older)
+	}
+
+	tagged, err := store.ListEvents(ctx, mila.ID, server.EventFilter{Tags: []string{"daycare"}})
+	if err != nil || len(tagged) != 1 || tagged[0].Type != "diaper" {
+		t.Fatalf("expected the tagged diaper, got %+v, %v", tagged, err)
+	}
+
+	notes, err := store.ListEvents(ctx, noah.ID, server.EventFilter{Notes: "rash"})
+	if err != nil || len(notes) != 1 {
+		t.Fatalf("expected a case-insensitive notes match, got %+v, %v", notes, err)
+	}
+
+	if count, err := store.CountEvents(ctx, mila.ID, server.EventFilter{Types: []string{"diaper", "sleep"}, Limit: 1}); err != nil || count != 2 {
+		t.Fatalf("expected a count of 2 ignoring the limit, got %d, %v", count, err)
+	}
+}
+
+func TestStoreFindOverlappingEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+	events := seedEvents(t, store,
+		server.CreateEventInput{BabyID: baby.ID, Type: "sleep", OccurredAt: mustTime(t, "2026-02-26T10:00:00Z"), Details: json.RawMessage(`{"start_at":"2026-02-26T10:00:00Z","end_at":"2026-02-26T11:00:00Z"}`)},
+		server.CreateEventInput{BabyID: baby.ID, Type: "sleep", OccurredAt: mustTime(t, "2026-02-26T20:00:00Z"), Details: json.RawMessage(`{"start_at":"2026-02-26T20:00:00Z"}`)},
+		server.CreateEventInput{BabyID: baby.ID, Type: "nursing", OccurredAt: mustTime(t, "2026-02-26T12:00:00Z"), Details: json.RawMessage(`{"side":"left","duration_minutes":20}`)},
+	)
+
+	end := func(value string) *time.Time {
+		at := mustTime(t, value)
+		return &at
+	}
+	tests := []struct {
+		name      string
+		eventType string
+		start     string
+		end       *time.Time
+		want      int64
+	}{
+		{name: "inside a sleep", eventType: "sleep", start: "2026-02-26T10:30:00Z", end: end("2026-02-26T10:45:00Z"), want: events[0].ID},
+		{name: "ends as it starts", eventType: "sleep", start: "2026-02-26T09:00:00Z", end: end("2026-02-26T10:00:00Z")},
+		{name: "starts as it ends", eventType: "sleep", start: "2026-02-26T11:00:00Z", end: end("2026-02-26T12:00:00Z")},
+		{name: "open sleep runs on", eventType: "sleep", start: "2026-02-27T03:00:00Z", end: end("2026-02-27T04:00:00Z"), want: events[1].ID},
+		{name: "open new sleep", eventType: "sleep", start: "2026-02-26T10:59:00Z", want: events[0].ID},
+		{name: "nursing duration", eventType: "nursing", start: "2026-02-26T12:15:00Z", end: end("2026-02-26T12:30:00Z"), want: events[2].ID},
+		{name: "after nursing", eventType: "nursing", start: "2026-02-26T12:20:00Z", end: end("2026-02-26T12:30:00Z")},
+		{name: "other types ignored", eventType: "nursing", start: "2026-02-26T10:30:00Z", end: end("2026-02-26T10:45:00Z")},
+	}
+
+	for _, tt := range tests {
+		event, err := store.FindOverlappingEvent(ctx, baby.ID, tt.eventType, mustTime(t, tt.start), tt.end)
+		if tt.want == 0 {
+			if !errors.Is(err, server.ErrNotFound) {
+				t.Fatalf("%s: expected ErrNotFound, got %+v, %v", tt.name, event, err)
+			}
+			continue
+		}
+		if err != nil || event.ID != tt.want {
+			t.Fatalf("%s: expected event %d, got %+v, %v", tt.name, tt.want, event, err)
+		}
+	}
+}
+
+func TestStoreEndSleep(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+
+	events := seedEvents(t, store, server.CreateEventInput{
+		BabyID:     baby.ID,
+		Type:       "sleep",
+		OccurredAt: mustTime(t, "2026-02-26T13:00:00Z"),
+		Details:    json.RawMessage(`{"start_at":"2026-02-26T13:00:00Z"}`),
+	})
+	sleep := events[0]
+
+	status, err := store.GetBabyStatus(ctx, baby.ID)
+	if err != nil || !status.Asleep {
+		t.Fatalf("expected the baby to be asleep, got %+v, %v", status, err)
+	}
+
+	if _, err := store.EndSleep(ctx, baby.ID, sleep.ID, mustTime(t, "2026-02-26T12:00:00Z")); !errors.Is(err, server.ErrSleepEndBeforeStart) {
+		t.Fatalf("expected ErrSleepEndBeforeStart, got %v", err)
+	}
+	ended, err := store.EndSleep(ctx, baby.ID, sleep.ID, mustTime(t, "2026-02-26T14:30:00Z"))
+	if err != nil {
+		t.Fatalf("failed to end sleep: %v", err)
+	}
+	if !strings.Contains(string(ended.Details), `"end_at":"2026-02-26T14:30:00Z"`) {
+		t.Fatalf("expected end_at in details, got %s", ended.Details)
+	}
+	if _, err := store.EndSleep(ctx, baby.ID, sleep.ID, mustTime(t, "2026-02-26T15:00:00Z")); !errors.Is(err, server.ErrSleepEnded) {
+		t.Fatalf("expected ErrSleepEnded, got %v", err)
+	}
+
+	sessions, err := store.ListSleepSessions(ctx, baby.ID, mustTime(t, "2026-02-26T00:00:00Z"), mustTime(t, "2026-02-27T00:00:00Z"))
+	if err != nil || len(sessions) != 1 || sessions[0].EndAt.Sub(sessions[0].StartAt) != 90*time.Minute {
+		t.Fatalf("expected one 90 minute session, got %+v, %v", sessions, err)
+	}
+}
+
+func TestStoreListSleepSessionsInProgress(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+	napping := store.AddBaby("Noah")
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+	seedEvents(t, store,
+		server.CreateEventInput{
+			BabyID:     baby.ID,
+			Type:       "sleep",
+			OccurredAt: mustTime(t, "2026-02-26T20:00:00Z"),
+			Details:    json.RawMessage(`{"start_at":"2026-02-26T20:00:00Z"}`),
+		},
+		server.CreateEventInput{
+			BabyID:     napping.ID,
+			Type:       "sleep",
+			OccurredAt: start,
+			Details:    json.RawMessage(`{"start_at":"` + start.Format(time.RFC3339) + `"}`),
+		},
+	)
+
+	// In a past range, a sleep in progress runs to the end of the range.
+	sessions, err := store.ListSleepSessions(ctx, baby.ID, mustTime(t, "2026-02-26T00:00:00Z"), mustTime(t, "2026-02-27T00:00:00Z"))
+	if err != nil || len(sessions) != 1 || !sessions[0].EndAt.Equal(mustTime(t, "2026-02-27T00:00:00Z")) {
+		t.Fatalf("expected one session ending at midnight, got %+v, %v", sessions, err)
+	}
+
+	// In a range holding now, it runs until now.
+	before := time.Now()
+	sessions, err = store.ListSleepSessions(ctx, napping.ID, start.Add(-time.Minute), start.Add(24*time.Hour))
+	if err != nil || len(sessions) != 1 || sessions[0].EndAt.Before(before) || sessions[0].EndAt.After(time.Now()) {
+		t.Fatalf("expected one session ending now, got %+v, %v", sessions, err)
+	}
+}
+
+func TestStoreCreateEventIdempotentConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+	input := server.CreateEventInput{BabyID: baby.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-02-26T10:00:00Z")}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+		ids     = map[int64]bool{}
+	)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			event, ok, err := store.CreateEventIdempotent(ctx, "tap", input)
+			if err != nil {
+				t.Errorf("failed to create event: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ids[event.ID] = true
+			if ok {
+				created++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 || len(ids) != 1 {
+		t.Fatalf("expected one event created once, got %d creations of %v", created, ids)
+	}
+	if event, err := store.GetIdempotentEvent(ctx, baby.ID, "tap"); err != nil || !ids[event.ID] {
+		t.Fatalf("expected the key to name the created event, got %d (%v)", event.ID, err)
+	}
+	if _, err := store.GetIdempotentEvent(ctx, baby.ID, "other"); !errors.Is(err, server.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unused key, got %v", err)
+	}
+}
+
+// A first use of an Idempotency-Key is still checked for duplicates; only
+// its replay is exempt.
+func TestStoreIdempotencyKeyDuplicateWindow(t *testing.T) {
+	t.Parallel()
+
+	store := inmemory.New()
+	store.AddBaby("Mila")
+	router := server.NewRouterWithConfig(store, server.Config{DuplicateWindow: time.Minute})
+
+	post := func(key, occurredAt string) int {
+		body := `{"type":"diaper","occurred_at":"` + occurredAt + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/babies/1/events", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := post("first", "2026-02-26T10:00:00Z"); code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+	}
+	if code := post("first", "2026-02-26T10:00:00Z"); code != http.StatusOK {
+		t.Fatalf("expected status %d for a replay, got %d", http.StatusOK, code)
+	}
+	if code := post("second", "2026-02-26T10:00:30Z"); code != http.StatusConflict {
+		t.Fatalf("expected status %d for a duplicate under a new key, got %d", http.StatusConflict, code)
+	}
+}
+
+// An export imports back as an equal baby, tags included.
+func TestStoreExportImportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+	seedEvents(t, store,
+		server.CreateEventInput{BabyID: baby.ID, Type: "diaper", OccurredAt: mustTime(t, "2026-02-26T08:00:00Z"), Details: json.RawMessage(`{"kind":"wet"}`), Tags: []string{"daycare", "night"}},
+		server.CreateEventInput{BabyID: baby.ID, Type: "nursing", OccurredAt: mustTime(t, "2026-02-26T09:00:00Z"), Details: json.RawMessage(`{"side":"left","duration_minutes":12}`)},
+	)
+	router := server.NewRouter(store)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/1/export.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/import", strings.NewReader(rr.Body.String()))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	events, err := store.ListEvents(context.Background(), 2, server.EventFilter{})
+	if err != nil {
+		t.Fatalf("failed to list imported events: %v", err)
+	}
+	if len(events) != 2 || !slices.Equal(events[0].Tags, []string{"daycare", "night"}) || len(events[1].Tags) != 0 {
+		t.Fatalf("expected the tags to round-trip, got %+v", events)
+	}
+}
+
+// The store backs a real router, which is what it exists for.
+func TestStoreServesRouter(t *testing.T) {
+	t.Parallel()
+
+	store := inmemory.New()
+	baby := store.AddBaby("Mila")
+	router := server.NewRouter(store)
+
+	body := `{"type":"nursing","occurred_at":"2026-02-26T10:00:00Z","side":"left","duration_minutes":12}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/1/events", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/babies/1/events", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data []server.Event `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got.Data) != 1 || got.Data[0].BabyID != baby.ID || got.Data[0].Type != "nursing" {
+		t.Fatalf("expected the nursing event, got %+v", got.Data)
+	}
+}
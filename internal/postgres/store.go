@@ -1,137 +1,1635 @@
 package postgres
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
 
 	"baby-tracker-server/internal/server"
 )
 
 type Store struct {
-	db *sql.DB
+	db                         *sql.DB
+	queryTimeout               time.Duration
+	bestEffortAudit            bool
+	schema                     string
+	detailsCompression         string
+	detailsCompressionMinBytes int
 }
 
-func New(ctx context.Context, databaseURL string) (*Store, error) {
-	db, err := sql.Open("pgx", databaseURL)
+const (
+	initialPingBackoff  = 250 * time.Millisecond
+	maxPingBackoff      = 5 * time.Second
+	defaultQueryTimeout = 3 * time.Second
+	defaultSchema       = "public"
+	// defaultToastTupleTarget is Postgres's own toast_tuple_target.
+	defaultToastTupleTarget = 2032
+)
+
+// DetailsCompressionMethods are the methods WithDetailsCompression
+// accepts. lz4 needs a Postgres server built with it.
+var DetailsCompressionMethods = []string{"pglz", "lz4"}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithQueryTimeout bounds every store method call, on top of whatever
+// deadline the caller's context already has. It defaults to 3s.
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(s *Store) {
+		s.queryTimeout = timeout
+	}
+}
+
+// WithBestEffortAudit lets event changes go through when their
+// event_audit row cannot be written; the failure is logged instead. By
+// default a failed audit write fails the change with it.
+func WithBestEffortAudit() Option {
+	return func(s *Store) {
+		s.bestEffortAudit = true
+	}
+}
+
+// WithSchema keeps the store's tables in the named Postgres schema, which
+// is created if missing, so tenants can share a database. Every
+// connection gets it as its search_path. It defaults to public. Names are
+// limited to lowercase letters, digits and underscores.
+func WithSchema(name string) Option {
+	return func(s *Store) {
+		s.schema = name
+	}
+}
+
+// WithDetailsCompression has Postgres compress large events.details
+// values with method, one of DetailsCompressionMethods, and decompress
+// them on read, so every query on details keeps working. Postgres only
+// compresses a row once it is longer than minBytes, between 128 and 8160,
+// or 2032 when zero, so small payloads are stored as they are, and it
+// records per value which form it keeps. Values written before the
+// option was set keep theirs. It needs Postgres 14 or later.
+func WithDetailsCompression(method string, minBytes int) Option {
+	return func(s *Store) {
+		s.detailsCompression = method
+		s.detailsCompressionMinBytes = minBytes
+	}
+}
+
+// ValidSchemaName reports whether name is accepted by WithSchema: an
+// unquoted Postgres identifier of at most 63 characters, in lower case
+// and outside the reserved pg_ prefix.
+func ValidSchemaName(name string) bool {
+	if name == "" || len(name) > 63 || strings.HasPrefix(name, "pg_") {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// New connects to Postgres, migrates the schema and seeds sample babies.
+// While the database is unreachable it retries with exponential backoff
+// until ctx is done, so callers bound startup with ctx's deadline.
+func New(ctx context.Context, databaseURL string, opts ...Option) (*Store, error) {
+	store := &Store{queryTimeout: defaultQueryTimeout, schema: defaultSchema}
+	for _, opt := range opts {
+		opt(store)
+	}
+	if !ValidSchemaName(store.schema) {
+		return nil, fmt.Errorf("invalid schema name %q", store.schema)
+	}
+	if store.detailsCompression != "" {
+		if !slices.Contains(DetailsCompressionMethods, store.detailsCompression) {
+			return nil, fmt.Errorf("invalid details compression %q, must be one of %s", store.detailsCompression, strings.Join(DetailsCompressionMethods, ", "))
+		}
+		store.detailsCompressionMinBytes = cmp.Or(store.detailsCompressionMinBytes, defaultToastTupleTarget)
+		if store.detailsCompressionMinBytes < 128 || store.detailsCompressionMinBytes > 8160 {
+			return nil, fmt.Errorf("details compression threshold must be between 128 and 8160 bytes, got %d", store.detailsCompressionMinBytes)
+		}
+	}
+
+	config, err := pgx.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres connection: %w", err)
+	}
+	config.RuntimeParams["search_path"] = store.schema
+	db := stdlib.OpenDB(*config)
+
+	if err := ping(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ping postgres: %w", err)
+	}
+
+	store.db = db
+	if err := store.migrate(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := store.seedBabies(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func ping(ctx context.Context, db *sql.DB) error {
+	backoff := initialPingBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+		slog.Warn("postgres not ready, retrying", "attempt", attempt, "backoff", backoff, "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxPingBackoff)
+	}
+}
+
+// Ping checks the database connection. It has no query timeout of its
+// own; the readiness probe bounds it with a shorter one.
+func (s *Store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping postgres: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// babySortExprs maps each server.BabySort to its ORDER BY expression.
+// Any other value, the empty default included, orders by id.
+var babySortExprs = map[server.BabySort]string{
+	server.BabySortID:        "id",
+	server.BabySortName:      "lower(name)",
+	server.BabySortCreatedAt: "created_at",
+}
+
+func (s *Store) ListBabies(ctx context.Context, filter server.BabyFilter) (_ []server.Baby, err error) {
+	ctx, done := s.begin(ctx, "ListBabies")
+	defer func() { err = done(err) }()
+
+	query := `
+		SELECT ` + babyColumns + `
+		FROM babies`
+	if !filter.IncludeDeleted {
+		query += " WHERE deleted_at IS NULL"
+	}
+	// Only the fixed expressions of babySortExprs reach the query.
+	orderBy, ok := babySortExprs[filter.Sort]
+	if !ok {
+		orderBy = babySortExprs[server.BabySortID]
+	}
+	direction := " ASC"
+	if filter.Descending {
+		direction = " DESC"
+	}
+	query += " ORDER BY " + orderBy + direction + ", id" + direction
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query babies: %w", err)
+	}
+	defer rows.Close()
+
+	data := make([]server.Baby, 0)
+	for rows.Next() {
+		b, err := scanBaby(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan baby: %w", err)
+		}
+		data = append(data, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate babies: %w", err)
+	}
+
+	return data, nil
+}
+
+func (s *Store) GetBaby(ctx context.Context, id int64, filter server.BabyFilter) (_ server.Baby, err error) {
+	ctx, done := s.begin(ctx, "GetBaby")
+	defer func() { err = done(err) }()
+
+	query := `
+		SELECT ` + babyColumns + `
+		FROM babies
+		WHERE id = $1`
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
+	b, err := scanBaby(s.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.Baby{}, server.ErrNotFound
+	}
+	if err != nil {
+		return server.Baby{}, fmt.Errorf("query baby: %w", err)
+	}
+
+	return b, nil
+}
+
+// UpdateBaby leaves a nil field as stored via COALESCE, and NULLIF turns
+// an empty color, avatar URL or timezone into NULL.
+func (s *Store) UpdateBaby(ctx context.Context, id int64, input server.UpdateBabyInput) (_ server.Baby, err error) {
+	ctx, done := s.begin(ctx, "UpdateBaby")
+	defer func() { err = done(err) }()
+
+	const query = `
+		UPDATE babies
+		SET name = COALESCE($2, name),
+			color = NULLIF(COALESCE($3, color), ''),
+			avatar_url = NULLIF(COALESCE($4, avatar_url), ''),
+			timezone = NULLIF(COALESCE($5, timezone), '')
+		WHERE id = $1
+			AND deleted_at IS NULL
+		RETURNING ` + babyColumns
+
+	baby, err := scanBaby(s.db.QueryRowContext(ctx, query, id, input.Name, input.Color, input.AvatarURL, input.Timezone))
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.Baby{}, server.ErrNotFound
+	}
+	if err != nil {
+		return server.Baby{}, fmt.Errorf("update baby: %w", err)
+	}
+
+	return baby, nil
+}
+
+// DeleteBaby archives a baby by setting deleted_at. Its events are kept.
+func (s *Store) DeleteBaby(ctx context.Context, id int64) (err error) {
+	ctx, done := s.begin(ctx, "DeleteBaby")
+	defer func() { err = done(err) }()
+
+	const query = `
+		UPDATE babies
+		SET deleted_at = NOW()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete baby: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete baby rows affected: %w", err)
+	}
+	if affected == 0 {
+		return server.ErrNotFound
+	}
+
+	return nil
+}
+
+// MergeBabies locks both babies, in id order so that concurrent merges
+// cannot deadlock, then moves the source's audit entries before its
+// events, so the update recorded for each moved event lands next to its
+// earlier history under the target.
+func (s *Store) MergeBabies(ctx context.Context, targetID, sourceID int64) (_ server.Baby, err error) {
+	ctx, done := s.begin(ctx, "MergeBabies")
+	defer func() { err = done(err) }()
+
+	const (
+		lockQuery = `
+			SELECT COUNT(*)
+			FROM (
+				SELECT id
+				FROM babies
+				WHERE id = ANY($1)
+					AND deleted_at IS NULL
+				ORDER BY id
+				FOR UPDATE
+			) AS locked
+		`
+		moveAuditQuery  = `UPDATE event_audit SET baby_id = $1 WHERE baby_id = $2`
+		moveEventsQuery = `UPDATE events SET baby_id = $1, updated_at = NOW() WHERE baby_id = $2 RETURNING id`
+		dropKeysQuery   = `DELETE FROM idempotency_keys WHERE baby_id = $1`
+		archiveQuery    = `UPDATE babies SET deleted_at = NOW() WHERE id = $1`
+		selectQuery     = `SELECT ` + babyColumns + ` FROM babies WHERE id = $1`
+	)
+
+	var baby server.Baby
+	err = s.WithTx(ctx, func(tx *sql.Tx) error {
+		var locked int
+		if err := tx.QueryRowContext(ctx, lockQuery, []int64{targetID, sourceID}).Scan(&locked); err != nil {
+			return fmt.Errorf("lock babies: %w", err)
+		}
+		if locked != 2 {
+			return server.ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, moveAuditQuery, targetID, sourceID); err != nil {
+			return fmt.Errorf("move event audit: %w", err)
+		}
+
+		rows, err := tx.QueryContext(ctx, moveEventsQuery, targetID, sourceID)
+		if err != nil {
+			return fmt.Errorf("move events: %w", err)
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan moved event: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Close(); err != nil {
+			return fmt.Errorf("move events: %w", err)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("move events: %w", err)
+		}
+		if len(ids) > 0 {
+			if err := s.recordAudit(ctx, tx, auditUpdate, ids...); err != nil {
+				return err
+			}
+		}
+
+		// The source's keys would now name the target's events.
+		if _, err := tx.ExecContext(ctx, dropKeysQuery, sourceID); err != nil {
+			return fmt.Errorf("drop idempotency keys: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, archiveQuery, sourceID); err != nil {
+			return fmt.Errorf("archive merged baby: %w", err)
+		}
+
+		if baby, err = scanBaby(tx.QueryRowContext(ctx, selectQuery, targetID)); err != nil {
+			return fmt.Errorf("select merged baby: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return server.Baby{}, err
+	}
+
+	return baby, nil
+}
+
+func (s *Store) CreateEvent(ctx context.Context, input server.CreateEventInput) (_ server.Event, err error) {
+	ctx, done := s.begin(ctx, "CreateEvent")
+	defer func() { err = done(err) }()
+
+	var event server.Event
+	err = s.WithTx(ctx, func(tx *sql.Tx) error {
+		event, err = s.createEvent(ctx, tx, input)
+		return err
+	})
+	if err != nil {
+		return server.Event{}, err
+	}
+
+	return event, nil
+}
+
+// CreateEventTx is CreateEvent inside a transaction the caller owns, e.g.
+// one started by WithTx. The event is only visible once tx commits.
+func (s *Store) CreateEventTx(ctx context.Context, tx *sql.Tx, input server.CreateEventInput) (_ server.Event, err error) {
+	ctx, done := s.begin(ctx, "CreateEventTx")
+	defer func() { err = done(err) }()
+
+	return s.createEvent(ctx, tx, input)
+}
+
+// createEvent inserts the event and its audit row in tx.
+func (s *Store) createEvent(ctx context.Context, tx *sql.Tx, input server.CreateEventInput) (server.Event, error) {
+	event, err := insertEvent(ctx, tx, input)
+	if err != nil {
+		return server.Event{}, err
+	}
+	if err := s.recordAudit(ctx, tx, auditCreate, event.ID); err != nil {
+		return server.Event{}, err
+	}
+	return event, nil
+}
+
+// WithTx runs fn in a transaction. It commits when fn returns nil and
+// rolls back when fn returns an error or panics; fn's error is returned
+// as is. The query timeout does not apply to the transaction as a whole,
+// only to the store methods fn calls, so ctx should carry any overall
+// deadline.
+func (s *Store) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertEvent inserts a validated event through q, which is either the
+// pool or a transaction.
+func insertEvent(ctx context.Context, q querier, input server.CreateEventInput) (server.Event, error) {
+	const query = `
+		INSERT INTO events (baby_id, type, occurred_at, details, tags)
+		VALUES ($1, $2, $3, $4, COALESCE($5::text[], '{}'))
+		RETURNING ` + eventColumns
+
+	event, err := scanEvent(q.QueryRowContext(
+		ctx,
+		query,
+		input.BabyID,
+		input.Type,
+		input.OccurredAt,
+		input.Details,
+		input.Tags,
+	))
+	if err != nil {
+		return server.Event{}, fmt.Errorf("insert event: %w", constraintError(err))
+	}
+
+	return event, nil
+}
+
+// PurgeEventsOlderThan deletes every event that occurred before cutoff,
+// for all babies, and returns how many were deleted. Idempotency keys
+// pointing at them go with them. It runs in the background rather than
+// for a request, so the query timeout does not apply: the first purge of
+// a long history may take a while.
+func (s *Store) PurgeEventsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	start := time.Now()
+	defer func() {
+		slog.DebugContext(ctx, "postgres query", "method", "PurgeEventsOlderThan", "duration", time.Since(start))
+	}()
+
+	const query = `DELETE FROM events WHERE occurred_at < $1`
+
+	result, err := s.db.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge events: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purge events: %w", err)
+	}
+
+	return purged, nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes idempotency keys claimed more than
+// server.IdempotencyKeyTTL ago and returns how many were deleted. A
+// request only expires its own key, so without this the table would keep
+// every key ever used. Like PurgeEventsOlderThan, it runs in the
+// background without the query timeout.
+func (s *Store) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	start := time.Now()
+	defer func() {
+		slog.DebugContext(ctx, "postgres query", "method", "PurgeExpiredIdempotencyKeys", "duration", time.Since(start))
+	}()
+
+	const query = `DELETE FROM idempotency_keys WHERE created_at < NOW() - $1 * INTERVAL '1 second'`
+
+	result, err := s.db.ExecContext(ctx, query, server.IdempotencyKeyTTL.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("purge idempotency keys: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purge idempotency keys: %w", err)
+	}
+
+	return purged, nil
+}
+
+// CreateEventIdempotent inserts the event and claims key in one
+// transaction. If a concurrent request claims the same key first, the
+// insert is rolled back and that request's event is returned instead.
+func (s *Store) CreateEventIdempotent(ctx context.Context, key string, input server.CreateEventInput) (_ server.Event, _ bool, err error) {
+	ctx, done := s.begin(ctx, "CreateEventIdempotent")
+	defer func() { err = done(err) }()
+
+	const (
+		expireQuery = `
+			DELETE FROM idempotency_keys
+			WHERE baby_id = $1 AND key = $2 AND created_at < NOW() - $3 * INTERVAL '1 second'
+		`
+		replayQuery = `
+			SELECT ` + eventColumns + `
+			FROM events
+			WHERE id = (SELECT event_id FROM idempotency_keys WHERE baby_id = $1 AND key = $2)
+				AND baby_id = $1
+		`
+		claimQuery = `
+			INSERT INTO idempotency_keys (baby_id, key, event_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (baby_id, key) DO NOTHING
+		`
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return server.Event{}, false, fmt.Errorf("begin create event: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, expireQuery, input.BabyID, key, server.IdempotencyKeyTTL.Seconds()); err != nil {
+		return server.Event{}, false, fmt.Errorf("expire idempotency key: %w", err)
+	}
+
+	event, err := scanEvent(tx.QueryRowContext(ctx, replayQuery, input.BabyID, key))
+	if err == nil {
+		return event, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return server.Event{}, false, fmt.Errorf("query idempotency key: %w", err)
+	}
+
+	event, err = insertEvent(ctx, tx, input)
+	if err != nil {
+		return server.Event{}, false, err
+	}
+
+	result, err := tx.ExecContext(ctx, claimQuery, input.BabyID, key, event.ID)
+	if err != nil {
+		return server.Event{}, false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return server.Event{}, false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	if claimed == 0 {
+		// The claim waited for the other transaction to commit, so its
+		// event is visible once this one is rolled back.
+		_ = tx.Rollback()
+		event, err := scanEvent(s.db.QueryRowContext(ctx, replayQuery, input.BabyID, key))
+		if err != nil {
+			return server.Event{}, false, fmt.Errorf("query idempotency key: %w", err)
+		}
+		return event, false, nil
+	}
+
+	if err := s.recordAudit(ctx, tx, auditCreate, event.ID); err != nil {
+		return server.Event{}, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return server.Event{}, false, fmt.Errorf("commit create event: %w", err)
+	}
+
+	return event, true, nil
+}
+
+func (s *Store) GetIdempotentEvent(ctx context.Context, babyID int64, key string) (_ server.Event, err error) {
+	ctx, done := s.begin(ctx, "GetIdempotentEvent")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE id = (
+			SELECT event_id
+			FROM idempotency_keys
+			WHERE baby_id = $1 AND key = $2 AND created_at >= NOW() - $3 * INTERVAL '1 second'
+		)
+			AND baby_id = $1
+	`
+
+	event, err := scanEvent(s.db.QueryRowContext(ctx, query, babyID, key, server.IdempotencyKeyTTL.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.Event{}, server.ErrNotFound
+	}
+	if err != nil {
+		return server.Event{}, fmt.Errorf("query idempotency key: %w", err)
+	}
+
+	return event, nil
+}
+
+func (s *Store) GetEvent(ctx context.Context, babyID, eventID int64) (_ server.Event, err error) {
+	ctx, done := s.begin(ctx, "GetEvent")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE id = $1 AND baby_id = $2
+	`
+
+	event, err := scanEvent(s.db.QueryRowContext(ctx, query, eventID, babyID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.Event{}, server.ErrNotFound
+	}
+	if err != nil {
+		return server.Event{}, fmt.Errorf("query event: %w", err)
+	}
+
+	return event, nil
+}
+
+func (s *Store) FindNearbyEvent(ctx context.Context, babyID int64, eventType string, at time.Time, window time.Duration) (_ server.Event, err error) {
+	ctx, done := s.begin(ctx, "FindNearbyEvent")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE baby_id = $1
+			AND type = $2
+			AND occurred_at BETWEEN $3 AND $4
+		ORDER BY ABS(EXTRACT(EPOCH FROM occurred_at - $5::timestamptz)), id
+		LIMIT 1
+	`
+
+	event, err := scanEvent(s.db.QueryRowContext(ctx, query, babyID, eventType, at.Add(-window), at.Add(window), at))
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.Event{}, server.ErrNotFound
+	}
+	if err != nil {
+		return server.Event{}, fmt.Errorf("query nearby event: %w", err)
+	}
+
+	return event, nil
+}
+
+// FindOverlappingEvent compares sessions as half-open tstzranges. A NULL
+// upper bound leaves a range unbounded, which is how a sleep without
+// end_at, and an open end, are treated.
+func (s *Store) FindOverlappingEvent(ctx context.Context, babyID int64, eventType string, start time.Time, end *time.Time) (_ server.Event, err error) {
+	ctx, done := s.begin(ctx, "FindOverlappingEvent")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE baby_id = $1
+			AND type = $2
+			AND tstzrange(
+				occurred_at,
+				CASE type
+					WHEN 'sleep' THEN (details->>'end_at')::timestamptz
+					ELSE occurred_at + make_interval(mins => (details->>'duration_minutes')::int)
+				END,
+				'[)'
+			) && tstzrange($3, $4, '[)')
+		ORDER BY occurred_at, id
+		LIMIT 1
+	`
+
+	event, err := scanEvent(s.db.QueryRowContext(ctx, query, babyID, eventType, start, end))
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.Event{}, server.ErrNotFound
+	}
+	if err != nil {
+		return server.Event{}, fmt.Errorf("query overlapping event: %w", err)
+	}
+
+	return event, nil
+}
+
+func (s *Store) ListEvents(ctx context.Context, babyID int64, filter server.EventFilter) (_ []server.Event, err error) {
+	ctx, done := s.begin(ctx, "ListEvents")
+	defer func() { err = done(err) }()
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE baby_id = $1`
+	conditions, args := eventFilterConditions(filter, []any{babyID})
+	query += conditions
+
+	comparison, direction := ">", "ASC"
+	if filter.Newest {
+		comparison, direction = "<", "DESC"
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.OccurredAt, filter.After.ID)
+		query += fmt.Sprintf(" AND (occurred_at, id) %s ($%d, $%d)", comparison, len(args)-1, len(args))
+	}
+
+	query += fmt.Sprintf(" ORDER BY occurred_at %[1]s, id %[1]s", direction)
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+	defer rows.Close()
+
+	data := make([]server.Event, 0)
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		data = append(data, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate events: %w", err)
+	}
+
+	return data, nil
+}
+
+// StreamEvents calls fn for each of the baby's events in occurred_at
+// order as rows arrive, without loading them all. It has no query
+// timeout, since a large export legitimately runs as long as the client
+// keeps reading; the caller's context bounds it instead.
+func (s *Store) StreamEvents(ctx context.Context, babyID int64, fn func(server.Event) error) error {
+	start := time.Now()
+	defer func() {
+		slog.DebugContext(ctx, "postgres query", "method", "StreamEvents", "duration", time.Since(start))
+	}()
+
+	const query = `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE baby_id = $1
+		ORDER BY occurred_at ASC, id ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, babyID)
+	if err != nil {
+		return fmt.Errorf("query events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return fmt.Errorf("scan event: %w", err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate events: %w", err)
+	}
+
+	return nil
+}
+
+// CountEvents counts the events matching filter. Its paging fields are
+// ignored, so the count covers every page.
+func (s *Store) CountEvents(ctx context.Context, babyID int64, filter server.EventFilter) (_ int64, err error) {
+	ctx, done := s.begin(ctx, "CountEvents")
+	defer func() { err = done(err) }()
+
+	conditions, args := eventFilterConditions(filter, []any{babyID})
+	query := `SELECT COUNT(*) FROM events WHERE baby_id = $1` + conditions
+
+	var count int64
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count events: %w", err)
+	}
+
+	return count, nil
+}
+
+// eventFilterConditions turns the Types, Tags and Notes of filter into
+// AND conditions, numbering their placeholders after args.
+func eventFilterConditions(filter server.EventFilter, args []any) (string, []any) {
+	var conditions string
+
+	if len(filter.Types) > 0 {
+		args = append(args, filter.Types)
+		conditions += fmt.Sprintf(" AND type = ANY($%d)", len(args))
+	}
+
+	if len(filter.Tags) > 0 {
+		args = append(args, filter.Tags)
+		conditions += fmt.Sprintf(" AND tags @> $%d::text[]", len(args))
+	}
+
+	if filter.Notes != "" {
+		args = append(args, "%"+escapeLike(filter.Notes)+"%")
+		conditions += fmt.Sprintf(` AND details->>'notes' ILIKE $%d ESCAPE '\'`, len(args))
+	}
+
+	return conditions, args
+}
+
+func (s *Store) CreateEvents(ctx context.Context, inputs []server.CreateEventInput) (_ []server.Event, err error) {
+	ctx, done := s.begin(ctx, "CreateEvents")
+	defer func() { err = done(err) }()
+
+	var events []server.Event
+	err = s.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		events, err = s.insertEvents(ctx, tx, inputs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ImportBaby inserts the baby and then its events, all in one
+// transaction.
+func (s *Store) ImportBaby(ctx context.Context, input server.NewBabyInput, inputs []server.CreateEventInput) (_ server.Baby, err error) {
+	ctx, done := s.begin(ctx, "ImportBaby")
+	defer func() { err = done(err) }()
+
+	const query = `
+		INSERT INTO babies (name, color, avatar_url, timezone)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + babyColumns
+
+	var baby server.Baby
+	err = s.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if baby, err = scanBaby(tx.QueryRowContext(ctx, query, input.Name, input.Color, input.AvatarURL, input.Timezone)); err != nil {
+			return fmt.Errorf("insert baby: %w", err)
+		}
+
+		inputs := slices.Clone(inputs)
+		for i := range inputs {
+			inputs[i].BabyID = baby.ID
+		}
+		_, err = s.insertEvents(ctx, tx, inputs)
+		return err
+	})
+	if err != nil {
+		return server.Baby{}, err
+	}
+
+	return baby, nil
+}
+
+// insertEvents inserts inputs with one prepared statement and audits them.
+func (s *Store) insertEvents(ctx context.Context, tx *sql.Tx, inputs []server.CreateEventInput) ([]server.Event, error) {
+	const query = `
+		INSERT INTO events (baby_id, type, occurred_at, details, tags)
+		VALUES ($1, $2, $3, $4, COALESCE($5::text[], '{}'))
+		RETURNING ` + eventColumns
+
+	if len(inputs) == 0 {
+		return []server.Event{}, nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("prepare insert event: %w", err)
+	}
+	defer stmt.Close()
+
+	events := make([]server.Event, 0, len(inputs))
+	for i, input := range inputs {
+		event, err := scanEvent(stmt.QueryRowContext(
+			ctx,
+			input.BabyID,
+			input.Type,
+			input.OccurredAt,
+			input.Details,
+			input.Tags,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("insert event %d: %w", i, constraintError(err))
+		}
+		events = append(events, event)
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	if err := s.recordAudit(ctx, tx, auditCreate, ids...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s *Store) ListWeightEntries(ctx context.Context, babyID int64) (_ []server.WeightEntry, err error) {
+	ctx, done := s.begin(ctx, "ListWeightEntries")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT id, occurred_at, (details->>'weight_kg')::double precision AS weight_kg
+		FROM events
+		WHERE baby_id = $1
+			AND type = 'weight'
+			AND details ? 'weight_kg'
+		ORDER BY occurred_at ASC, id ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, babyID)
+	if err != nil {
+		return nil, fmt.Errorf("query weight entries: %w", err)
+	}
+	defer rows.Close()
+
+	data := make([]server.WeightEntry, 0)
+	for rows.Next() {
+		var entry server.WeightEntry
+		if err := rows.Scan(&entry.ID, &entry.OccurredAt, &entry.WeightKg); err != nil {
+			return nil, fmt.Errorf("scan weight entry: %w", err)
+		}
+		data = append(data, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate weight entries: %w", err)
+	}
+
+	return data, nil
+}
+
+// CreateWeightEntries inserts entries as weight events with the same
+// statement as CreateEvents.
+func (s *Store) CreateWeightEntries(ctx context.Context, babyID int64, entries []server.NewWeightEntry) (_ []server.WeightEntry, err error) {
+	ctx, done := s.begin(ctx, "CreateWeightEntries")
+	defer func() { err = done(err) }()
+
+	inputs := make([]server.CreateEventInput, 0, len(entries))
+	for _, entry := range entries {
+		details, err := json.Marshal(map[string]float64{"weight_kg": entry.WeightKg})
+		if err != nil {
+			return nil, fmt.Errorf("encode weight: %w", err)
+		}
+		inputs = append(inputs, server.CreateEventInput{
+			BabyID:     babyID,
+			Type:       "weight",
+			OccurredAt: entry.OccurredAt,
+			Details:    details,
+		})
+	}
+
+	var events []server.Event
+	err = s.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		events, err = s.insertEvents(ctx, tx, inputs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]server.WeightEntry, 0, len(events))
+	for i, event := range events {
+		data = append(data, server.WeightEntry{ID: event.ID, OccurredAt: event.OccurredAt, WeightKg: entries[i].WeightKg})
+	}
+	return data, nil
+}
+
+// DeleteWeightEntry deletes a weight event of the baby. The audit entry
+// is written first, while the event can still be read.
+func (s *Store) DeleteWeightEntry(ctx context.Context, babyID, weightID int64) (err error) {
+	ctx, done := s.begin(ctx, "DeleteWeightEntry")
+	defer func() { err = done(err) }()
+
+	const (
+		selectQuery = `
+			SELECT id
+			FROM events
+			WHERE id = $1 AND baby_id = $2 AND type = 'weight'
+			FOR UPDATE
+		`
+		deleteQuery = `DELETE FROM events WHERE id = $1`
+	)
+
+	return s.WithTx(ctx, func(tx *sql.Tx) error {
+		var id int64
+		if err := tx.QueryRowContext(ctx, selectQuery, weightID, babyID).Scan(&id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return server.ErrNotFound
+			}
+			return fmt.Errorf("select weight entry: %w", err)
+		}
+
+		if err := s.recordAudit(ctx, tx, auditDelete, id); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteQuery, id); err != nil {
+			return fmt.Errorf("delete weight entry: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) PreviousWeight(ctx context.Context, babyID int64, at time.Time) (_ server.WeightEntry, err error) {
+	ctx, done := s.begin(ctx, "PreviousWeight")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT id, occurred_at, (details->>'weight_kg')::double precision AS weight_kg
+		FROM events
+		WHERE baby_id = $1
+			AND type = 'weight'
+			AND details ? 'weight_kg'
+			AND occurred_at <= $2
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT 1
+	`
+
+	var entry server.WeightEntry
+	err = s.db.QueryRowContext(ctx, query, babyID, at).Scan(&entry.ID, &entry.OccurredAt, &entry.WeightKg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.WeightEntry{}, server.ErrNotFound
+	}
+	if err != nil {
+		return server.WeightEntry{}, fmt.Errorf("query previous weight: %w", err)
+	}
+
+	return entry, nil
+}
+
+// LatestWeight reads the two most recent weight entries to report the
+// latest one along with its change from the previous.
+func (s *Store) LatestWeight(ctx context.Context, babyID int64) (_ server.LatestWeight, err error) {
+	ctx, done := s.begin(ctx, "LatestWeight")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT occurred_at, (details->>'weight_kg')::double precision AS weight_kg
+		FROM events
+		WHERE baby_id = $1
+			AND type = 'weight'
+			AND details ? 'weight_kg'
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT 2
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, babyID)
 	if err != nil {
-		return nil, fmt.Errorf("open postgres connection: %w", err)
+		return server.LatestWeight{}, fmt.Errorf("query latest weight: %w", err)
 	}
+	defer rows.Close()
 
-	if err := db.PingContext(ctx); err != nil {
-		_ = db.Close()
-		return nil, fmt.Errorf("ping postgres: %w", err)
+	entries := make([]server.WeightEntry, 0, 2)
+	for rows.Next() {
+		var entry server.WeightEntry
+		if err := rows.Scan(&entry.OccurredAt, &entry.WeightKg); err != nil {
+			return server.LatestWeight{}, fmt.Errorf("scan latest weight: %w", err)
+		}
+		entries = append(entries, entry)
 	}
 
-	store := &Store{db: db}
-	if err := store.migrate(ctx); err != nil {
-		_ = db.Close()
-		return nil, err
+	if err := rows.Err(); err != nil {
+		return server.LatestWeight{}, fmt.Errorf("iterate latest weight: %w", err)
 	}
-	if err := store.seedBabies(ctx); err != nil {
-		_ = db.Close()
-		return nil, err
+	if len(entries) == 0 {
+		return server.LatestWeight{}, server.ErrNotFound
 	}
 
-	return store, nil
+	latest := server.LatestWeight{OccurredAt: entries[0].OccurredAt, WeightKg: entries[0].WeightKg}
+	if len(entries) == 2 {
+		delta := entries[0].WeightKg - entries[1].WeightKg
+		latest.DeltaKg = &delta
+	}
+	return latest, nil
 }
 
-func (s *Store) Close() error {
-	return s.db.Close()
-}
+func (s *Store) ListSleepSessions(ctx context.Context, babyID int64, from, to time.Time) (_ []server.SleepSession, err error) {
+	ctx, done := s.begin(ctx, "ListSleepSessions")
+	defer func() { err = done(err) }()
 
-func (s *Store) ListBabies(ctx context.Context) ([]server.Baby, error) {
+	// A sleep without end_at is still going, so it runs until now or the
+	// end of the range, whichever comes first.
 	const query = `
-		SELECT id, name
-		FROM babies
-		ORDER BY id
+		SELECT start_at, end_at
+		FROM (
+			SELECT
+				occurred_at,
+				id,
+				(details->>'start_at')::timestamptz AS start_at,
+				COALESCE((details->>'end_at')::timestamptz, LEAST(NOW(), $3)) AS end_at,
+				details->>'end_at' IS NOT NULL AS ended
+			FROM events
+			WHERE baby_id = $1 AND type = 'sleep' AND details ? 'start_at'
+		) AS sleeps
+		WHERE start_at < $3
+			AND end_at > $2
+			AND (ended OR end_at > start_at)
+		ORDER BY occurred_at ASC, id ASC
 	`
 
-	rows, err := s.db.QueryContext(ctx, query)
+	rows, err := s.db.QueryContext(ctx, query, babyID, from, to)
 	if err != nil {
-		return nil, fmt.Errorf("query babies: %w", err)
+		return nil, fmt.Errorf("query sleep sessions: %w", err)
 	}
 	defer rows.Close()
 
-	data := make([]server.Baby, 0)
+	data := make([]server.SleepSession, 0)
 	for rows.Next() {
-		var b server.Baby
-		if err := rows.Scan(&b.ID, &b.Name); err != nil {
-			return nil, fmt.Errorf("scan baby: %w", err)
+		var session server.SleepSession
+		if err := rows.Scan(&session.StartAt, &session.EndAt); err != nil {
+			return nil, fmt.Errorf("scan sleep session: %w", err)
 		}
-		data = append(data, b)
+		data = append(data, session)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate babies: %w", err)
+		return nil, fmt.Errorf("iterate sleep sessions: %w", err)
 	}
 
 	return data, nil
 }
 
-func (s *Store) CreateEvent(ctx context.Context, input server.CreateEventInput) (server.Event, error) {
+func (s *Store) GetBabyStatus(ctx context.Context, babyID int64) (_ server.BabyStatus, err error) {
+	ctx, done := s.begin(ctx, "GetBabyStatus")
+	defer func() { err = done(err) }()
+
 	const query = `
-		INSERT INTO events (baby_id, type, occurred_at, details)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, baby_id, type, occurred_at, details
+		SELECT
+			(
+				SELECT MAX(occurred_at)
+				FROM events
+				WHERE baby_id = $1 AND type = 'nursing'
+			) AS last_feeding_at,
+			(
+				SELECT MAX(occurred_at)
+				FROM events
+				WHERE baby_id = $1 AND type = 'diaper'
+			) AS last_diaper_at,
+			(
+				SELECT MAX(occurred_at)
+				FROM events
+				WHERE baby_id = $1 AND type = 'sleep' AND NOT details ? 'end_at'
+			) AS asleep_since
 	`
 
-	var event server.Event
-	if err := s.db.QueryRowContext(
-		ctx,
-		query,
-		input.BabyID,
-		input.Type,
-		input.OccurredAt,
-		input.Details,
-	).Scan(
-		&event.ID,
-		&event.BabyID,
-		&event.Type,
-		&event.OccurredAt,
-		&event.Details,
-	); err != nil {
-		return server.Event{}, fmt.Errorf("insert event: %w", err)
+	var lastFeedingAt, lastDiaperAt, asleepSince sql.NullTime
+	if err := s.db.QueryRowContext(ctx, query, babyID).Scan(&lastFeedingAt, &lastDiaperAt, &asleepSince); err != nil {
+		return server.BabyStatus{}, fmt.Errorf("query baby status: %w", err)
 	}
 
-	return event, nil
+	return server.BabyStatus{
+		LastFeedingAt: nullTimePtr(lastFeedingAt),
+		LastDiaperAt:  nullTimePtr(lastDiaperAt),
+		Asleep:        asleepSince.Valid,
+		AsleepSince:   nullTimePtr(asleepSince),
+	}, nil
 }
 
-func (s *Store) ListWeightEntries(ctx context.Context, babyID int64) ([]server.WeightEntry, error) {
+func (s *Store) FeedingIntervals(ctx context.Context, babyID int64, from, to time.Time) (_ server.FeedingIntervals, err error) {
+	ctx, done := s.begin(ctx, "FeedingIntervals")
+	defer func() { err = done(err) }()
+
 	const query = `
-		SELECT occurred_at, (details->>'weight_kg')::double precision AS weight_kg
+		SELECT
+			COUNT(*) AS feeds,
+			AVG(gap_minutes) AS avg_minutes,
+			MIN(gap_minutes) AS min_minutes,
+			MAX(gap_minutes) AS max_minutes
+		FROM (
+			SELECT (EXTRACT(EPOCH FROM occurred_at - lag(occurred_at) OVER (ORDER BY occurred_at, id)) / 60)::double precision AS gap_minutes
+			FROM events
+			WHERE baby_id = $1
+				AND type = 'nursing'
+				AND occurred_at >= $2
+				AND occurred_at < $3
+		) AS gaps
+	`
+
+	var (
+		intervals           server.FeedingIntervals
+		avg, minGap, maxGap sql.NullFloat64
+	)
+	if err := s.db.QueryRowContext(ctx, query, babyID, from, to).Scan(&intervals.Feeds, &avg, &minGap, &maxGap); err != nil {
+		return server.FeedingIntervals{}, fmt.Errorf("query feeding intervals: %w", err)
+	}
+	intervals.AverageMinutes = nullFloatPtr(avg)
+	intervals.MinMinutes = nullFloatPtr(minGap)
+	intervals.MaxMinutes = nullFloatPtr(maxGap)
+
+	return intervals, nil
+}
+
+// BabyStats counts a baby's events per type and measures nursing sessions
+// and finished sleeps in the same pass.
+func (s *Store) BabyStats(ctx context.Context, babyID int64) (_ server.BabyStats, err error) {
+	ctx, done := s.begin(ctx, "BabyStats")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT
+			type,
+			COUNT(*) AS events,
+			AVG((details->>'duration_minutes')::double precision) FILTER (WHERE type = 'nursing') AS avg_nursing_minutes,
+			MAX((EXTRACT(EPOCH FROM (details->>'end_at')::timestamptz - (details->>'start_at')::timestamptz) / 60)::double precision)
+				FILTER (WHERE type = 'sleep' AND details ? 'end_at') AS longest_sleep_minutes
 		FROM events
 		WHERE baby_id = $1
-			AND type = 'weight'
-			AND details ? 'weight_kg'
-		ORDER BY occurred_at ASC, id ASC
+		GROUP BY type
 	`
 
 	rows, err := s.db.QueryContext(ctx, query, babyID)
 	if err != nil {
-		return nil, fmt.Errorf("query weight entries: %w", err)
+		return server.BabyStats{}, fmt.Errorf("query baby stats: %w", err)
 	}
 	defer rows.Close()
 
-	data := make([]server.WeightEntry, 0)
+	stats := server.BabyStats{EventCounts: make(map[string]int64)}
 	for rows.Next() {
-		var entry server.WeightEntry
-		if err := rows.Scan(&entry.OccurredAt, &entry.WeightKg); err != nil {
-			return nil, fmt.Errorf("scan weight entry: %w", err)
+		var (
+			eventType          string
+			count              int64
+			avgNursing, sleeps sql.NullFloat64
+		)
+		if err := rows.Scan(&eventType, &count, &avgNursing, &sleeps); err != nil {
+			return server.BabyStats{}, fmt.Errorf("scan baby stats: %w", err)
+		}
+		stats.EventCounts[eventType] = count
+		if avgNursing.Valid {
+			stats.AverageNursingMinutes = nullFloatPtr(avgNursing)
+		}
+		if sleeps.Valid {
+			stats.LongestSleepMinutes = nullFloatPtr(sleeps)
 		}
-		data = append(data, entry)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate weight entries: %w", err)
+		return server.BabyStats{}, fmt.Errorf("iterate baby stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// EventCountsByLocalDate counts a baby's events in [from, to) grouped by
+// calendar date in the tz time zone. Dates are keyed as YYYY-MM-DD and
+// days without events are omitted.
+func (s *Store) EventCountsByLocalDate(ctx context.Context, babyID int64, from, to time.Time, tz string) (_ map[string]int64, err error) {
+	ctx, done := s.begin(ctx, "EventCountsByLocalDate")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT to_char((occurred_at AT TIME ZONE $4)::date, 'YYYY-MM-DD') AS local_date, COUNT(*)
+		FROM events
+		WHERE baby_id = $1
+			AND occurred_at >= $2
+			AND occurred_at < $3
+		GROUP BY local_date
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, babyID, from, to, tz)
+	if err != nil {
+		return nil, fmt.Errorf("query event counts by local date: %w", err)
+	}
+	defer rows.Close()
+
+	data := make(map[string]int64)
+	for rows.Next() {
+		var (
+			date  string
+			count int64
+		)
+		if err := rows.Scan(&date, &count); err != nil {
+			return nil, fmt.Errorf("scan event count: %w", err)
+		}
+		data[date] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate event counts: %w", err)
+	}
+
+	return data, nil
+}
+
+// DailyCounts totals a baby's diapers and feeds in [from, to) per
+// calendar date in the tz time zone. Days without events are omitted.
+func (s *Store) DailyCounts(ctx context.Context, babyID int64, from, to time.Time, tz string) (_ map[string]server.DailyCounts, err error) {
+	ctx, done := s.begin(ctx, "DailyCounts")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT
+			to_char(date_trunc('day', occurred_at AT TIME ZONE $4), 'YYYY-MM-DD') AS local_date,
+			COUNT(*) FILTER (WHERE type = 'diaper') AS diapers,
+			COUNT(*) FILTER (WHERE type = 'diaper' AND details->>'kind' = 'wet') AS wet_diapers,
+			COUNT(*) FILTER (WHERE type = 'diaper' AND details->>'kind' = 'dirty') AS dirty_diapers,
+			COUNT(*) FILTER (WHERE type = 'diaper' AND details->>'kind' = 'mixed') AS mixed_diapers,
+			COUNT(*) FILTER (WHERE type = 'nursing') AS feeds,
+			COALESCE(SUM((details->>'duration_minutes')::int) FILTER (WHERE type = 'nursing'), 0) AS nursing_minutes
+		FROM events
+		WHERE baby_id = $1
+			AND occurred_at >= $2
+			AND occurred_at < $3
+		GROUP BY local_date
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, babyID, from, to, tz)
+	if err != nil {
+		return nil, fmt.Errorf("query daily counts: %w", err)
+	}
+	defer rows.Close()
+
+	data := make(map[string]server.DailyCounts)
+	for rows.Next() {
+		var (
+			date   string
+			counts server.DailyCounts
+		)
+		if err := rows.Scan(&date, &counts.Diapers, &counts.WetDiapers, &counts.DirtyDiapers, &counts.MixedDiapers, &counts.Feeds, &counts.NursingMinutes); err != nil {
+			return nil, fmt.Errorf("scan daily counts: %w", err)
+		}
+		data[date] = counts
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate daily counts: %w", err)
 	}
 
 	return data, nil
 }
 
+func (s *Store) RecentSideBalance(ctx context.Context, babyID int64, limit int) (_ server.SideBalance, err error) {
+	ctx, done := s.begin(ctx, "RecentSideBalance")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT
+			COUNT(*) FILTER (WHERE side = 'left') AS left_count,
+			COUNT(*) FILTER (WHERE side = 'right') AS right_count,
+			(array_agg(side ORDER BY occurred_at DESC, id DESC))[1] AS last_side
+		FROM (
+			SELECT id, occurred_at, details->>'side' AS side
+			FROM events
+			WHERE baby_id = $1 AND type = 'nursing'
+			ORDER BY occurred_at DESC, id DESC
+			LIMIT $2
+		) recent
+	`
+
+	var (
+		balance  server.SideBalance
+		lastSide sql.NullString
+	)
+	if err := s.db.QueryRowContext(ctx, query, babyID, limit).Scan(&balance.Left, &balance.Right, &lastSide); err != nil {
+		return server.SideBalance{}, fmt.Errorf("query recent side balance: %w", err)
+	}
+	balance.LastSide = lastSide.String
+
+	return balance, nil
+}
+
+// NursingMinutesBySide sums the logged duration of a baby's nursing events
+// in [from, to) per side.
+func (s *Store) NursingMinutesBySide(ctx context.Context, babyID int64, from, to time.Time) (_ server.SideMinutes, err error) {
+	ctx, done := s.begin(ctx, "NursingMinutesBySide")
+	defer func() { err = done(err) }()
+
+	const query = `
+		SELECT details->>'side' AS side, COALESCE(SUM((details->>'duration_minutes')::int), 0)
+		FROM events
+		WHERE baby_id = $1
+			AND type = 'nursing'
+			AND occurred_at >= $2
+			AND occurred_at < $3
+		GROUP BY side
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, babyID, from, to)
+	if err != nil {
+		return server.SideMinutes{}, fmt.Errorf("query nursing minutes by side: %w", err)
+	}
+	defer rows.Close()
+
+	var minutes server.SideMinutes
+	for rows.Next() {
+		var (
+			side  sql.NullString
+			total int
+		)
+		if err := rows.Scan(&side, &total); err != nil {
+			return server.SideMinutes{}, fmt.Errorf("scan nursing minutes: %w", err)
+		}
+		switch side.String {
+		case "left":
+			minutes.Left = total
+		case "right":
+			minutes.Right = total
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return server.SideMinutes{}, fmt.Errorf("iterate nursing minutes: %w", err)
+	}
+
+	return minutes, nil
+}
+
+// Actions recorded in event_audit.
+const (
+	auditCreate = "create"
+	auditUpdate = "update"
+	auditDelete = "delete"
+)
+
+// recordAudit logs action on the events in tx, attributed to the actor of
+// ctx. The baby is read from each event, which must already be written in
+// tx. In best-effort mode a failed write is rolled back to a savepoint,
+// so the rest of the transaction can still commit.
+func (s *Store) recordAudit(ctx context.Context, tx *sql.Tx, action string, eventIDs ...int64) error {
+	const query = `
+		INSERT INTO event_audit (baby_id, event_id, action, actor)
+		SELECT baby_id, id, $2, NULLIF($3, '')
+		FROM events
+		WHERE id = ANY($1)
+	`
+
+	actor := server.ActorFromContext(ctx)
+	if !s.bestEffortAudit {
+		if _, err := tx.ExecContext(ctx, query, eventIDs, action, actor); err != nil {
+			return fmt.Errorf("record event audit: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT event_audit"); err != nil {
+		return fmt.Errorf("record event audit: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, eventIDs, action, actor); err != nil {
+		slog.WarnContext(ctx, "event audit write failed", "action", action, "event_ids", eventIDs, "error", err)
+		if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT event_audit"); err != nil {
+			return fmt.Errorf("roll back event audit: %w", err)
+		}
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT event_audit"); err != nil {
+		return fmt.Errorf("record event audit: %w", err)
+	}
+	return nil
+}
+
+// EventHistory lists the audit entries of one of a baby's events, oldest
+// first. Events logged before auditing started have an empty history.
+func (s *Store) EventHistory(ctx context.Context, babyID, eventID int64) (_ []server.EventAuditEntry, err error) {
+	ctx, done := s.begin(ctx, "EventHistory")
+	defer func() { err = done(err) }()
+
+	const (
+		historyQuery = `
+			SELECT action, actor, changed_at
+			FROM event_audit
+			WHERE baby_id = $1 AND event_id = $2
+			ORDER BY changed_at ASC, id ASC
+		`
+		existsQuery = `SELECT EXISTS (SELECT 1 FROM events WHERE id = $1 AND baby_id = $2)`
+	)
+
+	rows, err := s.db.QueryContext(ctx, historyQuery, babyID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query event history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]server.EventAuditEntry, 0)
+	for rows.Next() {
+		var (
+			entry server.EventAuditEntry
+			actor sql.NullString
+		)
+		if err := rows.Scan(&entry.Action, &actor, &entry.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan event history: %w", err)
+		}
+		if actor.Valid {
+			entry.Actor = &actor.String
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate event history: %w", err)
+	}
+
+	if len(entries) == 0 {
+		var exists bool
+		if err := s.db.QueryRowContext(ctx, existsQuery, eventID, babyID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("query event: %w", err)
+		}
+		if !exists {
+			return nil, server.ErrNotFound
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *Store) EndSleep(ctx context.Context, babyID, eventID int64, endAt time.Time) (_ server.Event, err error) {
+	ctx, done := s.begin(ctx, "EndSleep")
+	defer func() { err = done(err) }()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return server.Event{}, fmt.Errorf("begin end sleep: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	const selectQuery = `
+		SELECT details
+		FROM events
+		WHERE id = $1 AND baby_id = $2 AND type = 'sleep'
+		FOR UPDATE
+	`
+
+	var raw []byte
+	if err := tx.QueryRowContext(ctx, selectQuery, eventID, babyID).Scan(&raw); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return server.Event{}, server.ErrNotFound
+		}
+		return server.Event{}, fmt.Errorf("select sleep event: %w", err)
+	}
+
+	var details struct {
+		StartAt time.Time  `json:"start_at"`
+		EndAt   *time.Time `json:"end_at"`
+	}
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return server.Event{}, fmt.Errorf("decode sleep details: %w", err)
+	}
+	if details.EndAt != nil {
+		return server.Event{}, server.ErrSleepEnded
+	}
+	if !endAt.After(details.StartAt) {
+		return server.Event{}, server.ErrSleepEndBeforeStart
+	}
+
+	const updateQuery = `
+		UPDATE events
+		SET details = details || jsonb_build_object('end_at', $2::text),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + eventColumns
+
+	event, err := scanEvent(tx.QueryRowContext(ctx, updateQuery, eventID, endAt.Format(time.RFC3339)))
+	if err != nil {
+		return server.Event{}, fmt.Errorf("update sleep event: %w", err)
+	}
+
+	if err := s.recordAudit(ctx, tx, auditUpdate, eventID); err != nil {
+		return server.Event{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return server.Event{}, fmt.Errorf("commit end sleep: %w", err)
+	}
+
+	return event, nil
+}
+
 func (s *Store) migrate(ctx context.Context) error {
+	// The name is validated, but quote it anyway.
+	createSchema := "CREATE SCHEMA IF NOT EXISTS " + pgx.Identifier{s.schema}.Sanitize()
+	if _, err := s.db.ExecContext(ctx, createSchema); err != nil {
+		return fmt.Errorf("create schema: %w", err)
+	}
+
 	const ddl = `
 		CREATE TABLE IF NOT EXISTS babies (
 			id BIGSERIAL PRIMARY KEY,
@@ -139,6 +1637,11 @@ func (s *Store) migrate(ctx context.Context) error {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		);
 
+		ALTER TABLE babies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+		ALTER TABLE babies ADD COLUMN IF NOT EXISTS color TEXT;
+		ALTER TABLE babies ADD COLUMN IF NOT EXISTS avatar_url TEXT;
+		ALTER TABLE babies ADD COLUMN IF NOT EXISTS timezone TEXT;
+
 		CREATE TABLE IF NOT EXISTS events (
 			id BIGSERIAL PRIMARY KEY,
 			baby_id BIGINT NOT NULL REFERENCES babies(id) ON DELETE CASCADE,
@@ -148,13 +1651,68 @@ func (s *Store) migrate(ctx context.Context) error {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		);
 
+		-- Backfill updated_at from created_at once, when the column is
+		-- first added. New rows get NOW() for both.
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1
+				FROM information_schema.columns
+				WHERE table_schema = current_schema()
+					AND table_name = 'events'
+					AND column_name = 'updated_at'
+			) THEN
+				ALTER TABLE events ADD COLUMN updated_at TIMESTAMPTZ;
+				UPDATE events SET updated_at = created_at;
+				ALTER TABLE events ALTER COLUMN updated_at SET DEFAULT NOW();
+				ALTER TABLE events ALTER COLUMN updated_at SET NOT NULL;
+			END IF;
+		END
+		$$;
+
+		ALTER TABLE events ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			baby_id BIGINT NOT NULL REFERENCES babies(id) ON DELETE CASCADE,
+			key TEXT NOT NULL,
+			event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (baby_id, key)
+		);
+
+		-- No foreign key on event_id, so the history of an event outlives
+		-- the event itself.
+		CREATE TABLE IF NOT EXISTS event_audit (
+			id BIGSERIAL PRIMARY KEY,
+			baby_id BIGINT NOT NULL REFERENCES babies(id) ON DELETE CASCADE,
+			event_id BIGINT NOT NULL,
+			action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+			actor TEXT,
+			changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
 		CREATE INDEX IF NOT EXISTS events_baby_id_idx ON events (baby_id);
+		CREATE INDEX IF NOT EXISTS events_baby_id_type_occurred_at_idx ON events (baby_id, type, occurred_at);
+		CREATE INDEX IF NOT EXISTS events_baby_id_occurred_at_id_idx ON events (baby_id, occurred_at, id);
+		CREATE INDEX IF NOT EXISTS events_tags_idx ON events USING GIN (tags);
+		CREATE INDEX IF NOT EXISTS event_audit_baby_id_event_id_idx ON event_audit (baby_id, event_id);
 	`
 
 	if _, err := s.db.ExecContext(ctx, ddl); err != nil {
 		return fmt.Errorf("migrate schema: %w", err)
 	}
 
+	if s.detailsCompression != "" {
+		// DDL takes no parameters; both values were checked in New.
+		compression := fmt.Sprintf(`
+			ALTER TABLE events ALTER COLUMN details SET COMPRESSION %s;
+			ALTER TABLE events SET (toast_tuple_target = %d);
+		`, s.detailsCompression, s.detailsCompressionMinBytes)
+		if _, err := s.db.ExecContext(ctx, compression); err != nil {
+			return fmt.Errorf("set details compression: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -177,3 +1735,119 @@ func (s *Store) seedBabies(ctx context.Context) error {
 
 	return nil
 }
+
+// babyColumns and eventColumns list the columns read by scanBaby and
+// scanEvent, in order.
+const (
+	babyColumns  = "id, name, created_at, deleted_at, color, avatar_url, timezone"
+	eventColumns = "id, baby_id, type, occurred_at, details, tags, created_at, updated_at"
+)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is what *sql.DB and *sql.Tx have in common, so a query helper
+// can run inside or outside a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// begin applies the query timeout to ctx. The returned done func must be
+// called with the method's error once it returns: it releases the
+// timeout, logs the duration at debug level and tags deadline errors
+// with server.ErrTimeout.
+func (s *Store) begin(ctx context.Context, method string) (context.Context, func(error) error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, s.queryTimeout)
+	return ctx, func(err error) error {
+		cancel()
+		slog.DebugContext(ctx, "postgres query", "method", method, "duration", time.Since(start))
+		if errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, server.ErrTimeout) {
+			return fmt.Errorf("%w: %w", server.ErrTimeout, err)
+		}
+		return err
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in value so it matches literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func nullFloatPtr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+func scanBaby(row rowScanner) (server.Baby, error) {
+	var (
+		baby      server.Baby
+		deletedAt sql.NullTime
+		color     sql.NullString
+		avatarURL sql.NullString
+		timezone  sql.NullString
+	)
+	err := row.Scan(&baby.ID, &baby.Name, &baby.CreatedAt, &deletedAt, &color, &avatarURL, &timezone)
+	baby.DeletedAt = nullTimePtr(deletedAt)
+	baby.Color = nullStringPtr(color)
+	baby.AvatarURL = nullStringPtr(avatarURL)
+	baby.Timezone = nullStringPtr(timezone)
+	return baby, err
+}
+
+func scanEvent(row rowScanner) (server.Event, error) {
+	var event server.Event
+	err := row.Scan(
+		&event.ID,
+		&event.BabyID,
+		&event.Type,
+		&event.OccurredAt,
+		&event.Details,
+		pgtype.NewMap().SQLScanner(&event.Tags),
+		&event.CreatedAt,
+		&event.UpdatedAt,
+	)
+	if event.Tags == nil {
+		event.Tags = []string{}
+	}
+	return event, err
+}
+
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+func nullTimePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time
+	return &t
+}
+
+// constraintError tags check and foreign key violations with the server's
+// sentinel errors so handlers can answer 400 or 404 instead of 500. Other
+// errors are returned unchanged.
+func constraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case "23514": // check_violation
+		return fmt.Errorf("%w: %w", server.ErrConstraintViolation, err)
+	case "23503": // foreign_key_violation
+		return fmt.Errorf("%w: %w", server.ErrNotFound, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const maxBatchEvents = 1000
+
+// batchItemError reports why one element of a batch was rejected.
+type batchItemError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// createEventsBatch validates every element before inserting any of them.
+// A single invalid element rejects the whole batch, and the store inserts
+// the rest in one transaction, so a batch is either fully applied or not
+// at all.
+func createEventsBatch(store BabyStore, cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		var reqs []createEventRequest
+		if err := decodeJSONBody(r.Body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(reqs) == 0 {
+			http.Error(w, "batch must contain at least one event", http.StatusBadRequest)
+			return
+		}
+		if len(reqs) > maxBatchEvents {
+			http.Error(w, fmt.Sprintf("batch must not exceed %d events", maxBatchEvents), http.StatusBadRequest)
+			return
+		}
+
+		inputs := make([]CreateEventInput, 0, len(reqs))
+		var failures []batchItemError
+		for i, req := range reqs {
+			input, err := buildCreateEventInput(babyID, req, cfg)
+			if err != nil {
+				failures = append(failures, batchItemError{Index: i, Error: err.Error()})
+				continue
+			}
+			inputs = append(inputs, input)
+		}
+		if len(failures) > 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"errors": failures})
+			return
+		}
+
+		events, err := store.CreateEvents(r.Context(), inputs)
+		if err != nil {
+			writeCreateEventError(w, r, "create events batch", err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, map[string]any{"data": events})
+	}
+}
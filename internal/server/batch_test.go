@@ -0,0 +1,165 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestCreateEventsBatch(t *testing.T) {
+	t.Parallel()
+
+	body := `[
+		{"type":"diaper","occurred_at":"2026-02-26T08:00:00Z"},
+		{"type":"nursing","occurred_at":"2026-02-26T09:00:00Z","side":"left","duration_minutes":15}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:batch", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		createEventsFunc: func(_ context.Context, inputs []server.CreateEventInput) ([]server.Event, error) {
+			events := make([]server.Event, len(inputs))
+			for i, input := range inputs {
+				if input.BabyID != 42 {
+					t.Fatalf("expected baby id 42, got %d", input.BabyID)
+				}
+				events[i] = server.Event{ID: int64(i + 1), BabyID: input.BabyID, Type: input.Type}
+			}
+			return events, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var got struct {
+		Data []server.Event `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got.Data) != 2 || got.Data[0].Type != "diaper" || got.Data[1].Type != "nursing" {
+		t.Fatalf("unexpected events %+v", got.Data)
+	}
+}
+
+func TestCreateEventsBatchValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	body := `[
+		{"type":"diaper","occurred_at":"2026-02-26T08:00:00Z"},
+		{"type":"nursing","occurred_at":"2026-02-26T09:00:00Z"},
+		{"type":"bath","occurred_at":"2026-02-26T10:00:00Z"}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:batch", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		createEventsFunc: func(context.Context, []server.CreateEventInput) ([]server.Event, error) {
+			t.Fatal("expected nothing to be inserted")
+			return nil, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	var got struct {
+		Errors []struct {
+			Index int    `json:"index"`
+			Error string `json:"error"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got.Errors) != 2 || got.Errors[0].Index != 1 || got.Errors[1].Index != 2 {
+		t.Fatalf("expected errors at indices 1 and 2, got %+v", got.Errors)
+	}
+	for _, item := range got.Errors {
+		if item.Error == "" {
+			t.Fatalf("expected an error message, got %+v", item)
+		}
+	}
+}
+
+func TestCreateEventsBatchDisabledType(t *testing.T) {
+	t.Parallel()
+
+	body := `[
+		{"type":"diaper","occurred_at":"2026-02-26T08:00:00Z"},
+		{"type":"sleep","start_at":"2026-02-26T09:00:00Z"}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:batch", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.NewRouterWithConfig(stubBabyStore{
+		createEventsFunc: func(context.Context, []server.CreateEventInput) ([]server.Event, error) {
+			t.Fatal("expected nothing to be inserted")
+			return nil, nil
+		},
+	}, server.Config{EventTypes: []string{"diaper", "nursing"}}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `{"index":1,"error":"event type sleep is disabled"}`) {
+		t.Fatalf("expected disabled type error at index 1, got %s", rr.Body.String())
+	}
+}
+
+func TestCreateEventsBatchTooLarge(t *testing.T) {
+	t.Parallel()
+
+	items := make([]string, 1001)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"type":"diaper","occurred_at":"2026-02-26T08:%02d:00Z"}`, i%60)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:batch", strings.NewReader("["+strings.Join(items, ",")+"]"))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestCreateEventsBatchEmpty(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:batch", strings.NewReader("[]"))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestCreateEventsBatchStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:batch", strings.NewReader(`[{"type":"diaper","occurred_at":"2026-02-26T08:00:00Z"}]`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		createEventsFunc: func(context.Context, []server.CreateEventInput) ([]server.Event, error) {
+			return nil, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
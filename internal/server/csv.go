@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// csvFlushEvery is how many rows are written between flushes, so a large
+// export reaches the client steadily without a flush per row.
+const csvFlushEvery = 100
+
+// streamWriteWindow is how long a streamed response may go between
+// flushes before its write deadline passes.
+const streamWriteWindow = 30 * time.Second
+
+// extendWriteDeadline moves the write deadline streamWriteWindow ahead.
+// The server's WriteTimeout is sized for ordinary responses, so streaming
+// handlers call it before writing and on every flush: a long export keeps
+// going while the client reads, and a stalled one is still cut off.
+// Writers without deadlines, like httptest's recorder, are left alone.
+func extendWriteDeadline(controller *http.ResponseController) error {
+	err := controller.SetWriteDeadline(time.Now().Add(streamWriteWindow))
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// csvDetailColumns are the Details keys exported as their own columns.
+// New columns go at the end so existing spreadsheets keep their layout.
+var csvDetailColumns = []string{"side", "duration_minutes", "start_at", "end_at", "weight_kg", "notes", "kind"}
+
+// csvColumns is the CSV header. Tags share one column, joined with ";".
+var csvColumns = slices.Concat([]string{"id", "type", "occurred_at"}, csvDetailColumns, []string{"tags"})
+
+// exportEventsCSV streams every event as CSV while it is read from the
+// store. Headers are only sent with the first row, so a store failure
+// before any output is still answered with a proper error status. The
+// length is unknown up front, so the checksum follows the body as a
+// trailer, which is left out when the stream breaks off.
+func exportEventsCSV(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		controller := http.NewResponseController(w)
+		if err := extendWriteDeadline(controller); err != nil {
+			logf(r.Context(), "extend write deadline for csv failed: %v", err)
+		}
+		sum := sha256.New()
+		out := csv.NewWriter(io.MultiWriter(w, sum))
+		started := false
+		rows := 0
+
+		start := func() error {
+			started = true
+			filename := fmt.Sprintf("baby-events-%d.csv", babyID)
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+			w.Header().Set("Trailer", contentSHA256Header)
+			w.WriteHeader(http.StatusOK)
+			return out.Write(csvColumns)
+		}
+
+		err = store.StreamEvents(r.Context(), babyID, func(event Event) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+
+			record, err := eventCSVRecord(event)
+			if err != nil {
+				return err
+			}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+
+			rows++
+			if rows%csvFlushEvery == 0 {
+				out.Flush()
+				if err := out.Error(); err != nil {
+					return err
+				}
+				if err := controller.Flush(); err != nil {
+					return err
+				}
+				if err := extendWriteDeadline(controller); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			if !started {
+				writeStoreError(w, r, "stream events for csv", err)
+				return
+			}
+			// The status line is gone; all that is left is to stop.
+			logf(r.Context(), "stream events for csv failed after %d rows: %v", rows, err)
+			return
+		}
+
+		if !started {
+			if err := start(); err != nil {
+				logf(r.Context(), "write csv header failed: %v", err)
+				return
+			}
+		}
+		out.Flush()
+		if err := out.Error(); err != nil {
+			logf(r.Context(), "write csv failed: %v", err)
+			return
+		}
+		w.Header().Set(contentSHA256Header, hex.EncodeToString(sum.Sum(nil)))
+	}
+}
+
+// eventCSVRecord flattens an event into the CSV columns. Numbers keep the
+// exact text stored in Details.
+func eventCSVRecord(event Event) ([]string, error) {
+	details := map[string]any{}
+	if len(event.Details) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(event.Details))
+		decoder.UseNumber()
+		if err := decoder.Decode(&details); err != nil {
+			return nil, fmt.Errorf("decode details of event %d: %w", event.ID, err)
+		}
+	}
+
+	record := []string{
+		fmt.Sprint(event.ID),
+		event.Type,
+		event.OccurredAt.UTC().Format(time.RFC3339),
+	}
+	for _, column := range csvDetailColumns {
+		value, ok := details[column]
+		if !ok || value == nil {
+			record = append(record, "")
+			continue
+		}
+		record = append(record, fmt.Sprint(value))
+	}
+	record = append(record, strings.Join(event.Tags, ";"))
+	return record, nil
+}
@@ -0,0 +1,205 @@
+package server_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestExportEventsCSV(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.csv", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(_ context.Context, babyID int64, fn func(server.Event) error) error {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			for _, event := range []server.Event{
+				{ID: 1, Type: "nursing", OccurredAt: mustParseRFC3339(t, "2026-02-26T08:00:00Z"), Details: json.RawMessage(`{"side":"left","duration_minutes":12}`)},
+				{ID: 2, Type: "diaper", OccurredAt: mustParseRFC3339(t, "2026-02-26T09:00:00Z"), Details: json.RawMessage(`{"kind":"wet","notes":"after \"feed\""}`), Tags: []string{"teething", "travel"}},
+				{ID: 3, Type: "weight", OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), Details: json.RawMessage(`{"weight_kg":3.45}`)},
+			} {
+				if err := fn(event); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Fatalf("expected CSV content type, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Disposition"); !strings.Contains(got, "baby-events-42.csv") {
+		t.Fatalf("expected attachment filename header, got %q", got)
+	}
+
+	if got := rr.Result().Trailer.Get("X-Content-SHA256"); got != sha256Hex(rr.Body.Bytes()) {
+		t.Fatalf("expected an X-Content-SHA256 trailer of %s, got %q", sha256Hex(rr.Body.Bytes()), got)
+	}
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	want := [][]string{
+		{"id", "type", "occurred_at", "side", "duration_minutes", "start_at", "end_at", "weight_kg", "notes", "kind", "tags"},
+		{"1", "nursing", "2026-02-26T08:00:00Z", "left", "12", "", "", "", "", "", ""},
+		{"2", "diaper", "2026-02-26T09:00:00Z", "", "", "", "", "", `after "feed"`, "wet", "teething;travel"},
+		{"3", "weight", "2026-02-26T10:00:00Z", "", "", "", "", "3.45", "", "", ""},
+	}
+	if fmt.Sprint(records) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, records)
+	}
+}
+
+func TestExportEventsCSVFlushesWhileStreaming(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.csv", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(_ context.Context, _ int64, fn func(server.Event) error) error {
+			occurredAt := mustParseRFC3339(t, "2026-02-26T08:00:00Z")
+			for i := 1; i <= 100; i++ {
+				if err := fn(server.Event{ID: int64(i), Type: "diaper", OccurredAt: occurredAt.Add(time.Duration(i) * time.Minute)}); err != nil {
+					return err
+				}
+			}
+			if !rr.Flushed || !strings.Contains(rr.Body.String(), "\n100,diaper,") {
+				t.Fatal("expected the first 100 rows to be flushed before the stream ends")
+			}
+			return nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+// deadlineRecorder records the read and write deadlines a handler sets,
+// which a plain ResponseRecorder does not support.
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	deadlines     []time.Time
+	readDeadlines []time.Time
+}
+
+func (r *deadlineRecorder) SetReadDeadline(deadline time.Time) error {
+	r.readDeadlines = append(r.readDeadlines, deadline)
+	return nil
+}
+
+func (r *deadlineRecorder) SetWriteDeadline(deadline time.Time) error {
+	r.deadlines = append(r.deadlines, deadline)
+	return nil
+}
+
+func TestExportEventsCSVExtendsWriteDeadline(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.csv", nil)
+	rr := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	started := time.Now()
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(_ context.Context, _ int64, fn func(server.Event) error) error {
+			for i := 1; i <= 250; i++ {
+				if err := fn(server.Event{ID: int64(i), Type: "diaper"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	// Once before streaming and again after each of the two flushes.
+	if len(rr.deadlines) != 3 {
+		t.Fatalf("expected 3 write deadlines, got %v", rr.deadlines)
+	}
+	for _, deadline := range rr.deadlines {
+		if !deadline.After(started) {
+			t.Fatalf("expected write deadlines in the future, got %v", rr.deadlines)
+		}
+	}
+}
+
+func TestExportEventsCSVEmpty(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.csv", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(context.Context, int64, func(server.Event) error) error {
+			return nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Body.String(); got != "id,type,occurred_at,side,duration_minutes,start_at,end_at,weight_kg,notes,kind,tags\n" {
+		t.Fatalf("expected only the header row, got %q", got)
+	}
+}
+
+func TestExportEventsCSVStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.csv", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(context.Context, int64, func(server.Event) error) error {
+			return errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestExportEventsCSVBrokenStreamHasNoChecksum(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.csv", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(_ context.Context, _ int64, fn func(server.Event) error) error {
+			if err := fn(server.Event{ID: 1, Type: "diaper", OccurredAt: mustParseRFC3339(t, "2026-02-26T09:00:00Z"), Details: json.RawMessage(`{}`)}); err != nil {
+				return err
+			}
+			return errors.New("connection reset")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected the status to be sent with the first row, got %d", rr.Code)
+	}
+	if got := rr.Result().Trailer.Get("X-Content-SHA256"); got != "" {
+		t.Fatalf("expected no checksum for a broken stream, got %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const demoDays = 3
+
+// seedDemo fills a baby with a few days of sample events. It is only
+// routed when Config.DemoMode is set.
+func seedDemo(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		_, err = store.GetBaby(r.Context(), babyID, BabyFilter{})
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "get baby for demo seed", err)
+			return
+		}
+
+		inputs, err := buildDemoEvents(babyID, time.Now().UTC())
+		if err != nil {
+			logf(r.Context(), "build demo events failed: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		events, err := store.CreateEvents(r.Context(), inputs)
+		if err != nil {
+			writeStoreError(w, r, "seed demo events", err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, map[string]any{"data": map[string]int{"seeded": len(events)}})
+	}
+}
+
+// buildDemoEvents generates demoDays days of events ending at now: a feed
+// every three hours alternating sides, a diaper after most feeds, a nap
+// between feeds and a daily weigh-in. The output is deterministic per baby.
+func buildDemoEvents(babyID int64, now time.Time) ([]CreateEventInput, error) {
+	rng := rand.New(rand.NewPCG(uint64(babyID), demoDays))
+	start := now.Truncate(24*time.Hour).AddDate(0, 0, -demoDays+1)
+
+	var inputs []CreateEventInput
+	add := func(req createEventRequest) error {
+		// Demo data covers every type, whatever clients may create.
+		input, err := buildCreateEventInput(babyID, req, Config{})
+		if err != nil {
+			return err
+		}
+		inputs = append(inputs, input)
+		return nil
+	}
+
+	sides := []string{"left", "right"}
+	for day := 0; day < demoDays; day++ {
+		dayStart := start.AddDate(0, 0, day)
+
+		weighedAt := dayStart.Add(9 * time.Hour)
+		if weighedAt.Before(now) {
+			weightKg := math.Round((3.5+0.03*float64(day)+rng.Float64()*0.02)*100) / 100
+			details, err := json.Marshal(map[string]any{"weight_kg": weightKg})
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, CreateEventInput{
+				BabyID:     babyID,
+				Type:       "weight",
+				OccurredAt: weighedAt,
+				Details:    details,
+			})
+		}
+
+		for feed := 0; feed < 8; feed++ {
+			fedAt := dayStart.Add(time.Duration(feed)*3*time.Hour + time.Duration(rng.IntN(30))*time.Minute)
+			if !fedAt.Before(now) {
+				break
+			}
+
+			duration := 10 + rng.IntN(16)
+			if err := add(createEventRequest{
+				Type:            "nursing",
+				OccurredAt:      fedAt.Format(time.RFC3339),
+				Side:            sides[(day*8+feed)%2],
+				DurationMinutes: duration,
+			}); err != nil {
+				return nil, err
+			}
+
+			if rng.IntN(3) > 0 {
+				changedAt := fedAt.Add(time.Duration(duration+5) * time.Minute)
+				if changedAt.Before(now) {
+					if err := add(createEventRequest{
+						Type:       "diaper",
+						OccurredAt: changedAt.Format(time.RFC3339),
+					}); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			sleepStart := fedAt.Add(time.Duration(duration+15) * time.Minute)
+			sleepEnd := sleepStart.Add(time.Duration(90+rng.IntN(45)) * time.Minute)
+			if sleepEnd.Before(now) {
+				if err := add(createEventRequest{
+					Type:    "sleep",
+					StartAt: sleepStart.Format(time.RFC3339),
+					EndAt:   sleepEnd.Format(time.RFC3339),
+				}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return inputs, nil
+}
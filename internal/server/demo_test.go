@@ -0,0 +1,90 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestSeedDemoDisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/seed-demo", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestSeedDemo(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/seed-demo", nil)
+	rr := httptest.NewRecorder()
+
+	var seeded []server.CreateEventInput
+	server.NewRouterWithConfig(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		createEventsFunc: func(_ context.Context, inputs []server.CreateEventInput) ([]server.Event, error) {
+			seeded = inputs
+			events := make([]server.Event, len(inputs))
+			for i, input := range inputs {
+				events[i] = server.Event{ID: int64(i + 1), BabyID: input.BabyID, Type: input.Type}
+			}
+			return events, nil
+		},
+	}, server.Config{DemoMode: true}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	types := map[string]int{}
+	for _, input := range seeded {
+		if input.BabyID != 42 {
+			t.Fatalf("expected baby id 42, got %d", input.BabyID)
+		}
+		types[input.Type]++
+	}
+	for _, eventType := range []string{"nursing", "diaper", "sleep", "weight"} {
+		if types[eventType] == 0 {
+			t.Fatalf("expected seeded %s events, got %v", eventType, types)
+		}
+	}
+
+	var got struct {
+		Data struct {
+			Seeded int `json:"seeded"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Data.Seeded != len(seeded) {
+		t.Fatalf("expected seeded count %d, got %d", len(seeded), got.Data.Seeded)
+	}
+}
+
+func TestSeedDemoBabyNotFound(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/77/seed-demo", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouterWithConfig(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+	}, server.Config{DemoMode: true}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
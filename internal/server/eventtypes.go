@@ -0,0 +1,144 @@
+package server
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// eventTypeSpec describes the fields one creatable event type reads from
+// a createEventRequest. buildCreateEventInput checks Required against it,
+// and the OpenAPI document and GET /v1/event-types are both built from
+// it, so neither can drift from the validator.
+type eventTypeSpec struct {
+	Type       string                    `json:"type"`
+	Required   []string                  `json:"required"`
+	Optional   []string                  `json:"optional"`
+	Properties map[string]*openAPISchema `json:"properties"`
+}
+
+var (
+	timestampSchema = &openAPISchema{Type: "string", Format: "date-time"}
+	tagsSchema      = arrayOf(&openAPISchema{Type: "string", Description: "Up to 10 tags of 1 to 32 characters, stored in lower case."})
+)
+
+// eventTypeSpecs lists creatableEventTypes in the same order.
+var eventTypeSpecs = []eventTypeSpec{
+	{
+		Type:     "diaper",
+		Required: []string{"occurred_at"},
+		Optional: []string{"kind", "notes", "tags"},
+		Properties: map[string]*openAPISchema{
+			"occurred_at": timestampSchema,
+			"kind":        {Type: "string", Enum: diaperKinds},
+			"notes":       {Type: "string"},
+			"tags":        tagsSchema,
+		},
+	},
+	{
+		Type:     "nursing",
+		Required: []string{"occurred_at", "side", "duration_minutes"},
+		Optional: []string{"tags"},
+		Properties: map[string]*openAPISchema{
+			"occurred_at":      timestampSchema,
+			"side":             {Type: "string", Enum: []string{"left", "right"}},
+			"duration_minutes": {Type: "integer"},
+			"tags":             tagsSchema,
+		},
+	},
+	{
+		Type:     "sleep",
+		Required: []string{"start_at"},
+		Optional: []string{"end_at", "tags"},
+		Properties: map[string]*openAPISchema{
+			"start_at": timestampSchema,
+			"end_at":   {Type: "string", Format: "date-time", Description: "Omit for a sleep in progress."},
+			"tags":     tagsSchema,
+		},
+	},
+}
+
+// lookupEventTypeSpec returns the spec of a creatable event type.
+func lookupEventTypeSpec(eventType string) (eventTypeSpec, bool) {
+	i := slices.IndexFunc(eventTypeSpecs, func(spec eventTypeSpec) bool { return spec.Type == eventType })
+	if i < 0 {
+		return eventTypeSpec{}, false
+	}
+	return eventTypeSpecs[i], true
+}
+
+// eventTypeSpec returns the spec of a creatable event type as this
+// deployment enforces it: the fields cfg.RequiredFields lists for the
+// type move from Optional to Required.
+func (cfg Config) eventTypeSpec(eventType string) (eventTypeSpec, bool) {
+	spec, ok := lookupEventTypeSpec(eventType)
+	extra := cfg.RequiredFields[eventType]
+	if !ok || len(extra) == 0 {
+		return spec, ok
+	}
+
+	spec.Required = slices.Clone(spec.Required)
+	spec.Optional = slices.DeleteFunc(slices.Clone(spec.Optional), func(field string) bool {
+		return slices.Contains(extra, field)
+	})
+	for _, field := range extra {
+		if !slices.Contains(spec.Required, field) {
+			spec.Required = append(spec.Required, field)
+		}
+	}
+	return spec, true
+}
+
+// hasField reports whether req sets the named field of eventTypeSpec.
+func (req createEventRequest) hasField(name string) bool {
+	switch name {
+	case "occurred_at":
+		return strings.TrimSpace(req.OccurredAt) != ""
+	case "start_at":
+		return strings.TrimSpace(req.StartAt) != ""
+	case "end_at":
+		return strings.TrimSpace(req.EndAt) != ""
+	case "side":
+		return strings.TrimSpace(req.Side) != ""
+	case "kind":
+		return strings.TrimSpace(req.Kind) != ""
+	case "notes":
+		return strings.TrimSpace(req.Notes) != ""
+	case "duration_minutes":
+		return req.DurationMinutes != 0
+	case "tags":
+		return len(req.Tags) > 0
+	default:
+		return false
+	}
+}
+
+// createEventSchema is the OpenAPI schema of a createEventRequest for
+// spec's type.
+func (spec eventTypeSpec) createEventSchema() *openAPISchema {
+	properties := maps.Clone(spec.Properties)
+	properties["type"] = &openAPISchema{Type: "string", Enum: []string{spec.Type}}
+	return &openAPISchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   append([]string{"type"}, spec.Required...),
+	}
+}
+
+// listEventTypes lists the event types clients may create with the fields
+// each one takes, so forms can be generated rather than hard-coded. Types
+// disabled through Config.EventTypes are left out, and fields made
+// mandatory through Config.RequiredFields are listed as required.
+func listEventTypes(cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data := make([]eventTypeSpec, 0, len(eventTypeSpecs))
+		for _, base := range eventTypeSpecs {
+			if cfg.EventTypes == nil || slices.Contains(cfg.EventTypes, base.Type) {
+				spec, _ := cfg.eventTypeSpec(base.Type)
+				data = append(data, spec)
+			}
+		}
+		writeList(w, r, data)
+	}
+}
@@ -0,0 +1,137 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"baby-tracker-server/internal/server"
+)
+
+type eventTypeSpec struct {
+	Type       string                     `json:"type"`
+	Required   []string                   `json:"required"`
+	Optional   []string                   `json:"optional"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+func getEventTypes(t *testing.T, cfg server.Config) []eventTypeSpec {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/event-types", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouterWithConfig(stubBabyStore{}, cfg).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var got struct {
+		Data []eventTypeSpec `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	return got.Data
+}
+
+func TestListEventTypes(t *testing.T) {
+	t.Parallel()
+
+	specs := getEventTypes(t, server.Config{})
+
+	var types []string
+	for _, spec := range specs {
+		types = append(types, spec.Type)
+		for _, field := range slices.Concat(spec.Required, spec.Optional) {
+			if _, ok := spec.Properties[field]; !ok {
+				t.Fatalf("expected a schema for %s field %s", spec.Type, field)
+			}
+		}
+	}
+	if !slices.Equal(types, []string{"diaper", "nursing", "sleep"}) {
+		t.Fatalf("unexpected event types %v", types)
+	}
+	if nursing := specs[1]; !slices.Equal(nursing.Required, []string{"occurred_at", "side", "duration_minutes"}) {
+		t.Fatalf("unexpected nursing required fields %v", nursing.Required)
+	}
+}
+
+func TestListEventTypesSkipsDisabled(t *testing.T) {
+	t.Parallel()
+
+	specs := getEventTypes(t, server.Config{EventTypes: []string{"sleep"}})
+
+	if len(specs) != 1 || specs[0].Type != "sleep" {
+		t.Fatalf("expected only sleep, got %+v", specs)
+	}
+}
+
+func TestListEventTypesRequiredFields(t *testing.T) {
+	t.Parallel()
+
+	specs := getEventTypes(t, server.Config{RequiredFields: map[string][]string{"diaper": {"kind", "notes"}}})
+
+	diaper := specs[0]
+	if !slices.Equal(diaper.Required, []string{"occurred_at", "kind", "notes"}) || !slices.Equal(diaper.Optional, []string{"tags"}) {
+		t.Fatalf("expected kind and notes to be required, got %+v", diaper)
+	}
+	if nursing := specs[1]; !slices.Equal(nursing.Optional, []string{"tags"}) {
+		t.Fatalf("expected other types unchanged, got %+v", nursing)
+	}
+}
+
+// Every field an event type lists as required must be rejected when
+// missing, so the listing cannot claim more or less than the validator,
+// also when a deployment requires more fields.
+func TestEventTypesMatchValidation(t *testing.T) {
+	t.Parallel()
+
+	valid := map[string]map[string]any{
+		"diaper":  {"occurred_at": "2026-02-26T10:00:00Z", "kind": "wet", "notes": "after feed"},
+		"nursing": {"occurred_at": "2026-02-26T10:00:00Z", "side": "left", "duration_minutes": 12},
+		"sleep":   {"start_at": "2026-02-26T10:00:00Z", "end_at": "2026-02-26T11:00:00Z"},
+	}
+
+	for _, cfg := range []server.Config{
+		{},
+		{RequiredFields: map[string][]string{"diaper": {"kind", "notes"}, "sleep": {"end_at"}}},
+	} {
+		for _, spec := range getEventTypes(t, cfg) {
+			checkRequiredFields(t, cfg, spec, valid)
+		}
+	}
+}
+
+func checkRequiredFields(t *testing.T, cfg server.Config, spec eventTypeSpec, valid map[string]map[string]any) {
+	t.Helper()
+
+	fields, ok := valid[spec.Type]
+	if !ok {
+		t.Fatalf("no valid %s event to check against", spec.Type)
+	}
+	for _, missing := range spec.Required {
+		body := map[string]any{"type": spec.Type}
+		for name, value := range fields {
+			if name != missing {
+				body[name] = value
+			}
+		}
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to encode body: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(string(payload)))
+		rr := httptest.NewRecorder()
+
+		server.NewRouterWithConfig(stubBabyStore{}, cfg).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), missing+" is required") {
+			t.Fatalf("expected 400 for %s without %s, got %d: %s", spec.Type, missing, rr.Code, rr.Body.String())
+		}
+	}
+}
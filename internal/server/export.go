@@ -0,0 +1,123 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// exportVersion is bumped whenever the export document changes shape, so
+// an importer can tell which layout it is reading.
+const exportVersion = 1
+
+// contentSHA256Header carries the hex SHA-256 of a download's body, as
+// generated and before any gzip encoding, so clients can verify what they
+// saved.
+const contentSHA256Header = "X-Content-SHA256"
+
+// writeDownload answers 200 with body, which is built in full first so
+// download managers get its Content-Length and checksum up front.
+func writeDownload(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Set(contentSHA256Header, hex.EncodeToString(sum[:]))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		logf(r.Context(), "write download failed: %v", err)
+	}
+}
+
+// BabyExport is a full backup of one baby. Server-assigned ids are left
+// out of events so the document can be imported into another account.
+// Weights are exported on their own and not repeated under events.
+type BabyExport struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Baby       Baby          `json:"baby"`
+	Events     []ExportEvent `json:"events"`
+	Weights    []WeightEntry `json:"weights"`
+}
+
+// ExportEvent is an Event without its ids.
+type ExportEvent struct {
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Details    json.RawMessage `json:"details"`
+	Tags       []string        `json:"tags"`
+}
+
+func exportBaby(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		baby, err := store.GetBaby(r.Context(), babyID, BabyFilter{})
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "get baby for export", err)
+			return
+		}
+
+		types := slices.DeleteFunc(slices.Clone(eventTypes), func(eventType string) bool {
+			return eventType == "weight"
+		})
+		events, err := store.ListEvents(r.Context(), babyID, EventFilter{Types: types})
+		if err != nil {
+			writeStoreError(w, r, "list events for export", err)
+			return
+		}
+
+		weights, err := store.ListWeightEntries(r.Context(), babyID)
+		if err != nil {
+			writeStoreError(w, r, "list weight entries for export", err)
+			return
+		}
+
+		export := BabyExport{
+			Version:    exportVersion,
+			ExportedAt: time.Now().UTC(),
+			Baby:       baby,
+			Events:     make([]ExportEvent, 0, len(events)),
+			Weights:    weights,
+		}
+		if export.Weights == nil {
+			export.Weights = []WeightEntry{}
+		}
+		for _, event := range events {
+			tags := event.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			export.Events = append(export.Events, ExportEvent{
+				Type:       event.Type,
+				OccurredAt: event.OccurredAt,
+				Details:    event.Details,
+				Tags:       tags,
+			})
+		}
+
+		body, err := json.Marshal(export)
+		if err != nil {
+			logf(r.Context(), "encode export failed: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		filename := fmt.Sprintf("baby-export-%d.json", babyID)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		writeDownload(w, r, "application/json", append(body, '\n'))
+	}
+}
@@ -0,0 +1,125 @@
+package server_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestExportBaby(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/export.json", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		listEventsFunc: func(_ context.Context, babyID int64, filter server.EventFilter) ([]server.Event, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			if slices.Contains(filter.Types, "weight") || len(filter.Types) == 0 {
+				t.Fatalf("expected weight events to be filtered out, got types %v", filter.Types)
+			}
+			return []server.Event{
+				{
+					ID:         7,
+					BabyID:     42,
+					Type:       "diaper",
+					OccurredAt: mustParseRFC3339(t, "2026-02-26T08:00:00Z"),
+					Details:    json.RawMessage(`{"notes":"wet"}`),
+					Tags:       []string{"daycare", "night"},
+				},
+				{
+					ID:         8,
+					BabyID:     42,
+					Type:       "diaper",
+					OccurredAt: mustParseRFC3339(t, "2026-02-26T09:00:00Z"),
+					Details:    json.RawMessage(`{}`),
+				},
+			}, nil
+		},
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return []server.WeightEntry{
+				{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: 3.44},
+			}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("Content-Disposition"); !strings.Contains(got, "attachment;") || !strings.Contains(got, "baby-export-42.json") {
+		t.Fatalf("expected attachment filename header, got %q", got)
+	}
+
+	checkDownloadHeaders(t, rr)
+
+	var got server.BabyExport
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Version != 1 {
+		t.Fatalf("expected version 1, got %d", got.Version)
+	}
+	if got.Baby.ID != 42 || got.Baby.Name != "Mila" {
+		t.Fatalf("unexpected baby %+v", got.Baby)
+	}
+	if len(got.Events) != 2 || got.Events[0].Type != "diaper" || string(got.Events[0].Details) != `{"notes":"wet"}` {
+		t.Fatalf("unexpected events %+v", got.Events)
+	}
+	if !slices.Equal(got.Events[0].Tags, []string{"daycare", "night"}) {
+		t.Fatalf("expected the event's tags to be exported, got %v", got.Events[0].Tags)
+	}
+	if !strings.Contains(rr.Body.String(), `"details":{},"tags":[]`) {
+		t.Fatalf("expected an untagged event to export an empty tags list, got %s", rr.Body.String())
+	}
+	if len(got.Weights) != 1 || got.Weights[0].WeightKg != 3.44 {
+		t.Fatalf("unexpected weights %+v", got.Weights)
+	}
+	if strings.Contains(rr.Body.String(), `"id":7`) {
+		t.Fatalf("expected event ids to be left out, got %s", rr.Body.String())
+	}
+}
+
+// checkDownloadHeaders checks the length and checksum headers of a
+// download against its body.
+func checkDownloadHeaders(t *testing.T, rr *httptest.ResponseRecorder) {
+	t.Helper()
+
+	if got := rr.Header().Get("Content-Length"); got != strconv.Itoa(rr.Body.Len()) {
+		t.Fatalf("expected Content-Length %d, got %q", rr.Body.Len(), got)
+	}
+	if got := rr.Header().Get("X-Content-SHA256"); got != sha256Hex(rr.Body.Bytes()) {
+		t.Fatalf("expected X-Content-SHA256 %s, got %q", sha256Hex(rr.Body.Bytes()), got)
+	}
+}
+
+func sha256Hex(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestExportBabyNotFound(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/77/export.json", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// importFieldError reports why one part of an import document was
+// rejected, e.g. field "events[3]".
+type importFieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// importResult is the outcome of an import.
+type importResult struct {
+	BabyID  int64 `json:"baby_id"`
+	Events  int   `json:"events"`
+	Weights int   `json:"weights"`
+}
+
+// importBaby restores a BabyExport as a new baby. Like a batch, the whole
+// document is validated first and every error reported at once; the store
+// then writes the baby and all of its events in one transaction, so a
+// failed import leaves nothing behind. Ids in the document are ignored.
+func importBaby(store BabyStore, cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var doc BabyExport
+		if err := decodeJSONBody(r.Body, &doc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if doc.Version != exportVersion {
+			http.Error(w, fmt.Sprintf("version must be %d", exportVersion), http.StatusBadRequest)
+			return
+		}
+
+		newBaby, inputs, failures := buildImportInputs(doc, cfg)
+		if len(failures) > 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"errors": failures})
+			return
+		}
+
+		baby, err := store.ImportBaby(r.Context(), newBaby, inputs)
+		if err != nil {
+			writeCreateEventError(w, r, "import baby", err)
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("%s/v1/babies/%d", cfg.PathPrefix, baby.ID))
+		writeJSON(w, http.StatusCreated, map[string]any{"data": importResult{
+			BabyID:  baby.ID,
+			Events:  len(doc.Events),
+			Weights: len(doc.Weights),
+		}})
+	}
+}
+
+// buildImportInputs validates doc and turns its events and weights into
+// CreateEventInputs, which carry no baby id yet. Events go through the
+// same checks as POST /v1/babies/{id}/events, reading their fields from
+// the exported details.
+func buildImportInputs(doc BabyExport, cfg Config) (NewBabyInput, []CreateEventInput, []importFieldError) {
+	var failures []importFieldError
+
+	name, err := normalizeBabyName(doc.Baby.Name)
+	if err != nil {
+		failures = append(failures, importFieldError{Field: "baby.name", Error: err.Error()})
+	}
+	color, err := normalizeOptionalBabyField(doc.Baby.Color, normalizeBabyColor)
+	if err != nil {
+		failures = append(failures, importFieldError{Field: "baby.color", Error: err.Error()})
+	}
+	avatarURL, err := normalizeOptionalBabyField(doc.Baby.AvatarURL, normalizeAvatarURL)
+	if err != nil {
+		failures = append(failures, importFieldError{Field: "baby.avatar_url", Error: err.Error()})
+	}
+	timezone, err := normalizeOptionalBabyField(doc.Baby.Timezone, normalizeBabyTimezone)
+	if err != nil {
+		failures = append(failures, importFieldError{Field: "baby.timezone", Error: err.Error()})
+	}
+	newBaby := NewBabyInput{Name: name, Color: nonEmpty(color), AvatarURL: nonEmpty(avatarURL), Timezone: nonEmpty(timezone)}
+
+	inputs := make([]CreateEventInput, 0, len(doc.Events)+len(doc.Weights))
+	for i, event := range doc.Events {
+		var req createEventRequest
+		if len(event.Details) > 0 {
+			if err := json.Unmarshal(event.Details, &req); err != nil {
+				failures = append(failures, importFieldError{Field: fmt.Sprintf("events[%d]", i), Error: "invalid details"})
+				continue
+			}
+		}
+		req.Type = event.Type
+		req.OccurredAt = event.OccurredAt.Format(time.RFC3339)
+		req.Tags = event.Tags
+
+		input, err := buildCreateEventInput(0, req, cfg)
+		if err != nil {
+			failures = append(failures, importFieldError{Field: fmt.Sprintf("events[%d]", i), Error: err.Error()})
+			continue
+		}
+		inputs = append(inputs, input)
+	}
+
+	for i, weight := range doc.Weights {
+		if weight.OccurredAt.IsZero() {
+			failures = append(failures, importFieldError{Field: fmt.Sprintf("weights[%d]", i), Error: "occurred_at is required"})
+			continue
+		}
+		if err := cfg.checkWeight(weight.WeightKg); err != nil {
+			failures = append(failures, importFieldError{Field: fmt.Sprintf("weights[%d]", i), Error: err.Error()})
+			continue
+		}
+		details, err := json.Marshal(map[string]any{"weight_kg": weight.WeightKg})
+		if err != nil {
+			failures = append(failures, importFieldError{Field: fmt.Sprintf("weights[%d]", i), Error: "failed to encode details"})
+			continue
+		}
+		inputs = append(inputs, CreateEventInput{
+			Type:       "weight",
+			OccurredAt: weight.OccurredAt,
+			Details:    details,
+		})
+	}
+
+	return newBaby, inputs, failures
+}
+
+// nonEmpty returns value, or nil when it points to "".
+func nonEmpty(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
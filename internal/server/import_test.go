@@ -0,0 +1,111 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestImportBaby(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/import", strings.NewReader(`{
+		"version": 1,
+		"baby": {"id": 42, "name": "  Mila  ", "color": "#F80", "avatar_url": ""},
+		"events": [
+			{"type": "diaper", "occurred_at": "2026-02-26T08:00:00Z", "details": {"kind": "wet"}, "tags": [" Daycare ", "night"]},
+			{"type": "sleep", "occurred_at": "2026-02-26T09:00:00Z", "details": {"start_at": "2026-02-26T09:00:00Z", "end_at": "2026-02-26T10:30:00Z"}}
+		],
+		"weights": [{"id": 9, "occurred_at": "2026-02-26T11:00:00Z", "weight_kg": 3.44}]
+	}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		importBabyFunc: func(_ context.Context, baby server.NewBabyInput, inputs []server.CreateEventInput) (server.Baby, error) {
+			if baby.Name != "Mila" || baby.Color == nil || *baby.Color != "#ff8800" || baby.AvatarURL != nil {
+				t.Fatalf("unexpected baby %+v", baby)
+			}
+			if len(inputs) != 3 {
+				t.Fatalf("expected 3 inputs, got %+v", inputs)
+			}
+			if inputs[0].Type != "diaper" || string(inputs[0].Details) != `{"kind":"wet"}` || strings.Join(inputs[0].Tags, ",") != "daycare,night" {
+				t.Fatalf("unexpected diaper input %+v", inputs[0])
+			}
+			if len(inputs[1].Tags) != 0 {
+				t.Fatalf("expected no tags on the sleep input, got %v", inputs[1].Tags)
+			}
+			if inputs[1].Type != "sleep" || string(inputs[1].Details) != `{"end_at":"2026-02-26T10:30:00Z","start_at":"2026-02-26T09:00:00Z"}` {
+				t.Fatalf("unexpected sleep input %+v", inputs[1])
+			}
+			if inputs[2].Type != "weight" || string(inputs[2].Details) != `{"weight_kg":3.44}` {
+				t.Fatalf("unexpected weight input %+v", inputs[2])
+			}
+			return server.Baby{ID: 7, Name: baby.Name}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Location"); got != "/v1/babies/7" {
+		t.Fatalf("expected Location /v1/babies/7, got %q", got)
+	}
+	if got := strings.TrimSpace(rr.Body.String()); got != `{"data":{"baby_id":7,"events":2,"weights":1}}` {
+		t.Fatalf("unexpected body %s", got)
+	}
+}
+
+func TestImportBabyValidatesEverythingFirst(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/import", strings.NewReader(`{
+		"version": 1,
+		"baby": {"name": " ", "color": "orange"},
+		"events": [
+			{"type": "diaper", "occurred_at": "2026-02-26T08:00:00Z", "details": {}},
+			{"type": "nursing", "occurred_at": "2026-02-26T09:00:00Z", "details": {"side": "middle", "duration_minutes": 10}}
+		],
+		"weights": [{"occurred_at": "2026-02-26T11:00:00Z", "weight_kg": 0}]
+	}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	var got struct {
+		Errors []struct {
+			Field string `json:"field"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	fields := make([]string, 0, len(got.Errors))
+	for _, failure := range got.Errors {
+		fields = append(fields, failure.Field)
+	}
+	if strings.Join(fields, ",") != "baby.name,baby.color,events[1],weights[0]" {
+		t.Fatalf("expected errors for baby.name, baby.color, events[1] and weights[0], got %s", rr.Body.String())
+	}
+}
+
+func TestImportBabyUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/import", strings.NewReader(`{"version": 2, "baby": {"name": "Mila"}}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
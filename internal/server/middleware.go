@@ -0,0 +1,402 @@
+package server
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied ids so they stay readable in
+// logs.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// withRequestID tags each request with an id taken from X-Request-ID, or
+// generated when the header is missing or unusable. The id is echoed in
+// the response and prefixed to the request's log lines by logf.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFromContext returns the request id set by the router, or ""
+// outside a request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// logf logs an error with the request id of ctx, when there is one.
+func logf(ctx context.Context, format string, args ...any) {
+	logWithRequestID(RequestIDFromContext(ctx), format, args...)
+}
+
+func logWithRequestID(id, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if id == "" {
+		slog.Error(msg)
+		return
+	}
+	slog.Error(msg, "request_id", id)
+}
+
+// validRequestID accepts printable ASCII without spaces, so a client
+// cannot break log lines with the id it sends.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+const responseTimeHeader = "X-Response-Time-ms"
+
+// logRequests times each request and logs it once the handler returns.
+// The time to the response headers is also sent as X-Response-Time-ms,
+// and the log line carries that same value, so a slow response seen in
+// the browser can be matched to the server log by request id. Streaming
+// responses report the time to their first byte.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &timingResponseWriter{ResponseWriter: w, start: time.Now()}
+		next.ServeHTTP(tw, r)
+		if !tw.wroteHeader {
+			tw.WriteHeader(http.StatusOK)
+		}
+
+		slog.InfoContext(r.Context(), "request",
+			"request_id", RequestIDFromContext(r.Context()),
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", tw.status,
+			"response_time_ms", tw.elapsed,
+		)
+	})
+}
+
+// timingResponseWriter sets X-Response-Time-ms when the headers are
+// written.
+type timingResponseWriter struct {
+	http.ResponseWriter
+	start       time.Time
+	wroteHeader bool
+	status      int
+	elapsed     string
+}
+
+func (w *timingResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+		ms := float64(time.Since(w.start).Microseconds()) / 1000
+		w.elapsed = strconv.FormatFloat(ms, 'f', 3, 64)
+		w.Header().Set(responseTimeHeader, w.elapsed)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timingResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *timingResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *timingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+const actorHeader = "X-Actor"
+
+type actorKey struct{}
+
+// withActor records who is making the request, from the X-Actor header,
+// so stores can attribute changes. It is whatever the client claims, not
+// an authenticated identity. Values that would not pass as a request id
+// are ignored.
+func withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := r.Header.Get(actorHeader)
+		if !validRequestID(actor) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithActor(r.Context(), actor)))
+	})
+}
+
+// ContextWithActor returns a copy of ctx that attributes changes to actor.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by the router, or "" when the
+// request named none.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// requireUserAgent rejects mutating requests without a User-Agent header.
+// It is a cheap filter against naive bots, not an access control.
+func requireUserAgent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isMutating(r.Method) && strings.TrimSpace(r.UserAgent()) == "" {
+			slog.WarnContext(r.Context(), "rejected request without User-Agent",
+				"request_id", RequestIDFromContext(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+				"remote_addr", r.RemoteAddr,
+			)
+			http.Error(w, "User-Agent header required", http.StatusBadRequest)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// reportCSP allows nothing beyond the page itself: the HTML report has no
+// scripts, styles or images, and must not be framed.
+const reportCSP = "default-src 'none'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+// securityHeaders sets X-Content-Type-Options and X-Frame-Options on every
+// response, and a Content-Security-Policy on HTML ones. JSON, CSV and PDF
+// bodies are not rendered as pages, so a CSP would be noise there.
+func securityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("X-Frame-Options", "DENY")
+		next.ServeHTTP(&cspResponseWriter{ResponseWriter: w}, r)
+	})
+}
+
+// cspResponseWriter adds the CSP once the handler has settled on its
+// Content-Type, when the headers are written.
+type cspResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *cspResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			w.Header().Set("Content-Security-Policy", reportCSP)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cspResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *cspResponseWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *cspResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// gzipMinBytes is the smallest body worth compressing. Below it the gzip
+// header and footer eat most of the savings.
+const gzipMinBytes = 1024
+
+// gzipResponses compresses response bodies of at least gzipMinBytes for
+// clients that accept gzip. PDFs and bodies that already carry a
+// Content-Encoding are sent as is.
+func gzipResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, ctx: r.Context(), status: http.StatusOK}
+		defer gw.finish()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip. An
+// explicit gzip entry takes precedence over a "*" wildcard.
+func acceptsGzip(header string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		allowed := true
+		if name, value, ok := strings.Cut(params, "="); ok && strings.EqualFold(strings.TrimSpace(name), "q") {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			allowed = err == nil && q > 0
+		}
+		if coding == "gzip" {
+			return allowed
+		}
+		wildcard = allowed
+	}
+	return wildcard
+}
+
+// gzipResponseWriter holds the body back until it reaches gzipMinBytes,
+// the handler flushes, or the handler returns, and only then decides
+// whether to compress. Headers are written at that point.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	status      int
+	wroteHeader bool
+	started     bool
+	buf         []byte
+	gz          *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader || w.started {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < gzipMinBytes {
+			return len(p), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush commits to compressing, since a handler that flushes is streaming
+// and its body is likely to be large.
+func (w *gzipResponseWriter) Flush() {
+	if !w.started {
+		if err := w.start(true); err != nil {
+			return
+		}
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the headers and any buffered body.
+func (w *gzipResponseWriter) start(compress bool) error {
+	w.started = true
+
+	header := w.Header()
+	if compress && w.compressible() {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	if w.gz != nil {
+		_, err := w.gz.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *gzipResponseWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if strings.HasPrefix(header.Get("Content-Type"), "application/pdf") {
+		return false
+	}
+	return w.status != http.StatusNoContent && w.status != http.StatusNotModified
+}
+
+// finish sends a body that never reached gzipMinBytes uncompressed and
+// closes the gzip stream otherwise.
+func (w *gzipResponseWriter) finish() {
+	if !w.started {
+		if err := w.start(false); err != nil {
+			logf(w.ctx, "write response failed: %v", err)
+		}
+	}
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			logf(w.ctx, "close gzip response failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package server_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestRequireUserAgentRejectsMutatingRequestWithoutUserAgent(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{}`))
+	req.Header.Del("User-Agent")
+	rr := httptest.NewRecorder()
+
+	server.NewRouterWithConfig(stubBabyStore{
+		createEventFunc: func(_ context.Context, _ server.CreateEventInput) (server.Event, error) {
+			t.Fatal("CreateEvent should not be called without a User-Agent")
+			return server.Event{}, nil
+		},
+	}, server.Config{RequireUserAgent: true}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestRequireUserAgentAllowsReadsWithoutUserAgent(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Del("User-Agent")
+	rr := httptest.NewRecorder()
+
+	server.NewRouterWithConfig(stubBabyStore{}, server.Config{RequireUserAgent: true}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestRequireUserAgentDisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{"type":"unknown"}`))
+	req.Header.Del("User-Agent")
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "type must be") {
+		t.Fatalf("expected request to reach validation, got %d %q", rr.Code, rr.Body.String())
+	}
+}
+
+func manyEvents(n int) []server.Event {
+	events := make([]server.Event, n)
+	for i := range events {
+		events[i] = server.Event{ID: int64(i + 1), BabyID: 42, Type: "diaper", Details: json.RawMessage(`{}`)}
+	}
+	return events
+}
+
+func TestGzipCompressesLargeResponses(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listEventsFunc: func(context.Context, int64, server.EventFilter) ([]server.Event, error) {
+			return manyEvents(100), nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip Content-Encoding, got %q", got)
+	}
+	if got := rr.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Fatalf("expected Vary Accept-Encoding, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected Content-Type application/json, got %q", got)
+	}
+
+	zr, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatalf("failed to open gzip body: %v", err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("failed to read gzip body: %v", err)
+	}
+
+	var got struct {
+		Data []server.Event `json:"data"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got.Data) != 100 {
+		t.Fatalf("expected 100 events, got %d", len(got.Data))
+	}
+}
+
+func TestGzipSkipsSmallResponses(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected no Content-Encoding, got %q", got)
+	}
+	if got := rr.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Fatalf("expected Vary Accept-Encoding, got %q", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"ok"`) {
+		t.Fatalf("expected plain body, got %q", rr.Body.String())
+	}
+}
+
+func TestGzipRespectsAcceptEncoding(t *testing.T) {
+	t.Parallel()
+
+	for _, acceptEncoding := range []string{"", "br", "gzip;q=0", "*;q=1, gzip;q=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{
+			listEventsFunc: func(context.Context, int64, server.EventFilter) ([]server.Event, error) {
+				return manyEvents(100), nil
+			},
+		}).ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "" {
+			t.Fatalf("%q: expected no Content-Encoding, got %q", acceptEncoding, got)
+		}
+		if !strings.HasPrefix(rr.Body.String(), `{"data":`) {
+			t.Fatalf("%q: expected plain JSON body, got %q", acceptEncoding, rr.Body.String()[:20])
+		}
+	}
+}
+
+func TestGzipSkipsPDF(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			entries := make([]server.WeightEntry, 60)
+			for i := range entries {
+				entries[i] = server.WeightEntry{
+					OccurredAt: mustParseRFC3339(t, fmt.Sprintf("2026-02-%02dT10:00:00Z", i%28+1)),
+					WeightKg:   3.4,
+				}
+			}
+			return entries, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected no Content-Encoding for PDF, got %q", got)
+	}
+	if !strings.HasPrefix(rr.Body.String(), "%PDF-1.4") || rr.Body.Len() < 1024 {
+		t.Fatalf("expected an uncompressed PDF over the threshold, got %d bytes", rr.Body.Len())
+	}
+}
+
+func TestSecurityHeadersOnHTMLReport(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/report", nil)
+	req.Header.Set("Accept", "text/html")
+	rr := httptest.NewRecorder()
+
+	server.NewRouterWithConfig(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		listWeightFunc: func(context.Context, int64) ([]server.WeightEntry, error) {
+			return []server.WeightEntry{}, nil
+		},
+	}, server.Config{SecurityHeaders: true}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Fatalf("expected nosniff, got %q", got)
+	}
+	if got := rr.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Fatalf("expected X-Frame-Options DENY, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Security-Policy"); !strings.Contains(got, "default-src 'none'") {
+		t.Fatalf("expected a restrictive CSP on HTML, got %q", got)
+	}
+}
+
+func TestSecurityHeadersSkipCSPOnJSON(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	server.NewRouterWithConfig(stubBabyStore{
+		listEventsFunc: func(context.Context, int64, server.EventFilter) ([]server.Event, error) {
+			return manyEvents(100), nil
+		},
+	}, server.Config{SecurityHeaders: true}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Fatalf("expected nosniff, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Security-Policy"); got != "" {
+		t.Fatalf("expected no CSP on JSON, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected the response to still be compressed, got %q", got)
+	}
+}
+
+func TestSecurityHeadersDisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("X-Frame-Options"); got != "" {
+		t.Fatalf("expected no X-Frame-Options by default, got %q", got)
+	}
+}
+
+func TestRequestIDEchoesClientID(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("X-Request-ID", "trace-123")
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("X-Request-ID"); got != "trace-123" {
+		t.Fatalf("expected echoed request id trace-123, got %q", got)
+	}
+}
+
+func TestRequestIDGeneratedWhenMissingOrInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, clientID := range []string{"", "has space", "line\nbreak", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		if clientID != "" {
+			req.Header.Set("X-Request-ID", clientID)
+		}
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+		got := rr.Header().Get("X-Request-ID")
+		if got == "" || got == clientID {
+			t.Fatalf("%q: expected a generated request id, got %q", clientID, got)
+		}
+	}
+}
+
+func TestRequestIDInLogs(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events", nil)
+	req.Header.Set("X-Request-ID", "trace-456")
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listEventsFunc: func(context.Context, int64, server.EventFilter) ([]server.Event, error) {
+			return nil, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if !strings.Contains(buf.String(), "ERROR list events failed: boom request_id=trace-456") {
+		t.Fatalf("expected request id in log line, got %q", buf.String())
+	}
+}
+
+func TestResponseTimeHeaderMatchesLog(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies", nil)
+	req.Header.Set("X-Request-ID", "trace-789")
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	elapsed := rr.Header().Get("X-Response-Time-ms")
+	if ms, err := strconv.ParseFloat(elapsed, 64); err != nil || ms < 0 {
+		t.Fatalf("expected a duration in milliseconds, got %q", elapsed)
+	}
+	want := fmt.Sprintf("request_id=trace-789 method=GET path=/v1/babies status=200 response_time_ms=%s", elapsed)
+	if !strings.Contains(buf.String(), want) {
+		t.Fatalf("expected %q in the log, got %q", want, buf.String())
+	}
+}
+
+func TestRequestIDFromContextOutsideRequest(t *testing.T) {
+	t.Parallel()
+
+	if got := server.RequestIDFromContext(context.Background()); got != "" {
+		t.Fatalf("expected empty request id, got %q", got)
+	}
+}
+
+func TestActorReachesStore(t *testing.T) {
+	t.Parallel()
+
+	for header, want := range map[string]string{"dad": "dad", "": "", "has space": ""} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{"type":"diaper","occurred_at":"2026-02-26T10:00:00Z"}`))
+		if header != "" {
+			req.Header.Set("X-Actor", header)
+		}
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{
+			createEventFunc: func(ctx context.Context, input server.CreateEventInput) (server.Event, error) {
+				if got := server.ActorFromContext(ctx); got != want {
+					t.Fatalf("%q: expected actor %q, got %q", header, want, got)
+				}
+				return server.Event{ID: 1, BabyID: input.BabyID, Type: input.Type}, nil
+			},
+		}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("%q: expected status %d, got %d", header, http.StatusCreated, rr.Code)
+		}
+	}
+}
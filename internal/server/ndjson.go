@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// ndjsonFlushEvery is how many events are written between flushes.
+	ndjsonFlushEvery = 100
+	// defaultImportChunkSize is how many lines an import inserts per
+	// transaction unless Config.ImportChunkSize says otherwise.
+	defaultImportChunkSize = 500
+	// maxNDJSONLineBytes bounds one line of an import.
+	maxNDJSONLineBytes = 64 * 1024
+	// maxReportedImportFailures bounds the failures listed in an import
+	// result; Failed still counts them all.
+	maxReportedImportFailures = 1000
+	// importReadWindow is how long an import may take to send its next
+	// chunk of lines before the read deadline passes.
+	importReadWindow = 30 * time.Second
+)
+
+// extendReadDeadline moves the read deadline importReadWindow ahead. The
+// server's ReadTimeout covers the whole body of ordinary requests, which
+// would cut a large import off midway, so the import calls it before
+// reading and after every chunk. Writers without deadlines are left
+// alone.
+func extendReadDeadline(controller *http.ResponseController) error {
+	err := controller.SetReadDeadline(time.Now().Add(importReadWindow))
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// importLineError reports why one line of an import was not stored.
+// Lines are numbered from 1.
+type importLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ndjsonImportResult is the outcome of a JSON Lines import.
+type ndjsonImportResult struct {
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	Failures []importLineError `json:"failures"`
+}
+
+func (res *ndjsonImportResult) fail(line int, msg string) {
+	res.Failed++
+	if len(res.Failures) < maxReportedImportFailures {
+		res.Failures = append(res.Failures, importLineError{Line: line, Error: msg})
+	}
+}
+
+// exportEventsNDJSON streams every event as one JSON object per line while
+// it is read from the store. As with the CSV export, the status line waits
+// for the first event so an early store failure still gets an error status,
+// and the checksum follows the body as a trailer.
+func exportEventsNDJSON(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		controller := http.NewResponseController(w)
+		if err := extendWriteDeadline(controller); err != nil {
+			logf(r.Context(), "extend write deadline for ndjson failed: %v", err)
+		}
+		sum := sha256.New()
+		encoder := json.NewEncoder(io.MultiWriter(w, sum))
+		started := false
+		rows := 0
+
+		start := func() {
+			started = true
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Trailer", contentSHA256Header)
+			w.WriteHeader(http.StatusOK)
+		}
+
+		err = store.StreamEvents(r.Context(), babyID, func(event Event) error {
+			if !started {
+				start()
+			}
+
+			// Encode terminates each value with a newline.
+			if err := encoder.Encode(event); err != nil {
+				return err
+			}
+
+			rows++
+			if rows%ndjsonFlushEvery == 0 {
+				if err := controller.Flush(); err != nil {
+					return err
+				}
+				return extendWriteDeadline(controller)
+			}
+			return nil
+		})
+		if err != nil {
+			if !started {
+				writeStoreError(w, r, "stream events for ndjson", err)
+				return
+			}
+			logf(r.Context(), "stream events for ndjson failed after %d rows: %v", rows, err)
+			return
+		}
+
+		if !started {
+			start()
+		}
+		w.Header().Set(contentSHA256Header, hex.EncodeToString(sum.Sum(nil)))
+	}
+}
+
+// importEventsNDJSON reads one createEventRequest per line and stores the
+// valid ones, Config.ImportChunkSize at a time, each chunk in its own
+// transaction. Unlike a batch, a bad line does not abort the import: it
+// is listed in the result with its line number, and a chunk the store
+// rejects fails only its own lines. Blank lines are skipped. The body is
+// read as it arrives, so imports are not bounded by the batch limit or,
+// while lines keep coming, by the server's read and write timeouts.
+func importEventsNDJSON(store BabyStore, cfg Config) http.HandlerFunc {
+	chunkSize := cmp.Or(cfg.ImportChunkSize, defaultImportChunkSize)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		// Fail fast rather than reporting every line as a store error.
+		_, err = store.GetBaby(r.Context(), babyID, BabyFilter{})
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "get baby for import", err)
+			return
+		}
+
+		controller := http.NewResponseController(w)
+		extend := func() {
+			if err := extendReadDeadline(controller); err != nil {
+				logf(r.Context(), "extend read deadline for import failed: %v", err)
+			}
+		}
+		extend()
+
+		result := ndjsonImportResult{Failures: []importLineError{}}
+		var (
+			chunk      []CreateEventInput
+			chunkLines []int
+		)
+		flush := func() {
+			if len(chunk) == 0 {
+				return
+			}
+			events, err := store.CreateEvents(r.Context(), chunk)
+			if err == nil {
+				result.Imported += len(events)
+			} else {
+				msg := ErrConstraintViolation.Error()
+				if !errors.Is(err, ErrConstraintViolation) {
+					logf(r.Context(), "import events chunk failed: %v", err)
+					msg = "failed to store the chunk holding this line"
+				}
+				for _, line := range chunkLines {
+					result.fail(line, msg)
+				}
+			}
+			chunk, chunkLines = nil, nil
+		}
+
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 0, 4096), maxNDJSONLineBytes)
+		line, seen := 0, 0
+		for scanner.Scan() {
+			line++
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+			seen++
+
+			var req createEventRequest
+			if err := json.Unmarshal(text, &req); err != nil {
+				result.fail(line, "invalid json")
+				continue
+			}
+			input, err := buildCreateEventInput(babyID, req, cfg)
+			if err != nil {
+				result.fail(line, err.Error())
+				continue
+			}
+
+			chunk = append(chunk, input)
+			chunkLines = append(chunkLines, line)
+			if len(chunk) == chunkSize {
+				flush()
+				extend()
+			}
+		}
+		flush()
+
+		// The lines after an unreadable one cannot be told apart, so the
+		// import stops there.
+		if err := scanner.Err(); err != nil {
+			msg := "failed to read the request body"
+			if errors.Is(err, bufio.ErrTooLong) {
+				msg = fmt.Sprintf("line exceeds %d bytes; the rest of the body was not read", maxNDJSONLineBytes)
+			}
+			result.fail(line+1, msg)
+		} else if seen == 0 {
+			http.Error(w, "request body required", http.StatusBadRequest)
+			return
+		}
+
+		// The write deadline was set when the request arrived.
+		if err := extendWriteDeadline(controller); err != nil {
+			logf(r.Context(), "extend write deadline for import failed: %v", err)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": result})
+	}
+}
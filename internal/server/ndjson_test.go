@@ -0,0 +1,319 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestExportEventsNDJSON(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.ndjson", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(_ context.Context, babyID int64, fn func(server.Event) error) error {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			for _, event := range []server.Event{
+				{ID: 1, BabyID: 42, Type: "nursing", OccurredAt: mustParseRFC3339(t, "2026-02-26T08:00:00Z"), Details: json.RawMessage(`{"side":"left","duration_minutes":12}`)},
+				{ID: 2, BabyID: 42, Type: "diaper", OccurredAt: mustParseRFC3339(t, "2026-02-26T09:00:00Z"), Details: json.RawMessage(`{"notes":"line\nbreak"}`)},
+			} {
+				if err := fn(event); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Fatalf("expected NDJSON content type, got %q", got)
+	}
+	if got := rr.Result().Trailer.Get("X-Content-SHA256"); got != sha256Hex(rr.Body.Bytes()) {
+		t.Fatalf("expected an X-Content-SHA256 trailer of %s, got %q", sha256Hex(rr.Body.Bytes()), got)
+	}
+
+	var events []server.Event
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var event server.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("failed to parse line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 2 || events[0].ID != 1 || events[1].Type != "diaper" {
+		t.Fatalf("expected one line per event, got %+v", events)
+	}
+}
+
+func TestExportEventsNDJSONFlushesWhileStreaming(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.ndjson", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(_ context.Context, _ int64, fn func(server.Event) error) error {
+			occurredAt := mustParseRFC3339(t, "2026-02-26T08:00:00Z")
+			for i := 1; i <= 100; i++ {
+				if err := fn(server.Event{ID: int64(i), Type: "diaper", OccurredAt: occurredAt.Add(time.Duration(i) * time.Minute)}); err != nil {
+					return err
+				}
+			}
+			if !rr.Flushed || strings.Count(rr.Body.String(), "\n") != 100 {
+				t.Fatal("expected the first 100 events to be flushed before the stream ends")
+			}
+			return nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestExportEventsNDJSONExtendsWriteDeadline(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.ndjson", nil)
+	rr := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	started := time.Now()
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(_ context.Context, _ int64, fn func(server.Event) error) error {
+			for i := 1; i <= 250; i++ {
+				if err := fn(server.Event{ID: int64(i), Type: "diaper"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if len(rr.deadlines) != 3 {
+		t.Fatalf("expected 3 write deadlines, got %v", rr.deadlines)
+	}
+	for _, deadline := range rr.deadlines {
+		if !deadline.After(started) {
+			t.Fatalf("expected write deadlines in the future, got %v", rr.deadlines)
+		}
+	}
+}
+
+func TestImportEventsNDJSONExtendsDeadlines(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat(`{"type":"diaper","occurred_at":"2026-03-01T08:00:00Z"}`+"\n", 5)
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rr := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	started := time.Now()
+	server.NewRouterWithConfig(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		createEventsFunc: func(_ context.Context, inputs []server.CreateEventInput) ([]server.Event, error) {
+			return make([]server.Event, len(inputs)), nil
+		},
+	}, server.Config{ImportChunkSize: 2}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	// Once before reading and again after each of the two full chunks.
+	if len(rr.readDeadlines) != 3 {
+		t.Fatalf("expected 3 read deadlines, got %v", rr.readDeadlines)
+	}
+	if len(rr.deadlines) != 1 {
+		t.Fatalf("expected the write deadline to be moved before answering, got %v", rr.deadlines)
+	}
+	for _, deadline := range slices.Concat(rr.readDeadlines, rr.deadlines) {
+		if !deadline.After(started) {
+			t.Fatalf("expected deadlines in the future, got %v and %v", rr.readDeadlines, rr.deadlines)
+		}
+	}
+}
+
+func TestExportEventsNDJSONBrokenStream(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.ndjson", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(_ context.Context, _ int64, fn func(server.Event) error) error {
+			if err := fn(server.Event{ID: 1, Type: "diaper", OccurredAt: mustParseRFC3339(t, "2026-02-26T09:00:00Z")}); err != nil {
+				return err
+			}
+			return errors.New("connection reset")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected the status to be sent with the first event, got %d", rr.Code)
+	}
+	if got := rr.Result().Trailer.Get("X-Content-SHA256"); got != "" {
+		t.Fatalf("expected no checksum for a broken stream, got %q", got)
+	}
+}
+
+func TestExportEventsNDJSONStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events.ndjson", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		streamEventsFunc: func(context.Context, int64, func(server.Event) error) error {
+			return errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestImportEventsNDJSON(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Join([]string{
+		`{"type":"diaper","occurred_at":"2026-03-01T08:00:00Z","kind":"wet"}`,
+		`{"type":"diaper"}`,
+		``,
+		`{"type":"sleep","start_at":"2026-03-01T09:00:00Z","end_at":"2026-03-01T10:00:00Z"}`,
+		`not json`,
+		`{"type":"nursing","occurred_at":"2026-03-01T11:00:00Z","side":"left","duration_minutes":12}`,
+		`{"type":"diaper","occurred_at":"2026-03-01T12:00:00Z","notes":"reject me"}`,
+		`{"type":"diaper","occurred_at":"2026-03-01T13:00:00Z"}`,
+	}, "\n")
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+
+	var chunks [][]string
+	server.NewRouterWithConfig(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		createEventsFunc: func(_ context.Context, inputs []server.CreateEventInput) ([]server.Event, error) {
+			var types []string
+			for _, input := range inputs {
+				if input.BabyID != 42 {
+					t.Fatalf("expected baby 42, got %+v", input)
+				}
+				types = append(types, input.Type)
+			}
+			chunks = append(chunks, types)
+			// The second chunk holds the note the "database" rejects.
+			if len(chunks) == 2 {
+				return nil, server.ErrConstraintViolation
+			}
+			return make([]server.Event, len(inputs)), nil
+		},
+	}, server.Config{ImportChunkSize: 2}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if got := len(chunks); got != 3 {
+		t.Fatalf("expected 3 chunks of at most 2 events, got %v", chunks)
+	}
+
+	var got struct {
+		Data struct {
+			Imported int `json:"imported"`
+			Failed   int `json:"failed"`
+			Failures []struct {
+				Line  int    `json:"line"`
+				Error string `json:"error"`
+			} `json:"failures"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Data.Imported != 3 || got.Data.Failed != 4 {
+		t.Fatalf("expected 3 imported and 4 failed, got %s", rr.Body.String())
+	}
+	lines := make([]int, 0, len(got.Data.Failures))
+	for _, failure := range got.Data.Failures {
+		if failure.Error == "" {
+			t.Fatalf("expected a reason for line %d", failure.Line)
+		}
+		lines = append(lines, failure.Line)
+	}
+	if want := []int{2, 5, 6, 7}; !slices.Equal(lines, want) {
+		t.Fatalf("expected failed lines %v, got %v", want, lines)
+	}
+}
+
+func TestImportEventsNDJSONErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{name: "unknown baby", path: "/v1/babies/7/events:import", body: `{"type":"diaper","occurred_at":"2026-03-01T08:00:00Z"}`, want: http.StatusNotFound},
+		{name: "invalid baby id", path: "/v1/babies/abc/events:import", body: `{}`, want: http.StatusBadRequest},
+		{name: "empty body", path: "/v1/babies/42/events:import", body: "\n\n", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{data: []server.Baby{{ID: 42, Name: "Mila"}}}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportEventsNDJSONLineTooLong(t *testing.T) {
+	t.Parallel()
+
+	body := `{"type":"diaper","occurred_at":"2026-03-01T08:00:00Z"}` + "\n" +
+		`{"type":"diaper","occurred_at":"2026-03-01T09:00:00Z","notes":"` + strings.Repeat("a", 70*1024) + `"}` + "\n" +
+		`{"type":"diaper","occurred_at":"2026-03-01T10:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events:import", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		createEventsFunc: func(_ context.Context, inputs []server.CreateEventInput) ([]server.Event, error) {
+			return make([]server.Event, len(inputs)), nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), `"imported":1,"failed":1,"failures":[{"line":2,`) {
+		t.Fatalf("expected the first line imported and the import stopped at line 2, got %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,19 @@
+package server
+
+import "strings"
+
+// NormalizeText trims surrounding whitespace. With collapseSpaces, every
+// run of internal whitespace, line breaks included, becomes one space;
+// without it the inner text is kept as typed, so multi-line notes survive.
+func NormalizeText(value string, collapseSpaces bool) string {
+	if collapseSpaces {
+		return strings.Join(strings.Fields(value), " ")
+	}
+	return strings.TrimSpace(value)
+}
+
+// normalizeKeyword prepares an enumerated value such as an event type or a
+// nursing side for comparison: collapsed and lowercased.
+func normalizeKeyword(value string) string {
+	return strings.ToLower(NormalizeText(value, true))
+}
@@ -0,0 +1,60 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestNormalizeText(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		value    string
+		collapse bool
+		want     string
+	}{
+		{value: "  Mila  ", collapse: false, want: "Mila"},
+		{value: "\tMila \n", collapse: true, want: "Mila"},
+		{value: "Mila   Rose", collapse: false, want: "Mila   Rose"},
+		{value: " Mila \t Rose\n", collapse: true, want: "Mila Rose"},
+		{value: "fed well\n\nslept after ", collapse: false, want: "fed well\n\nslept after"},
+		{value: " \n\t ", collapse: true, want: ""},
+	} {
+		if got := server.NormalizeText(tc.value, tc.collapse); got != tc.want {
+			t.Fatalf("NormalizeText(%q, %t): expected %q, got %q", tc.value, tc.collapse, tc.want, got)
+		}
+	}
+}
+
+func TestCreateEventNormalizesInput(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+		"type": " Diaper ",
+		"occurred_at": "2026-02-26T10:00:00Z",
+		"kind": "  WET ",
+		"notes": "  after the\nmorning feed  "
+	}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			if input.Type != "diaper" {
+				t.Fatalf("expected type diaper, got %q", input.Type)
+			}
+			if string(input.Details) != `{"kind":"wet","notes":"after the\nmorning feed"}` {
+				t.Fatalf("unexpected details %s", input.Details)
+			}
+			return server.Event{ID: 1, BabyID: input.BabyID, Type: input.Type}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+}
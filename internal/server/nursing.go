@@ -0,0 +1,231 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRecentFeeds = 10
+	maxRecentFeeds     = 50
+
+	defaultFeedingIntervalMinutes = 180
+	maxFeedingIntervalMinutes     = 24 * 60
+)
+
+type recentSideBalance struct {
+	Feeds    int     `json:"feeds"`
+	Left     int     `json:"left"`
+	Right    int     `json:"right"`
+	LastSide *string `json:"last_side"`
+	NextSide *string `json:"next_side"`
+}
+
+type sideMinutes struct {
+	LeftMinutes  int      `json:"left_minutes"`
+	RightMinutes int      `json:"right_minutes"`
+	TotalMinutes int      `json:"total_minutes"`
+	LeftPercent  *float64 `json:"left_percent"`
+	RightPercent *float64 `json:"right_percent"`
+}
+
+// feedingReminder is when the next feed is due. Status is "feed_now" when
+// it is overdue or no feed was ever logged, and "upcoming" otherwise.
+type feedingReminder struct {
+	LastFeedingAt   *time.Time `json:"last_feeding_at"`
+	IntervalMinutes int        `json:"interval_minutes"`
+	NextDueAt       time.Time  `json:"next_due_at"`
+	Overdue         bool       `json:"overdue"`
+	Status          string     `json:"status"`
+}
+
+// getRecentSideBalance reports the left/right split over the last n
+// nursing events (default 10, capped at 50) and which side is due next.
+func getRecentSideBalance(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		n := defaultRecentFeeds
+		if value := strings.TrimSpace(r.URL.Query().Get("n")); value != "" {
+			n, err = strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				http.Error(w, "n must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+		n = min(n, maxRecentFeeds)
+
+		balance, err := store.RecentSideBalance(r.Context(), babyID, n)
+		if err != nil {
+			writeStoreError(w, r, "recent side balance", err)
+			return
+		}
+
+		data := recentSideBalance{
+			Feeds: balance.Left + balance.Right,
+			Left:  balance.Left,
+			Right: balance.Right,
+		}
+		if balance.LastSide != "" {
+			data.LastSide = &balance.LastSide
+		}
+		if next := nextNursingSide(balance); next != "" {
+			data.NextSide = &next
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
+
+// nextNursingSide picks the less used side, or the side opposite the last
+// feed when both are even. It returns "" when there are no feeds.
+func nextNursingSide(balance SideBalance) string {
+	switch {
+	case balance.Left < balance.Right:
+		return "left"
+	case balance.Right < balance.Left:
+		return "right"
+	case balance.LastSide == "left":
+		return "right"
+	case balance.LastSide == "right":
+		return "left"
+	default:
+		return ""
+	}
+}
+
+// getFeedingIntervals reports the gaps between consecutive feeds over an
+// inclusive range of local days. Minutes are rounded to one decimal.
+func getFeedingIntervals(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		fallback, err := babyLocation(r, store, babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby", err)
+			return
+		}
+
+		days, err := parseDayRange(r, fallback)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := store.FeedingIntervals(r.Context(), babyID, days.start(), days.end())
+		if err != nil {
+			writeStoreError(w, r, "feeding intervals", err)
+			return
+		}
+
+		for _, minutes := range []*float64{data.AverageMinutes, data.MinMinutes, data.MaxMinutes} {
+			if minutes != nil {
+				*minutes = math.Round(*minutes*10) / 10
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
+
+// getSideMinutes reports nursing minutes per side over an inclusive range
+// of local days. The percentages are rounded to one decimal and are null
+// when nothing was logged.
+func getSideMinutes(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		fallback, err := babyLocation(r, store, babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby", err)
+			return
+		}
+
+		days, err := parseDayRange(r, fallback)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		minutes, err := store.NursingMinutesBySide(r.Context(), babyID, days.start(), days.end())
+		if err != nil {
+			writeStoreError(w, r, "nursing minutes by side", err)
+			return
+		}
+
+		data := sideMinutes{
+			LeftMinutes:  minutes.Left,
+			RightMinutes: minutes.Right,
+			TotalMinutes: minutes.Left + minutes.Right,
+		}
+		if data.TotalMinutes > 0 {
+			left := math.Round(float64(minutes.Left)*1000/float64(data.TotalMinutes)) / 10
+			right := math.Round((100-left)*10) / 10
+			data.LeftPercent = &left
+			data.RightPercent = &right
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
+
+// getReminders computes when the next feed is due, interval_minutes
+// (default 180, at most a day) after the last one. Nothing is scheduled;
+// clients poll it to drive their own notifications.
+func getReminders(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		interval := defaultFeedingIntervalMinutes
+		if value := strings.TrimSpace(r.URL.Query().Get("interval_minutes")); value != "" {
+			interval, err = strconv.Atoi(value)
+			if err != nil || interval <= 0 || interval > maxFeedingIntervalMinutes {
+				http.Error(w, fmt.Sprintf("interval_minutes must be an integer from 1 to %d", maxFeedingIntervalMinutes), http.StatusBadRequest)
+				return
+			}
+		}
+
+		status, err := store.GetBabyStatus(r.Context(), babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby status for reminders", err)
+			return
+		}
+
+		now := time.Now().UTC()
+		data := feedingReminder{
+			LastFeedingAt:   status.LastFeedingAt,
+			IntervalMinutes: interval,
+			NextDueAt:       now,
+			Status:          "feed_now",
+		}
+		if status.LastFeedingAt != nil {
+			data.NextDueAt = status.LastFeedingAt.UTC().Add(time.Duration(interval) * time.Minute)
+			data.Overdue = now.After(data.NextDueAt)
+			if !data.Overdue {
+				data.Status = "upcoming"
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
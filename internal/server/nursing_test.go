@@ -0,0 +1,316 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestGetRecentSideBalance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		query    string
+		balance  server.SideBalance
+		limit    int
+		wantNext any
+	}{
+		{name: "default limit", query: "", balance: server.SideBalance{Left: 4, Right: 6, LastSide: "right"}, limit: 10, wantNext: "left"},
+		{name: "tie uses opposite of last", query: "?n=4", balance: server.SideBalance{Left: 2, Right: 2, LastSide: "left"}, limit: 4, wantNext: "right"},
+		{name: "capped limit", query: "?n=500", balance: server.SideBalance{Left: 30, Right: 20, LastSide: "left"}, limit: 50, wantNext: "right"},
+		{name: "no feeds", query: "?n=5", balance: server.SideBalance{}, limit: 5, wantNext: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/recent-side-balance"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				sideBalanceFunc: func(_ context.Context, babyID int64, limit int) (server.SideBalance, error) {
+					if babyID != 42 {
+						t.Fatalf("expected baby id 42, got %d", babyID)
+					}
+					if limit != tt.limit {
+						t.Fatalf("expected limit %d, got %d", tt.limit, limit)
+					}
+					return tt.balance, nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+
+			var got struct {
+				Data map[string]any `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if got.Data["next_side"] != tt.wantNext {
+				t.Fatalf("expected next_side %v, got %v", tt.wantNext, got.Data["next_side"])
+			}
+		})
+	}
+}
+
+func TestGetRecentSideBalanceInvalidN(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []string{"0", "-3", "ten"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/recent-side-balance?n="+n, nil)
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d for n=%s, got %d", http.StatusBadRequest, n, rr.Code)
+		}
+	}
+}
+
+func TestGetRecentSideBalanceStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/recent-side-balance", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		sideBalanceFunc: func(_ context.Context, _ int64, _ int) (server.SideBalance, error) {
+			return server.SideBalance{}, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestGetFeedingIntervals(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/feeding-intervals?from=2026-03-01&to=2026-03-02", nil)
+	rr := httptest.NewRecorder()
+
+	avg, minGap, maxGap := 172.333333, 150.0, 195.04
+	server.NewRouter(stubBabyStore{
+		intervalsFunc: func(_ context.Context, babyID int64, from, to time.Time) (server.FeedingIntervals, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			if !from.Equal(mustParseRFC3339(t, "2026-03-01T00:00:00Z")) || !to.Equal(mustParseRFC3339(t, "2026-03-03T00:00:00Z")) {
+				t.Fatalf("unexpected window %s - %s", from, to)
+			}
+			return server.FeedingIntervals{Feeds: 4, AverageMinutes: &avg, MinMinutes: &minGap, MaxMinutes: &maxGap}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data server.FeedingIntervals `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Data.Feeds != 4 || *got.Data.AverageMinutes != 172.3 || *got.Data.MinMinutes != 150 || *got.Data.MaxMinutes != 195 {
+		t.Fatalf("unexpected intervals %+v", got.Data)
+	}
+}
+
+func TestGetFeedingIntervalsTooFewFeeds(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/feeding-intervals?from=2026-03-01&to=2026-03-02", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		intervalsFunc: func(context.Context, int64, time.Time, time.Time) (server.FeedingIntervals, error) {
+			return server.FeedingIntervals{Feeds: 1}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	want := `{"data":{"feeds":1,"average_minutes":null,"min_minutes":null,"max_minutes":null}}`
+	if got := strings.TrimSpace(rr.Body.String()); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestGetFeedingIntervalsErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{query: "to=2026-03-02", want: http.StatusBadRequest},
+		{query: "from=2026-03-01&to=2026-03-02&tz=Nowhere", want: http.StatusBadRequest},
+		{query: "from=2026-03-01&to=2026-03-02", want: http.StatusInternalServerError},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/feeding-intervals?"+tt.query, nil)
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{
+			intervalsFunc: func(context.Context, int64, time.Time, time.Time) (server.FeedingIntervals, error) {
+				return server.FeedingIntervals{}, errors.New("boom")
+			},
+		}).ServeHTTP(rr, req)
+
+		if rr.Code != tt.want {
+			t.Fatalf("%s: expected status %d, got %d", tt.query, tt.want, rr.Code)
+		}
+	}
+}
+
+func TestGetSideMinutes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		minutes server.SideMinutes
+		want    string
+	}{
+		{name: "split", minutes: server.SideMinutes{Left: 40, Right: 20}, want: `{"left_minutes":40,"right_minutes":20,"total_minutes":60,"left_percent":66.7,"right_percent":33.3}`},
+		{name: "no feeds", minutes: server.SideMinutes{}, want: `{"left_minutes":0,"right_minutes":0,"total_minutes":0,"left_percent":null,"right_percent":null}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/side-minutes?from=2026-03-01&to=2026-03-02", nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				sideMinutesFunc: func(_ context.Context, babyID int64, from, to time.Time) (server.SideMinutes, error) {
+					if babyID != 42 {
+						t.Fatalf("expected baby id 42, got %d", babyID)
+					}
+					if !from.Equal(mustParseRFC3339(t, "2026-03-01T00:00:00Z")) || !to.Equal(mustParseRFC3339(t, "2026-03-03T00:00:00Z")) {
+						t.Fatalf("unexpected window %s - %s", from, to)
+					}
+					return tt.minutes, nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+
+			var got struct {
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if string(got.Data) != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got.Data)
+			}
+		})
+	}
+}
+
+func TestGetSideMinutesInvalidRange(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/side-minutes?from=2026-03-02", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestGetReminders(t *testing.T) {
+	t.Parallel()
+
+	recent := time.Now().Add(-30 * time.Minute).UTC().Truncate(time.Second)
+	old := time.Now().Add(-5 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		query       string
+		lastFeeding *time.Time
+		wantDue     *time.Time
+		wantOverdue bool
+		wantStatus  string
+	}{
+		{name: "never fed", lastFeeding: nil, wantStatus: "feed_now"},
+		{name: "upcoming", query: "?interval_minutes=60", lastFeeding: &recent, wantDue: ptrTime(recent.Add(time.Hour)), wantStatus: "upcoming"},
+		{name: "overdue", lastFeeding: &old, wantDue: ptrTime(old.Add(3 * time.Hour)), wantOverdue: true, wantStatus: "feed_now"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/reminders"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				statusFunc: func(context.Context, int64) (server.BabyStatus, error) {
+					return server.BabyStatus{LastFeedingAt: tt.lastFeeding}, nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+
+			var got struct {
+				Data struct {
+					NextDueAt time.Time `json:"next_due_at"`
+					Overdue   bool      `json:"overdue"`
+					Status    string    `json:"status"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if got.Data.Status != tt.wantStatus || got.Data.Overdue != tt.wantOverdue {
+				t.Fatalf("expected status %q overdue %v, got %+v", tt.wantStatus, tt.wantOverdue, got.Data)
+			}
+			if tt.wantDue != nil && !got.Data.NextDueAt.Equal(*tt.wantDue) {
+				t.Fatalf("expected next_due_at %s, got %s", tt.wantDue, got.Data.NextDueAt)
+			}
+			if tt.wantDue == nil && time.Since(got.Data.NextDueAt) > time.Minute {
+				t.Fatalf("expected next_due_at to be now, got %s", got.Data.NextDueAt)
+			}
+		})
+	}
+}
+
+func TestGetRemindersInvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	for _, interval := range []string{"0", "-5", "1441", "soon"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/reminders?interval_minutes="+interval, nil)
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status %d, got %d", interval, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func ptrTime(value time.Time) *time.Time {
+	return &value
+}
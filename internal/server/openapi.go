@@ -0,0 +1,293 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// route is one API endpoint. The mux and the OpenAPI document are both
+// built from the same routes, so the spec cannot drift from the router.
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+	doc     routeDoc
+}
+
+// routeDoc describes a route for the OpenAPI document. Path parameters
+// are derived from the route's path.
+type routeDoc struct {
+	summary string
+	params  []openAPIParameter
+	body    *openAPISchema
+	// formBody documents that body may also be sent form-encoded.
+	formBody bool
+	// bodyContentType replaces application/json as the media type of
+	// body, e.g. for a stream of body values.
+	bodyContentType string
+	status          int
+	contentType     string
+	response        *openAPISchema
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       openAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components openAPIComponents                      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Required    bool           `json:"required,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref         string                    `json:"$ref,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	Format      string                    `json:"format,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Nullable    bool                      `json:"nullable,omitempty"`
+	Enum        []string                  `json:"enum,omitempty"`
+	Properties  map[string]*openAPISchema `json:"properties,omitempty"`
+	Required    []string                  `json:"required,omitempty"`
+	Items       *openAPISchema            `json:"items,omitempty"`
+	OneOf       []*openAPISchema          `json:"oneOf,omitempty"`
+}
+
+// openAPIComponentTypes are published under components/schemas and
+// referenced by name from the routes.
+var openAPIComponentTypes = map[string]reflect.Type{
+	"Baby":             reflect.TypeFor[Baby](),
+	"Event":            reflect.TypeFor[Event](),
+	"WeightEntry":      reflect.TypeFor[WeightEntry](),
+	"WeightEntryLb":    reflect.TypeFor[weightEntryLb](),
+	"LatestWeight":     reflect.TypeFor[LatestWeight](),
+	"LatestWeightLb":   reflect.TypeFor[latestWeightLb](),
+	"BabyStatus":       reflect.TypeFor[BabyStatus](),
+	"BabyExport":       reflect.TypeFor[BabyExport](),
+	"FeedingIntervals": reflect.TypeFor[FeedingIntervals](),
+	"EventAuditEntry":  reflect.TypeFor[EventAuditEntry](),
+	"BabyStats":        reflect.TypeFor[BabyStats](),
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func buildOpenAPI(routes []route) openAPIDocument {
+	doc := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: "Baby Tracker API", Version: "1"},
+		Paths:   make(map[string]map[string]openAPIOperation),
+		Components: openAPIComponents{
+			Schemas: make(map[string]*openAPISchema),
+		},
+	}
+
+	for name, typ := range openAPIComponentTypes {
+		doc.Components.Schemas[name] = schemaForType(typ)
+	}
+	for name, schema := range createEventSchemas() {
+		doc.Components.Schemas[name] = schema
+	}
+
+	for _, rt := range routes {
+		operation := openAPIOperation{
+			Summary:   rt.doc.summary,
+			Responses: make(map[string]openAPIResponse),
+		}
+
+		for _, match := range pathParamPattern.FindAllStringSubmatch(rt.path, -1) {
+			operation.Parameters = append(operation.Parameters, openAPIParameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &openAPISchema{Type: "integer", Format: "int64"},
+			})
+		}
+		operation.Parameters = append(operation.Parameters, rt.doc.params...)
+
+		if rt.doc.body != nil {
+			operation.RequestBody = &openAPIRequestBody{
+				Required: true,
+				Content:  map[string]openAPIMediaType{cmp.Or(rt.doc.bodyContentType, "application/json"): {Schema: rt.doc.body}},
+			}
+			if rt.doc.formBody {
+				operation.RequestBody.Content["application/x-www-form-urlencoded"] = openAPIMediaType{Schema: rt.doc.body}
+			}
+		}
+
+		status := rt.doc.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := openAPIResponse{Description: http.StatusText(status)}
+		if rt.doc.response != nil {
+			contentType := rt.doc.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			success.Content = map[string]openAPIMediaType{contentType: {Schema: rt.doc.response}}
+		}
+		operation.Responses[strconv.Itoa(status)] = success
+		operation.Responses["default"] = openAPIResponse{
+			Description: "Error message",
+			Content:     map[string]openAPIMediaType{"text/plain": {Schema: &openAPISchema{Type: "string"}}},
+		}
+
+		if doc.Paths[rt.path] == nil {
+			doc.Paths[rt.path] = make(map[string]openAPIOperation)
+		}
+		doc.Paths[rt.path][strings.ToLower(rt.method)] = operation
+	}
+
+	return doc
+}
+
+func queryParam(name, description string, required bool, schema *openAPISchema) openAPIParameter {
+	return openAPIParameter{
+		Name:        name,
+		In:          "query",
+		Required:    required,
+		Description: description,
+		Schema:      schema,
+	}
+}
+
+func weightUnitParam() openAPIParameter {
+	return queryParam("unit", "Weight unit, defaults to kg.", false, &openAPISchema{
+		Type: "string",
+		Enum: []string{string(unitKilograms), string(unitPounds)},
+	})
+}
+
+// createEventSchemas describes the createEventRequest variants, one per
+// eventTypeSpec.
+func createEventSchemas() map[string]*openAPISchema {
+	schemas := make(map[string]*openAPISchema, len(eventTypeSpecs)+1)
+	variants := make([]*openAPISchema, 0, len(eventTypeSpecs))
+	for _, spec := range eventTypeSpecs {
+		name := "Create" + strings.ToUpper(spec.Type[:1]) + spec.Type[1:] + "Event"
+		schemas[name] = spec.createEventSchema()
+		variants = append(variants, schemaRef(name))
+	}
+	schemas["CreateEventRequest"] = &openAPISchema{OneOf: variants}
+	return schemas
+}
+
+func schemaRef(name string) *openAPISchema {
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// dataEnvelope wraps a schema in the {"data": ...} response envelope.
+func dataEnvelope(schema *openAPISchema) *openAPISchema {
+	return &openAPISchema{
+		Type:       "object",
+		Properties: map[string]*openAPISchema{"data": schema},
+		Required:   []string{"data"},
+	}
+}
+
+// withPaging adds the next_offset and next_cursor paging fields to an
+// envelope, plus the total sent with with_total=true.
+func withPaging(envelope *openAPISchema) *openAPISchema {
+	envelope.Properties["next_offset"] = &openAPISchema{Type: "integer", Nullable: true}
+	envelope.Properties["next_cursor"] = &openAPISchema{Type: "string", Nullable: true}
+	envelope.Properties["total"] = &openAPISchema{Type: "integer", Description: "Matching events across all pages; only sent with with_total=true."}
+	envelope.Required = append(envelope.Required, "next_offset", "next_cursor")
+	return envelope
+}
+
+func arrayOf(schema *openAPISchema) *openAPISchema {
+	return &openAPISchema{Type: "array", Items: schema}
+}
+
+func schemaFor[T any]() *openAPISchema {
+	return schemaForType(reflect.TypeFor[T]())
+}
+
+// schemaForType derives a schema from a Go type using its json tags.
+func schemaForType(typ reflect.Type) *openAPISchema {
+	switch typ {
+	case reflect.TypeFor[time.Time]():
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case reflect.TypeFor[json.RawMessage]():
+		return &openAPISchema{Type: "object"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		schema := schemaForType(typ.Elem())
+		schema.Nullable = true
+		return schema
+	case reflect.Struct:
+		schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			schema.Properties[name] = schemaForType(field.Type)
+		}
+		return schema
+	case reflect.Slice, reflect.Array:
+		return arrayOf(schemaForType(typ.Elem()))
+	case reflect.Map:
+		return &openAPISchema{Type: "object"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	default:
+		return &openAPISchema{Type: "string"}
+	}
+}
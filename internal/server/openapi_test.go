@@ -0,0 +1,133 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"baby-tracker-server/internal/server"
+)
+
+type openAPIDoc struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]json.RawMessage `json:"schemas"`
+	} `json:"components"`
+}
+
+func getOpenAPI(t *testing.T, handler http.Handler) openAPIDoc {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected Content-Type application/json, got %q", got)
+	}
+
+	var doc openAPIDoc
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	return doc
+}
+
+func TestOpenAPIDescribesRoutes(t *testing.T) {
+	t.Parallel()
+
+	doc := getOpenAPI(t, server.NewRouter(stubBabyStore{}))
+
+	if doc.OpenAPI != "3.0.3" {
+		t.Fatalf("expected openapi 3.0.3, got %q", doc.OpenAPI)
+	}
+
+	for path, methods := range map[string][]string{
+		"/healthz":                                 {"get"},
+		"/v1/babies":                               {"get"},
+		"/v1/babies/import":                        {"post"},
+		"/v1/babies/{id}":                          {"get", "patch", "delete"},
+		"/v1/babies/{id}/merge":                    {"post"},
+		"/v1/babies/{id}/events":                   {"get", "post"},
+		"/v1/babies/{id}/events.ndjson":            {"get"},
+		"/v1/babies/{id}/events/recent":            {"get"},
+		"/v1/babies/{id}/events:import":            {"post"},
+		"/v1/babies/{id}/events/{eventId}":         {"get", "patch"},
+		"/v1/babies/{id}/report":                   {"get"},
+		"/v1/babies/{id}/stats":                    {"get"},
+		"/v1/babies/{id}/side-minutes":             {"get"},
+		"/v1/babies/{id}/reminders":                {"get"},
+		"/v1/babies/{id}/activity":                 {"get"},
+		"/v1/babies/{id}/today":                    {"get"},
+		"/v1/babies/{id}/events/{eventId}/history": {"get"},
+		"/v1/report.pdf":                           {"get"},
+		"/v1/babies/{id}/weights":                  {"get", "post"},
+		"/v1/babies/{id}/weights:batch":            {"post"},
+		"/v1/babies/{id}/weights/latest":           {"get"},
+		"/v1/babies/{id}/growth-velocity":          {"get"},
+		"/v1/babies/{id}/weights/{weightId}":       {"delete"},
+		"/v1/babies/{id}/report.pdf":               {"get"},
+		"/v1/event-types":                          {"get"},
+		"/openapi.json":                            {"get"},
+		"/version":                                 {"get"},
+	} {
+		for _, method := range methods {
+			if _, ok := doc.Paths[path][method]; !ok {
+				t.Fatalf("expected %s %s in spec, got %v", method, path, doc.Paths[path])
+			}
+		}
+	}
+
+	if _, ok := doc.Paths["/v1/babies/{id}/seed-demo"]; ok {
+		t.Fatal("expected seed-demo to be absent when demo mode is off")
+	}
+
+	for _, name := range []string{"Baby", "Event", "CreateEventRequest", "CreateSleepEvent"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Fatalf("expected schema %s in components", name)
+		}
+	}
+}
+
+func TestOpenAPIPathParameters(t *testing.T) {
+	t.Parallel()
+
+	doc := getOpenAPI(t, server.NewRouter(stubBabyStore{}))
+
+	var operation struct {
+		Parameters []struct {
+			Name     string `json:"name"`
+			In       string `json:"in"`
+			Required bool   `json:"required"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(doc.Paths["/v1/babies/{id}/events/{eventId}"]["patch"], &operation); err != nil {
+		t.Fatalf("failed to unmarshal operation: %v", err)
+	}
+
+	if len(operation.Parameters) != 2 {
+		t.Fatalf("expected 2 path parameters, got %+v", operation.Parameters)
+	}
+	for i, name := range []string{"id", "eventId"} {
+		param := operation.Parameters[i]
+		if param.Name != name || param.In != "path" || !param.Required {
+			t.Fatalf("expected required path parameter %s, got %+v", name, param)
+		}
+	}
+}
+
+func TestOpenAPIIncludesDemoRouteWhenEnabled(t *testing.T) {
+	t.Parallel()
+
+	doc := getOpenAPI(t, server.NewRouterWithConfig(stubBabyStore{}, server.Config{DemoMode: true}))
+
+	if _, ok := doc.Paths["/v1/babies/{id}/seed-demo"]["post"]; !ok {
+		t.Fatal("expected seed-demo in spec when demo mode is on")
+	}
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultReportTitle heads reports when Config.ReportTitle is unset.
+const defaultReportTitle = "Baby Tracker Report"
+
+// reportBranding is the deployment-specific text around a report.
+type reportBranding struct {
+	Title  string
+	Footer string
+}
+
+func (cfg Config) reportBranding() reportBranding {
+	return reportBranding{Title: cmp.Or(cfg.ReportTitle, defaultReportTitle), Footer: cfg.ReportFooter}
+}
+
+// reportFormat is the representation a baby report is rendered in.
+type reportFormat string
+
+const (
+	reportPDF  reportFormat = "pdf"
+	reportHTML reportFormat = "html"
+)
+
+// getBabyReport renders the baby report. With a fixed format it always
+// answers in that format; otherwise the Accept header picks between PDF
+// and HTML, with PDF as the default.
+func getBabyReport(store BabyStore, fixed reportFormat, branding reportBranding) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		unit, err := parseWeightUnit(r.URL.Query().Get("unit"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		format := fixed
+		if format == "" {
+			w.Header().Add("Vary", "Accept")
+			format = negotiateReportFormat(r.Header.Get("Accept"))
+		}
+
+		baby, err := store.GetBaby(r.Context(), babyID, BabyFilter{})
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "get baby for report", err)
+			return
+		}
+
+		weights, err := store.ListWeightEntries(r.Context(), babyID)
+		if err != nil {
+			writeStoreError(w, r, "list weight entries for report", err)
+			return
+		}
+
+		etag := reportETag(baby, weights, unit, format, branding)
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		if format == reportHTML {
+			page, err := buildBabyReportHTML(baby, weights, unit, branding)
+			if err != nil {
+				logf(r.Context(), "build baby report html failed: %v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(page)
+			return
+		}
+
+		pdf, err := buildBabyReportPDF([]babyReportSection{{Baby: baby, Weights: weights}}, unit, branding)
+		if err != nil {
+			logf(r.Context(), "build baby report pdf failed: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		filename := fmt.Sprintf("baby-report-%d.pdf", babyID)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		writeDownload(w, r, "application/pdf", pdf)
+	}
+}
+
+// maxReportBabies bounds how many babies one combined report covers.
+const maxReportBabies = 10
+
+// getCombinedReportPDF renders one PDF with a page per baby_id, in the
+// order given. Every baby must exist.
+func getCombinedReportPDF(store BabyStore, branding reportBranding) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var babyIDs []int64
+		for _, value := range query["baby_id"] {
+			babyID, err := parseID(value)
+			if err != nil {
+				http.Error(w, "invalid baby id", http.StatusBadRequest)
+				return
+			}
+			if !slices.Contains(babyIDs, babyID) {
+				babyIDs = append(babyIDs, babyID)
+			}
+		}
+		if len(babyIDs) == 0 {
+			http.Error(w, "baby_id is required", http.StatusBadRequest)
+			return
+		}
+		if len(babyIDs) > maxReportBabies {
+			http.Error(w, fmt.Sprintf("a report covers at most %d babies", maxReportBabies), http.StatusBadRequest)
+			return
+		}
+
+		unit, err := parseWeightUnit(query.Get("unit"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sections := make([]babyReportSection, 0, len(babyIDs))
+		for _, babyID := range babyIDs {
+			baby, err := store.GetBaby(r.Context(), babyID, BabyFilter{})
+			if errors.Is(err, ErrNotFound) {
+				http.Error(w, fmt.Sprintf("baby %d not found", babyID), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				writeStoreError(w, r, "get baby for report", err)
+				return
+			}
+
+			weights, err := store.ListWeightEntries(r.Context(), babyID)
+			if err != nil {
+				writeStoreError(w, r, "list weight entries for report", err)
+				return
+			}
+			sections = append(sections, babyReportSection{Baby: baby, Weights: weights})
+		}
+
+		pdf, err := buildBabyReportPDF(sections, unit, branding)
+		if err != nil {
+			logf(r.Context(), "build combined report pdf failed: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Disposition", `attachment; filename="baby-report.pdf"`)
+		writeDownload(w, r, "application/pdf", pdf)
+	}
+}
+
+// negotiateReportFormat picks HTML only when the Accept header ranks
+// text/html above PDF. Wildcards count towards PDF, so a browser sending
+// "text/html,...,*/*;q=0.8" gets HTML and a bare "*/*" gets PDF.
+func negotiateReportFormat(accept string) reportFormat {
+	var htmlQ, pdfQ float64
+	if strings.TrimSpace(accept) == "" {
+		return reportPDF
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if name, value, ok := strings.Cut(param, "="); ok && strings.EqualFold(strings.TrimSpace(name), "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+
+		switch mediaType {
+		case "text/html":
+			htmlQ = max(htmlQ, q)
+		case "application/pdf", "application/*", "*/*":
+			pdfQ = max(pdfQ, q)
+		}
+	}
+
+	if htmlQ > pdfQ {
+		return reportHTML
+	}
+	return reportPDF
+}
+
+// reportETag hashes the data shown in the report, branding included. The
+// PDF itself embeds its generation time, so the tag is weak: equal tags
+// mean the same content, not the same bytes.
+func reportETag(baby Baby, entries []WeightEntry, unit weightUnit, format reportFormat, branding reportBranding) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00%s\x00%s\x00%s\x00%s\x00%s\x00", baby.ID, baby.Name, unit, format, branding.Title, branding.Footer)
+	for _, entry := range entries {
+		fmt.Fprintf(hash, "%d\x00%g\x00", entry.OccurredAt.UnixNano(), entry.WeightKg)
+	}
+	return fmt.Sprintf(`W/"%x"`, hash.Sum(nil)[:16])
+}
+
+var reportHTMLTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}: {{.Baby.Name}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Baby: {{.Baby.Name}} (ID {{.Baby.ID}})</p>
+<p>Generated at: {{.GeneratedAt}}</p>
+<h2>Weight entries</h2>
+<ul>
+{{- range .Weights}}
+<li>{{.OccurredAt}}: {{.Weight}}</li>
+{{- else}}
+<li>none</li>
+{{- end}}
+</ul>
+{{- with .Footer}}
+<footer>{{.}}</footer>
+{{- end}}
+</body>
+</html>
+`))
+
+type reportHTMLWeight struct {
+	OccurredAt string
+	Weight     string
+}
+
+// buildBabyReportHTML renders the same content as the PDF report. The
+// template escapes the baby name and every other value.
+func buildBabyReportHTML(baby Baby, entries []WeightEntry, unit weightUnit, branding reportBranding) ([]byte, error) {
+	weights := make([]reportHTMLWeight, 0, len(entries))
+	for _, entry := range entries {
+		weights = append(weights, reportHTMLWeight{
+			OccurredAt: entry.OccurredAt.UTC().Format(time.RFC3339),
+			Weight:     unit.format(entry.WeightKg),
+		})
+	}
+
+	var buf bytes.Buffer
+	err := reportHTMLTemplate.Execute(&buf, map[string]any{
+		"Baby":        baby,
+		"GeneratedAt": time.Now().UTC().Format(time.RFC3339),
+		"Weights":     weights,
+		"Title":       branding.Title,
+		"Footer":      branding.Footer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -2,80 +2,1802 @@ package server
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"baby-tracker-server/internal/buildinfo"
 )
 
-type Baby struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
-}
+type Baby struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// DeletedAt is set once the baby is archived. Archived babies keep
+	// their events.
+	DeletedAt *time.Time `json:"deleted_at"`
+	// Color is a lower-case #rrggbb hex color the UI tells babies apart
+	// by, and AvatarURL an http(s) image URL. Both are cosmetic and
+	// optional.
+	Color     *string `json:"color"`
+	AvatarURL *string `json:"avatar_url"`
+	// Timezone is an IANA time zone the per-day endpoints use when a
+	// request has no tz of its own.
+	Timezone *string `json:"timezone"`
+}
+
+// NewBabyInput lists the fields of a baby to create. Nil fields are left
+// unset.
+type NewBabyInput struct {
+	Name      string
+	Color     *string
+	AvatarURL *string
+	Timezone  *string
+}
+
+// UpdateBabyInput lists the baby fields to change. Nil fields are kept,
+// and an empty Color, AvatarURL or Timezone clears it.
+type UpdateBabyInput struct {
+	Name      *string
+	Color     *string
+	AvatarURL *string
+	Timezone  *string
+}
+
+// BabyFilter narrows baby lookups. The zero value hides archived babies.
+type BabyFilter struct {
+	IncludeDeleted bool
+	// Sort orders ListBabies, by id when empty. Ties are broken by id in
+	// the same direction.
+	Sort       BabySort
+	Descending bool
+}
+
+// BabySort is a key ListBabies can order by.
+type BabySort string
+
+const (
+	BabySortID        BabySort = "id"
+	BabySortName      BabySort = "name"
+	BabySortCreatedAt BabySort = "created_at"
+)
+
+// babySorts lists the accepted values of GET /v1/babies?sort=.
+var babySorts = []BabySort{BabySortID, BabySortName, BabySortCreatedAt}
+
+type Event struct {
+	ID         int64           `json:"id"`
+	BabyID     int64           `json:"baby_id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Details    json.RawMessage `json:"details"`
+	Tags       []string        `json:"tags"`
+	CreatedAt  time.Time       `json:"created_at"`
+	// UpdatedAt equals CreatedAt until the event is edited.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateEventInput struct {
+	BabyID     int64
+	Type       string
+	OccurredAt time.Time
+	Details    json.RawMessage
+	Tags       []string
+}
+
+// EventFilter narrows ListEvents. Zero fields match every event.
+type EventFilter struct {
+	Types []string
+	// Tags matches events carrying every one of them.
+	Tags []string
+	// Notes matches events whose notes contain it, ignoring case.
+	Notes string
+	// Limit caps how many events are returned; zero returns all of them.
+	Limit int
+	// Offset skips that many events in occurred_at order.
+	Offset int
+	// After lists only events that sort after this one, by occurred_at
+	// and then id. Unlike Offset it does not drift when events are
+	// inserted earlier in the list.
+	After *EventCursor
+	// Newest reverses the order, listing the latest events first. Offset
+	// and After then count from the newest end.
+	Newest bool
+}
+
+// EventCursor is the sort key of an event in ListEvents order.
+type EventCursor struct {
+	OccurredAt time.Time
+	ID         int64
+}
+
+// eventTypes lists every event type stored in the events table.
+var eventTypes = []string{"diaper", "nursing", "sleep", "weight"}
+
+// IdempotencyKeyTTL is how long an Idempotency-Key keeps answering with
+// the event it first created.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// creatableEventTypes are the types clients can create through the API.
+var creatableEventTypes = []string{"diaper", "nursing", "sleep"}
+
+// diaperKinds are the accepted values of a diaper event's optional "kind".
+var diaperKinds = []string{"wet", "dirty", "mixed"}
+
+// WeightEntry is a weight event. ID is the event id, used to delete it.
+type WeightEntry struct {
+	ID         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	WeightKg   float64   `json:"weight_kg"`
+}
+
+// NewWeightEntry is a weight to log with CreateWeightEntries.
+type NewWeightEntry struct {
+	OccurredAt time.Time
+	WeightKg   float64
+}
+
+// LatestWeight is a baby's most recent weight entry. DeltaKg is the change
+// since the entry before it and is nil when there is only one.
+type LatestWeight struct {
+	OccurredAt time.Time `json:"occurred_at"`
+	WeightKg   float64   `json:"weight_kg"`
+	DeltaKg    *float64  `json:"delta_kg"`
+}
+
+type SleepSession struct {
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+type BabyStatus struct {
+	LastFeedingAt *time.Time `json:"last_feeding_at"`
+	LastDiaperAt  *time.Time `json:"last_diaper_at"`
+	Asleep        bool       `json:"asleep"`
+	AsleepSince   *time.Time `json:"asleep_since"`
+	// ElapsedMinutes is how long the current sleep has lasted so far. It
+	// is computed by the handler, not the store.
+	ElapsedMinutes *int `json:"elapsed_minutes"`
+}
+
+// SideBalance counts nursing sides among a baby's most recent feeds.
+type SideBalance struct {
+	Left     int
+	Right    int
+	LastSide string
+}
+
+// SideMinutes totals nursing minutes per side.
+type SideMinutes struct {
+	Left  int
+	Right int
+}
+
+// FeedingIntervals summarizes the gaps between consecutive feeds. The
+// minute fields are nil when there are fewer than two feeds.
+type FeedingIntervals struct {
+	Feeds          int      `json:"feeds"`
+	AverageMinutes *float64 `json:"average_minutes"`
+	MinMinutes     *float64 `json:"min_minutes"`
+	MaxMinutes     *float64 `json:"max_minutes"`
+}
+
+// BabyStats are lifetime totals for a baby. EventCounts is keyed by event
+// type. The minute fields are nil until there is a nursing session or a
+// finished sleep to measure.
+type BabyStats struct {
+	EventCounts           map[string]int64 `json:"event_counts"`
+	AverageNursingMinutes *float64         `json:"average_nursing_minutes"`
+	LongestSleepMinutes   *float64         `json:"longest_sleep_minutes"`
+}
+
+// EventAuditEntry is one recorded change to an event. Actor is who made
+// it, as sent in the X-Actor header, or nil when the request had none.
+type EventAuditEntry struct {
+	Action    string    `json:"action"`
+	Actor     *string   `json:"actor"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// DailyCounts are a baby's diaper and feed totals for one local day.
+// Diapers counts every diaper; the per-kind counts only those logged with
+// that kind.
+type DailyCounts struct {
+	Diapers        int
+	WetDiapers     int
+	DirtyDiapers   int
+	MixedDiapers   int
+	Feeds          int
+	NursingMinutes int
+}
+
+var (
+	// ErrNotFound is returned by stores when the requested record does
+	// not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrSleepEnded is returned when ending a sleep event that already
+	// has an end_at.
+	ErrSleepEnded = errors.New("sleep event already ended")
+	// ErrSleepEndBeforeStart is returned when ending a sleep event at or
+	// before its start_at.
+	ErrSleepEndBeforeStart = errors.New("end_at must be after start_at")
+	// ErrTimeout is returned by stores when a query runs past its
+	// deadline.
+	ErrTimeout = errors.New("store query timed out")
+	// ErrConstraintViolation is returned when the database rejects an
+	// event because it breaks a check constraint.
+	ErrConstraintViolation = errors.New("event violates a data constraint")
+)
+
+type BabyStore interface {
+	// Ping checks that the store can serve queries.
+	Ping(ctx context.Context) error
+	ListBabies(ctx context.Context, filter BabyFilter) ([]Baby, error)
+	// GetBaby returns ErrNotFound when there is no baby with the id, or
+	// when it is archived and filter does not include deleted babies.
+	GetBaby(ctx context.Context, id int64, filter BabyFilter) (Baby, error)
+	// UpdateBaby changes the fields set in input and returns the updated
+	// baby. It returns ErrNotFound when there is no such baby or it is
+	// archived.
+	UpdateBaby(ctx context.Context, id int64, input UpdateBabyInput) (Baby, error)
+	// DeleteBaby archives a baby. It returns ErrNotFound when there is no
+	// such baby or it is already archived.
+	DeleteBaby(ctx context.Context, id int64) error
+	// MergeBabies moves every event of sourceID, weights included, with
+	// its audit history to targetID and archives sourceID, in a single
+	// transaction. It returns the target, or ErrNotFound when either baby
+	// does not exist or is archived.
+	MergeBabies(ctx context.Context, targetID, sourceID int64) (Baby, error)
+	ListEvents(ctx context.Context, babyID int64, filter EventFilter) ([]Event, error)
+	// GetEvent returns ErrNotFound when the event does not exist or
+	// belongs to another baby.
+	GetEvent(ctx context.Context, babyID, eventID int64) (Event, error)
+	CountEvents(ctx context.Context, babyID int64, filter EventFilter) (int64, error)
+	// StreamEvents calls fn for each event in occurred_at order as it is
+	// read, stopping at the first error fn returns.
+	StreamEvents(ctx context.Context, babyID int64, fn func(Event) error) error
+	// CreateEvent stores a validated event. BabyID, Type and OccurredAt
+	// are always required; Details depends on the type:
+	//
+	//   - diaper: optional "notes" and "kind" (wet, dirty or mixed).
+	//   - nursing: "side" (left or right) and "duration_minutes" (> 0).
+	//   - sleep: "start_at", equal to OccurredAt, and an optional "end_at"
+	//     after it. A sleep without end_at is in progress.
+	//   - weight: "weight_kg".
+	//
+	// Stores do not validate Details; buildCreateEventInput does. A
+	// missing baby yields ErrNotFound and a rejected row
+	// ErrConstraintViolation.
+	CreateEvent(ctx context.Context, input CreateEventInput) (Event, error)
+	// FindNearbyEvent returns the baby's event of eventType closest to at,
+	// within window either side, or ErrNotFound when there is none.
+	FindNearbyEvent(ctx context.Context, babyID int64, eventType string, at time.Time, window time.Duration) (Event, error)
+	// FindOverlappingEvent returns the earliest of the baby's sleep or
+	// nursing events of eventType whose session overlaps [start, end), or
+	// ErrNotFound when there is none. A nil end, like a sleep without
+	// end_at, runs on indefinitely; a nursing session lasts
+	// duration_minutes from occurred_at.
+	FindOverlappingEvent(ctx context.Context, babyID int64, eventType string, start time.Time, end *time.Time) (Event, error)
+	// CreateEventIdempotent is CreateEvent guarded by a client-chosen key,
+	// scoped to input.BabyID. The first call with a key inserts the event
+	// and reports created; a repeat within IdempotencyKeyTTL returns the
+	// event stored then, whatever its input.
+	CreateEventIdempotent(ctx context.Context, key string, input CreateEventInput) (event Event, created bool, err error)
+	// GetIdempotentEvent returns the event a live claim on key created for
+	// the baby, or ErrNotFound when the key is unused or has expired.
+	GetIdempotentEvent(ctx context.Context, babyID int64, key string) (Event, error)
+	// CreateEvents inserts all inputs in a single transaction, with the
+	// same requirements and errors as CreateEvent.
+	CreateEvents(ctx context.Context, inputs []CreateEventInput) ([]Event, error)
+	// ImportBaby creates baby together with inputs, whose BabyID is
+	// ignored, in a single transaction. Inputs are validated as for
+	// CreateEvent.
+	ImportBaby(ctx context.Context, baby NewBabyInput, inputs []CreateEventInput) (Baby, error)
+	ListWeightEntries(ctx context.Context, babyID int64) ([]WeightEntry, error)
+	// CreateWeightEntries logs entries as the baby's weight events in a
+	// single transaction, returning them in the same order. It returns
+	// ErrNotFound when the baby does not exist.
+	CreateWeightEntries(ctx context.Context, babyID int64, entries []NewWeightEntry) ([]WeightEntry, error)
+	// DeleteWeightEntry deletes one of a baby's weight events. It returns
+	// ErrNotFound when there is no such weight event for the baby.
+	DeleteWeightEntry(ctx context.Context, babyID, weightID int64) error
+	// PreviousWeight returns the baby's last weight entry at or before
+	// at, or ErrNotFound when there is none.
+	PreviousWeight(ctx context.Context, babyID int64, at time.Time) (WeightEntry, error)
+	// LatestWeight returns the most recent weight entry, or ErrNotFound
+	// when the baby has none.
+	LatestWeight(ctx context.Context, babyID int64) (LatestWeight, error)
+	// ListSleepSessions returns the baby's sleeps overlapping [from, to).
+	// A sleep in progress ends at now or to, whichever is earlier.
+	ListSleepSessions(ctx context.Context, babyID int64, from, to time.Time) ([]SleepSession, error)
+	GetBabyStatus(ctx context.Context, babyID int64) (BabyStatus, error)
+	RecentSideBalance(ctx context.Context, babyID int64, limit int) (SideBalance, error)
+	// NursingMinutesBySide sums duration_minutes of nursing events in
+	// [from, to) per side.
+	NursingMinutesBySide(ctx context.Context, babyID int64, from, to time.Time) (SideMinutes, error)
+	// FeedingIntervals measures the gaps between nursing events in
+	// [from, to).
+	FeedingIntervals(ctx context.Context, babyID int64, from, to time.Time) (FeedingIntervals, error)
+	// BabyStats aggregates all of a baby's events. Types never logged
+	// may be missing from EventCounts.
+	BabyStats(ctx context.Context, babyID int64) (BabyStats, error)
+	// DailyCounts groups events in [from, to) by calendar date (YYYY-MM-DD)
+	// in the tz time zone. Days without events are omitted.
+	DailyCounts(ctx context.Context, babyID int64, from, to time.Time, tz string) (map[string]DailyCounts, error)
+	// EventCountsByLocalDate counts events of every type in [from, to) by
+	// calendar date (YYYY-MM-DD) in the tz time zone. Days without events
+	// are omitted.
+	EventCountsByLocalDate(ctx context.Context, babyID int64, from, to time.Time, tz string) (map[string]int64, error)
+	// EventHistory lists an event's audit entries, oldest first. It
+	// returns ErrNotFound when the event has no history and does not
+	// exist, or belongs to another baby.
+	EventHistory(ctx context.Context, babyID, eventID int64) ([]EventAuditEntry, error)
+	// EndSleep sets end_at on an open sleep event. It returns ErrNotFound
+	// when the baby has no such sleep event, ErrSleepEnded when it is
+	// already closed and ErrSleepEndBeforeStart when endAt is not after
+	// the start.
+	EndSleep(ctx context.Context, babyID, eventID int64, endAt time.Time) (Event, error)
+}
+
+// Config holds optional router behavior. The zero value keeps every
+// optional feature off.
+type Config struct {
+	// RequireUserAgent rejects mutating requests that carry no User-Agent.
+	RequireUserAgent bool
+	// DemoMode exposes POST /v1/babies/{id}/seed-demo.
+	DemoMode bool
+	// PathPrefix is prepended to every route, e.g. "/api" serves
+	// /api/v1/babies. It must start with a slash and not end with one.
+	PathPrefix string
+	// HealthzPath overrides where the health check is served. It is used
+	// as is, without PathPrefix, so load balancers can keep probing
+	// /healthz. Empty serves it at PathPrefix + "/healthz".
+	HealthzPath string
+	// ReadyTimeout bounds the store ping of the readiness probe, so a
+	// half-up database fails the probe instead of hanging it. Zero uses
+	// defaultReadyTimeout.
+	ReadyTimeout time.Duration
+	// SecurityHeaders sets nosniff and X-Frame-Options on every response
+	// and a Content-Security-Policy on HTML ones, for deployments that
+	// serve the HTML report to browsers.
+	SecurityHeaders bool
+	// DuplicateWindow makes creating an event answer 409 when the baby
+	// already has an event of the same type this close to it, unless the
+	// request sets force=true. Zero disables the check.
+	DuplicateWindow time.Duration
+	// MaxWeightChangePerDay is how far, in percent per day, a new weight
+	// may differ from the entry before it. Larger changes are likely
+	// typos and answer 409 unless the request sets force=true. Changes
+	// within a day of the previous entry get one day's allowance. Zero
+	// disables the check.
+	MaxWeightChangePerDay float64
+	// MinWeightKg and MaxWeightKg bound the weights that may be logged,
+	// answering 400 outside them, to catch unit mistakes such as pounds
+	// entered as kilograms. Zero means defaultMinWeightKg and
+	// defaultMaxWeightKg.
+	MinWeightKg float64
+	MaxWeightKg float64
+	// ReportTitle heads the PDF and HTML reports in place of "Baby
+	// Tracker Report", e.g. with a clinic's name.
+	ReportTitle string
+	// ReportFooter is printed at the bottom of every report page. Empty
+	// prints none.
+	ReportFooter string
+	// ImportChunkSize is how many valid lines POST
+	// /v1/babies/{id}/events:import inserts per transaction. Zero means
+	// defaultImportChunkSize.
+	ImportChunkSize int
+	// EventTypes limits which event types clients may create; creating
+	// any other type answers 400. Nil allows every type. Stored events
+	// of a disabled type are still listed and exported.
+	EventTypes []string
+	// RequiredFields makes optional fields of an event type mandatory,
+	// keyed by type, e.g. {"diaper": {"kind", "notes"}}, for deployments
+	// that want stricter data. Nil keeps every optional field optional.
+	RequiredFields map[string][]string
+}
+
+// Validate reports configuration the router cannot honor.
+func (cfg Config) Validate() error {
+	for _, eventType := range cfg.EventTypes {
+		if !slices.Contains(creatableEventTypes, eventType) {
+			return fmt.Errorf("unknown event type %q, must be one of %s", eventType, strings.Join(creatableEventTypes, ", "))
+		}
+	}
+	if minKg, maxKg := cfg.weightBounds(); minKg >= maxKg {
+		return fmt.Errorf("minimum weight %g kg must be below the maximum of %g kg", minKg, maxKg)
+	}
+	for eventType, fields := range cfg.RequiredFields {
+		spec, ok := lookupEventTypeSpec(eventType)
+		if !ok {
+			return fmt.Errorf("unknown event type %q in required fields, must be one of %s", eventType, strings.Join(creatableEventTypes, ", "))
+		}
+		for _, field := range fields {
+			if !slices.Contains(spec.Optional, field) {
+				return fmt.Errorf("%q is not an optional field of %s events, must be one of %s", field, eventType, strings.Join(spec.Optional, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// NewRouter creates the HTTP router for the Baby Tracker API.
+func NewRouter(store BabyStore) http.Handler {
+	return NewRouterWithConfig(store, Config{})
+}
+
+// NewRouterWithConfig creates the HTTP router with the given options.
+func NewRouterWithConfig(store BabyStore, cfg Config) http.Handler {
+	mux := http.NewServeMux()
+
+	var spec openAPIDocument
+	routes := append(apiRoutes(store, cfg), route{
+		method: http.MethodGet,
+		path:   "/openapi.json",
+		handler: func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, http.StatusOK, spec)
+		},
+		doc: routeDoc{summary: "OpenAPI document for this API", response: &openAPISchema{Type: "object"}},
+	})
+	for i := range routes {
+		if routes[i].path == healthzPath && cfg.HealthzPath != "" {
+			routes[i].path = cfg.HealthzPath
+			continue
+		}
+		routes[i].path = cfg.PathPrefix + routes[i].path
+	}
+	spec = buildOpenAPI(routes)
+
+	for _, rt := range routes {
+		mux.HandleFunc(rt.method+" "+rt.path, rt.handler)
+	}
+
+	var handler http.Handler = mux
+	if cfg.RequireUserAgent {
+		handler = requireUserAgent(handler)
+	}
+	if cfg.SecurityHeaders {
+		handler = securityHeaders(handler)
+	}
+	handler = gzipResponses(handler)
+	handler = withActor(handler)
+	handler = logRequests(handler)
+	handler = withRequestID(handler)
+
+	return handler
+}
+
+const healthzPath = "/healthz"
+
+// defaultReadyTimeout is the readiness probe's ping budget when
+// Config.ReadyTimeout is unset.
+const defaultReadyTimeout = time.Second
+
+// apiRoutes lists the API endpoints along with their OpenAPI description.
+func apiRoutes(store BabyStore, cfg Config) []route {
+	dateParam := &openAPISchema{Type: "string", Format: "date"}
+	tzParam := queryParam("tz", "IANA time zone, defaults to the baby's timezone and then to UTC", false, &openAPISchema{Type: "string"})
+	includeDeletedParam := queryParam("include_deleted", "Include archived babies", false, &openAPISchema{Type: "boolean"})
+	envelopeParam := queryParam("envelope", "Set to false to get the bare array instead of the data envelope", false, &openAPISchema{Type: "boolean"})
+
+	routes := []route{
+		{
+			method:  http.MethodGet,
+			path:    healthzPath,
+			handler: healthz,
+			doc: routeDoc{
+				summary: "Health check",
+				response: &openAPISchema{
+					Type:       "object",
+					Properties: map[string]*openAPISchema{"status": {Type: "string"}},
+				},
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/readyz",
+			handler: readyz(store, cmp.Or(cfg.ReadyTimeout, defaultReadyTimeout)),
+			doc: routeDoc{
+				summary:  "Readiness check: 200 when the database answers, 503 otherwise",
+				response: schemaFor[readiness](),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/version",
+			handler: getVersion,
+			doc:     routeDoc{summary: "Commit, build time and Go version of the running server", response: schemaFor[buildinfo.Info]()},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/event-types",
+			handler: listEventTypes(cfg),
+			doc: routeDoc{
+				summary: "Event types clients may create, with the fields each takes",
+				params:  []openAPIParameter{envelopeParam},
+				response: dataEnvelope(arrayOf(&openAPISchema{
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"type":       {Type: "string", Enum: creatableEventTypes},
+						"required":   arrayOf(&openAPISchema{Type: "string"}),
+						"optional":   arrayOf(&openAPISchema{Type: "string"}),
+						"properties": {Type: "object", Description: "Schema of each field, keyed by field name."},
+					},
+				})),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies",
+			handler: listBabies(store),
+			doc: routeDoc{
+				summary: "List babies",
+				params: []openAPIParameter{
+					includeDeletedParam,
+					queryParam("sort", "Field to order by, defaults to id. Names ignore case.", false, &openAPISchema{
+						Type: "string",
+						Enum: []string{string(BabySortID), string(BabySortName), string(BabySortCreatedAt)},
+					}),
+					queryParam("order", "Sort direction, defaults to asc", false, &openAPISchema{Type: "string", Enum: []string{"asc", "desc"}}),
+					envelopeParam,
+				},
+				response: dataEnvelope(arrayOf(schemaRef("Baby"))),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}",
+			handler: getBaby(store),
+			doc: routeDoc{
+				summary:  "Get a baby",
+				params:   []openAPIParameter{includeDeletedParam},
+				response: dataEnvelope(schemaRef("Baby")),
+			},
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/v1/babies/import",
+			handler: importBaby(store, cfg),
+			doc: routeDoc{
+				summary:  "Create a baby from an export document, all or nothing",
+				body:     schemaRef("BabyExport"),
+				status:   http.StatusCreated,
+				response: dataEnvelope(schemaFor[importResult]()),
+			},
+		},
+		{
+			method:  http.MethodPatch,
+			path:    "/v1/babies/{id}",
+			handler: updateBaby(store),
+			doc: routeDoc{
+				summary:  "Update a baby; omitted fields are kept",
+				body:     schemaFor[updateBabyRequest](),
+				response: dataEnvelope(schemaRef("Baby")),
+			},
+		},
+		{
+			method:  http.MethodDelete,
+			path:    "/v1/babies/{id}",
+			handler: deleteBaby(store),
+			doc:     routeDoc{summary: "Archive a baby, keeping its events", status: http.StatusNoContent},
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/v1/babies/{id}/merge",
+			handler: mergeBabies(store),
+			doc: routeDoc{
+				summary:  "Move a duplicate baby's events and weights to this one and archive the duplicate",
+				body:     schemaFor[mergeBabiesRequest](),
+				response: dataEnvelope(schemaRef("Baby")),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/weights",
+			handler: listWeightEntries(store),
+			doc: routeDoc{
+				summary: "List weight entries",
+				params:  []openAPIParameter{weightUnitParam(), envelopeParam},
+				response: dataEnvelope(arrayOf(&openAPISchema{
+					OneOf: []*openAPISchema{schemaRef("WeightEntry"), schemaRef("WeightEntryLb")},
+				})),
+			},
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/v1/babies/{id}/weights",
+			handler: createWeightEntry(store, cfg),
+			doc: routeDoc{
+				summary:  "Log a weight",
+				params:   []openAPIParameter{queryParam("force", "Log the weight even when it changed implausibly fast since the previous entry", false, &openAPISchema{Type: "boolean"})},
+				body:     schemaFor[createWeightRequest](),
+				status:   http.StatusCreated,
+				response: dataEnvelope(schemaRef("WeightEntry")),
+			},
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/v1/babies/{id}/weights:batch",
+			handler: createWeightEntriesBatch(store, cfg),
+			doc: routeDoc{
+				summary:  "Log up to 1000 weights in one transaction",
+				body:     arrayOf(schemaFor[createWeightRequest]()),
+				status:   http.StatusCreated,
+				response: dataEnvelope(arrayOf(schemaRef("WeightEntry"))),
+			},
+		},
+		{
+			method:  http.MethodDelete,
+			path:    "/v1/babies/{id}/weights/{weightId}",
+			handler: deleteWeightEntry(store),
+			doc:     routeDoc{summary: "Delete a weight entry", status: http.StatusNoContent},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/growth-velocity",
+			handler: getGrowthVelocity(store),
+			doc: routeDoc{
+				summary: "Average daily weight gain in g/day, overall and between consecutive entries; 422 with fewer than two",
+				params: []openAPIParameter{
+					queryParam("from", "First day, inclusive", true, dateParam),
+					queryParam("to", "Last day, inclusive", true, dateParam),
+					tzParam,
+				},
+				response: dataEnvelope(schemaFor[growthVelocity]()),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/weights/latest",
+			handler: getLatestWeight(store),
+			doc: routeDoc{
+				summary: "Most recent weight entry with the change since the previous one",
+				params:  []openAPIParameter{weightUnitParam()},
+				response: dataEnvelope(&openAPISchema{
+					OneOf: []*openAPISchema{schemaRef("LatestWeight"), schemaRef("LatestWeightLb")},
+				}),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/report",
+			handler: getBabyReport(store, "", cfg.reportBranding()),
+			doc: routeDoc{
+				summary:     "Download the baby report as PDF, or as HTML when Accept prefers text/html",
+				params:      []openAPIParameter{weightUnitParam()},
+				contentType: "application/pdf",
+				response:    &openAPISchema{Type: "string", Format: "binary"},
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/report.pdf",
+			handler: getBabyReport(store, reportPDF, cfg.reportBranding()),
+			doc: routeDoc{
+				summary:     "Download the baby report as PDF",
+				params:      []openAPIParameter{weightUnitParam()},
+				contentType: "application/pdf",
+				response:    &openAPISchema{Type: "string", Format: "binary"},
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/report.pdf",
+			handler: getCombinedReportPDF(store, cfg.reportBranding()),
+			doc: routeDoc{
+				summary: "Download one PDF with a page per baby",
+				params: []openAPIParameter{
+					queryParam("baby_id", "Baby to include; repeat for each baby, up to 10", true, &openAPISchema{Type: "integer", Format: "int64"}),
+					weightUnitParam(),
+				},
+				contentType: "application/pdf",
+				response:    &openAPISchema{Type: "string", Format: "binary"},
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/export.json",
+			handler: exportBaby(store),
+			doc:     routeDoc{summary: "Download a full backup of the baby", response: schemaRef("BabyExport")},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/awake-time",
+			handler: getAwakeTime(store),
+			doc: routeDoc{
+				summary: "Awake hours per local day",
+				params: []openAPIParameter{
+					queryParam("from", "First day, inclusive", true, dateParam),
+					queryParam("to", "Last day, inclusive", true, dateParam),
+					tzParam,
+					envelopeParam,
+				},
+				response: dataEnvelope(arrayOf(schemaFor[awakeDay]())),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/activity",
+			handler: getActivity(store),
+			doc: routeDoc{
+				summary: "Number of events of any type per local day, for heatmap calendars",
+				params: []openAPIParameter{
+					queryParam("from", "First day, inclusive", true, dateParam),
+					queryParam("to", "Last day, inclusive", true, dateParam),
+					tzParam,
+					envelopeParam,
+				},
+				response: dataEnvelope(arrayOf(schemaFor[activityDay]())),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/summary",
+			handler: getDailySummary(store),
+			doc: routeDoc{
+				summary: "Diaper, feed and sleep totals per local day, week or month",
+				params: []openAPIParameter{
+					queryParam("from", "First day, inclusive", true, dateParam),
+					queryParam("to", "Last day, inclusive", true, dateParam),
+					tzParam,
+					queryParam("bucket", "Period per row, defaults to day. Weeks start on Monday.", false, &openAPISchema{
+						Type: "string",
+						Enum: []string{string(bucketDay), string(bucketWeek), string(bucketMonth)},
+					}),
+					envelopeParam,
+				},
+				response: dataEnvelope(arrayOf(schemaFor[dailySummary]())),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/today",
+			handler: getTodaySummary(store),
+			doc: routeDoc{
+				summary:  "Diaper, feed and sleep totals for the current local day",
+				params:   []openAPIParameter{tzParam},
+				response: dataEnvelope(schemaFor[dailySummary]()),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/status",
+			handler: getBabyStatus(store),
+			doc:     routeDoc{summary: "Latest feeding, diaper and sleep state", response: dataEnvelope(schemaRef("BabyStatus"))},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/reminders",
+			handler: getReminders(store),
+			doc: routeDoc{
+				summary: "When the next feed is due and whether it is overdue",
+				params: []openAPIParameter{
+					queryParam("interval_minutes", "Minutes between feeds, default 180, max 1440", false, &openAPISchema{Type: "integer"}),
+				},
+				response: dataEnvelope(schemaFor[feedingReminder]()),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/recent-side-balance",
+			handler: getRecentSideBalance(store),
+			doc: routeDoc{
+				summary: "Left/right split over the most recent nursing events",
+				params: []openAPIParameter{
+					queryParam("n", "Number of recent feeds, default 10, max 50", false, &openAPISchema{Type: "integer"}),
+				},
+				response: dataEnvelope(schemaFor[recentSideBalance]()),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/side-minutes",
+			handler: getSideMinutes(store),
+			doc: routeDoc{
+				summary: "Nursing minutes per side over a range of local days",
+				params: []openAPIParameter{
+					queryParam("from", "First day, inclusive", true, dateParam),
+					queryParam("to", "Last day, inclusive", true, dateParam),
+					tzParam,
+				},
+				response: dataEnvelope(schemaFor[sideMinutes]()),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/feeding-intervals",
+			handler: getFeedingIntervals(store),
+			doc: routeDoc{
+				summary: "Average, shortest and longest gap between feeds",
+				params: []openAPIParameter{
+					queryParam("from", "First day, inclusive", true, dateParam),
+					queryParam("to", "Last day, inclusive", true, dateParam),
+					tzParam,
+				},
+				response: dataEnvelope(schemaRef("FeedingIntervals")),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/stats",
+			handler: getBabyStats(store),
+			doc: routeDoc{
+				summary:  "Lifetime event counts per type, average nursing session and longest sleep",
+				response: dataEnvelope(schemaRef("BabyStats")),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/events",
+			handler: listEvents(store),
+			doc: routeDoc{
+				summary: "List events",
+				params: []openAPIParameter{
+					queryParam("type", "Event types to include, repeatable", false, arrayOf(&openAPISchema{Type: "string", Enum: eventTypes})),
+					queryParam("tag", "Only events carrying this tag, repeatable; every tag must match", false, arrayOf(&openAPISchema{Type: "string"})),
+					queryParam("q", "Only events whose notes contain this text, ignoring case", false, &openAPISchema{Type: "string"}),
+					queryParam("limit", "Events per page, default 100, capped at 1000", false, &openAPISchema{Type: "integer"}),
+					queryParam("offset", "Events to skip, from next_offset of the previous page", false, &openAPISchema{Type: "integer"}),
+					queryParam("cursor", "next_cursor of the previous page; cannot be combined with offset", false, &openAPISchema{Type: "string"}),
+					queryParam("with_total", "Also count the matching events across all pages", false, &openAPISchema{Type: "boolean"}),
+					envelopeParam,
+				},
+				response: withPaging(dataEnvelope(arrayOf(schemaRef("Event")))),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/events.csv",
+			handler: exportEventsCSV(store),
+			doc: routeDoc{
+				summary:     "Download all events as CSV",
+				contentType: "text/csv",
+				response:    &openAPISchema{Type: "string"},
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/events.ndjson",
+			handler: exportEventsNDJSON(store),
+			doc: routeDoc{
+				summary:     "Stream all events as newline-delimited JSON, one event per line",
+				contentType: "application/x-ndjson",
+				response:    schemaRef("Event"),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/events/recent",
+			handler: listRecentEvents(store),
+			doc: routeDoc{
+				summary: "List the latest events, newest first",
+				params: []openAPIParameter{
+					queryParam("limit", "Number of events, 20 by default and at most 100", false, &openAPISchema{Type: "integer"}),
+					queryParam("type", "Event types to include, repeatable", false, arrayOf(&openAPISchema{Type: "string", Enum: eventTypes})),
+					envelopeParam,
+				},
+				response: dataEnvelope(arrayOf(schemaRef("Event"))),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/events/count",
+			handler: countEvents(store),
+			doc:     routeDoc{summary: "Count events", response: dataEnvelope(schemaFor[eventCount]())},
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/v1/babies/{id}/events",
+			handler: createEvent(store, cfg),
+			doc: routeDoc{
+				summary: "Create an event",
+				params: []openAPIParameter{
+					queryParam("force", "Create the event even when one of the same type is within the duplicate window, or overlaps this sleep or feed", false, &openAPISchema{Type: "boolean"}),
+					{
+						Name:        "Idempotency-Key",
+						In:          "header",
+						Description: "Retries with the same key return the first event with 200 instead of inserting again",
+						Schema:      &openAPISchema{Type: "string"},
+					},
+				},
+				body:     schemaRef("CreateEventRequest"),
+				formBody: true,
+				status:   http.StatusCreated,
+				response: dataEnvelope(schemaRef("Event")),
+			},
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/v1/babies/{id}/events:batch",
+			handler: createEventsBatch(store, cfg),
+			doc: routeDoc{
+				summary:  "Create up to 1000 events in one transaction",
+				body:     arrayOf(schemaRef("CreateEventRequest")),
+				status:   http.StatusCreated,
+				response: dataEnvelope(arrayOf(schemaRef("Event"))),
+			},
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/v1/babies/{id}/events:import",
+			handler: importEventsNDJSON(store, cfg),
+			doc: routeDoc{
+				summary:         "Import events from JSON Lines, one event per line, keeping the valid ones",
+				body:            schemaRef("CreateEventRequest"),
+				bodyContentType: "application/x-ndjson",
+				response:        dataEnvelope(schemaFor[ndjsonImportResult]()),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/events/{eventId}",
+			handler: getEvent(store),
+			doc:     routeDoc{summary: "Get one event", response: dataEnvelope(schemaRef("Event"))},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/babies/{id}/events/{eventId}/history",
+			handler: getEventHistory(store),
+			doc: routeDoc{
+				summary:  "Who created and changed an event, oldest first",
+				params:   []openAPIParameter{envelopeParam},
+				response: dataEnvelope(arrayOf(schemaRef("EventAuditEntry"))),
+			},
+		},
+		{
+			method:  http.MethodPatch,
+			path:    "/v1/babies/{id}/events/{eventId}",
+			handler: updateEvent(store),
+			doc: routeDoc{
+				summary:  "End a sleep in progress",
+				body:     schemaFor[updateEventRequest](),
+				response: dataEnvelope(schemaRef("Event")),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/profile",
+			handler: getProfile,
+			doc: routeDoc{
+				summary: "Current user profile",
+				response: &openAPISchema{
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"id":    {Type: "string"},
+						"name":  {Type: "string"},
+						"email": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+
+	if cfg.DemoMode {
+		routes = append(routes, route{
+			method:  http.MethodPost,
+			path:    "/v1/babies/{id}/seed-demo",
+			handler: seedDemo(store),
+			doc: routeDoc{
+				summary: "Seed sample events (demo mode only)",
+				status:  http.StatusCreated,
+				response: dataEnvelope(&openAPISchema{
+					Type:       "object",
+					Properties: map[string]*openAPISchema{"seeded": {Type: "integer"}},
+				}),
+			},
+		})
+	}
+
+	return routes
+}
+
+func healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readiness reports whether the store answered and how long it took.
+// Error is only set when it did not.
+type readiness struct {
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// readyz pings the store within timeout. Unlike healthz, which only shows
+// the process is up, it fails while the database is unreachable.
+func readyz(store BabyStore, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		start := time.Now()
+		err := store.Ping(ctx)
+		data := readiness{
+			Status:     "ready",
+			DurationMS: math.Round(float64(time.Since(start).Microseconds())/10) / 100,
+		}
+		if err != nil {
+			logf(r.Context(), "readiness ping failed: %v", err)
+			data.Status = "unavailable"
+			data.Error = "database unreachable"
+			if errors.Is(err, context.DeadlineExceeded) {
+				data.Error = fmt.Sprintf("database did not answer within %s", timeout)
+			}
+			writeJSON(w, http.StatusServiceUnavailable, data)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, data)
+	}
+}
+
+func getVersion(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, buildinfo.Read())
+}
+
+func listBabies(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseBabyFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if filter.Sort, filter.Descending, err = parseBabySort(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := store.ListBabies(r.Context(), filter)
+		if err != nil {
+			writeStoreError(w, r, "list babies", err)
+			return
+		}
+
+		writeList(w, r, data)
+	}
+}
+
+func getBaby(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		filter, err := parseBabyFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := store.GetBaby(r.Context(), babyID, filter)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "get baby", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
+
+// maxBabyNameLength bounds baby names, in characters.
+const maxBabyNameLength = 100
+
+// normalizeBabyName collapses the whitespace of a baby name and checks its
+// length.
+func normalizeBabyName(value string) (string, error) {
+	name := NormalizeText(value, true)
+	if name == "" || utf8.RuneCountInString(name) > maxBabyNameLength {
+		return "", fmt.Errorf("name must be between 1 and %d characters", maxBabyNameLength)
+	}
+	return name, nil
+}
+
+// maxAvatarURLLength bounds avatar URLs, in bytes.
+const maxAvatarURLLength = 2048
+
+// normalizeBabyColor accepts a #rgb or #rrggbb hex color and returns it as
+// lower-case #rrggbb.
+func normalizeBabyColor(value string) (string, error) {
+	color := strings.ToLower(strings.TrimSpace(value))
+	if len(color) == 4 && color[0] == '#' {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	if len(color) != 7 || color[0] != '#' || strings.Trim(color[1:], "0123456789abcdef") != "" {
+		return "", fmt.Errorf("color must be a hex color such as #ff8800, got %q", value)
+	}
+	return color, nil
+}
+
+// normalizeAvatarURL checks that value is an absolute http or https URL.
+func normalizeAvatarURL(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(value) > maxAvatarURLLength {
+		return "", fmt.Errorf("avatar_url must be an http or https URL of at most %d bytes", maxAvatarURLLength)
+	}
+	return value, nil
+}
+
+// normalizeBabyTimezone checks that value names an IANA time zone.
+func normalizeBabyTimezone(value string) (string, error) {
+	loc, err := parseLocation(value)
+	if err != nil {
+		return "", errors.New("timezone must be a valid IANA time zone, such as America/New_York")
+	}
+	return loc.String(), nil
+}
+
+// normalizeOptionalBabyField applies normalize to a set value. A blank
+// value becomes "", which clears the field on update.
+func normalizeOptionalBabyField(value *string, normalize func(string) (string, error)) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if strings.TrimSpace(*value) == "" {
+		empty := ""
+		return &empty, nil
+	}
+	normalized, err := normalize(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+type updateBabyRequest struct {
+	Name *string `json:"name"`
+	// Color, AvatarURL and Timezone are cleared by an empty string.
+	Color     *string `json:"color"`
+	AvatarURL *string `json:"avatar_url"`
+	Timezone  *string `json:"timezone"`
+}
+
+func updateBaby(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		var req updateBabyRequest
+		if err := decodeJSONBody(r.Body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var input UpdateBabyInput
+		if req.Name != nil {
+			name, err := normalizeBabyName(*req.Name)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			input.Name = &name
+		}
+		if input.Color, err = normalizeOptionalBabyField(req.Color, normalizeBabyColor); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if input.AvatarURL, err = normalizeOptionalBabyField(req.AvatarURL, normalizeAvatarURL); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if input.Timezone, err = normalizeOptionalBabyField(req.Timezone, normalizeBabyTimezone); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := store.UpdateBaby(r.Context(), babyID, input)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "update baby", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
+
+func deleteBaby(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		err = store.DeleteBaby(r.Context(), babyID)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "delete baby", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type mergeBabiesRequest struct {
+	SourceID int64 `json:"source_id"`
+}
+
+// mergeBabies folds a profile created by accident, source_id, into the
+// baby in the path, which keeps its own name and settings.
+func mergeBabies(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		var req mergeBabiesRequest
+		if err := decodeJSONBody(r.Body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.SourceID <= 0 {
+			http.Error(w, "source_id must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		if req.SourceID == babyID {
+			http.Error(w, "a baby cannot be merged into itself", http.StatusBadRequest)
+			return
+		}
+
+		baby, err := store.MergeBabies(r.Context(), babyID, req.SourceID)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "merge babies", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": baby})
+	}
+}
+
+func deleteWeightEntry(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		weightID, err := parseID(r.PathValue("weightId"))
+		if err != nil {
+			http.Error(w, "invalid weight id", http.StatusBadRequest)
+			return
+		}
+
+		err = store.DeleteWeightEntry(r.Context(), babyID, weightID)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "delete weight entry", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// parseBabyFilter reads the include_deleted query parameter.
+func parseBabyFilter(r *http.Request) (BabyFilter, error) {
+	value := strings.TrimSpace(r.URL.Query().Get("include_deleted"))
+	if value == "" {
+		return BabyFilter{}, nil
+	}
+	includeDeleted, err := strconv.ParseBool(value)
+	if err != nil {
+		return BabyFilter{}, errors.New("include_deleted must be true or false")
+	}
+	return BabyFilter{IncludeDeleted: includeDeleted}, nil
+}
+
+// parseBabySort reads the sort and order query parameters of GET
+// /v1/babies, which default to id and asc. Names are compared without
+// regard to case.
+func parseBabySort(r *http.Request) (BabySort, bool, error) {
+	query := r.URL.Query()
+
+	sort := BabySort(strings.ToLower(strings.TrimSpace(query.Get("sort"))))
+	if sort == "" {
+		sort = BabySortID
+	}
+	if !slices.Contains(babySorts, sort) {
+		return "", false, errors.New("sort must be one of id, name, created_at")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(query.Get("order"))) {
+	case "", "asc":
+		return sort, false, nil
+	case "desc":
+		return sort, true, nil
+	default:
+		return "", false, errors.New("order must be asc or desc")
+	}
+}
+
+func listWeightEntries(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		unit, err := parseWeightUnit(r.URL.Query().Get("unit"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := store.ListWeightEntries(r.Context(), babyID)
+		if err != nil {
+			writeStoreError(w, r, "list weight entries", err)
+			return
+		}
+
+		if unit == unitPounds {
+			converted := make([]weightEntryLb, 0, len(data))
+			for _, entry := range data {
+				converted = append(converted, weightEntryLb{
+					ID:         entry.ID,
+					OccurredAt: entry.OccurredAt,
+					WeightLb:   unit.fromKilograms(entry.WeightKg),
+				})
+			}
+			writeList(w, r, converted)
+			return
+		}
+
+		writeList(w, r, data)
+	}
+}
+
+func getLatestWeight(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		unit, err := parseWeightUnit(r.URL.Query().Get("unit"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := store.LatestWeight(r.Context(), babyID)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "latest weight", err)
+			return
+		}
+
+		if unit == unitPounds {
+			converted := latestWeightLb{
+				OccurredAt: data.OccurredAt,
+				WeightLb:   unit.fromKilograms(data.WeightKg),
+			}
+			if data.DeltaKg != nil {
+				delta := unit.fromKilograms(*data.DeltaKg)
+				converted.DeltaLb = &delta
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"data": converted})
+			return
+		}
+
+		if data.DeltaKg != nil {
+			// Subtracting stored kilograms leaves float noise; grams are
+			// as precise as any scale.
+			*data.DeltaKg = math.Round(*data.DeltaKg*1000) / 1000
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
+
+func getBabyStatus(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		data, err := store.GetBabyStatus(r.Context(), babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby status", err)
+			return
+		}
+		if data.AsleepSince != nil {
+			elapsed := max(int(time.Since(*data.AsleepSince).Minutes()), 0)
+			data.ElapsedMinutes = &elapsed
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using
+// weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// babyReportSection is one baby's part of a report.
+type babyReportSection struct {
+	Baby    Baby
+	Weights []WeightEntry
+}
+
+// buildBabyReportPDF lays out each section on its own page, headed by the
+// branding title and with its footer at the bottom of every page.
+func buildBabyReportPDF(sections []babyReportSection, unit weightUnit, branding reportBranding) ([]byte, error) {
+	generatedAt := time.Now().UTC().Format(time.RFC3339)
+
+	pages := make([][]string, 0, len(sections))
+	for _, section := range sections {
+		lines := make([]string, 0, len(section.Weights)+5)
+		lines = append(lines, branding.Title)
+		lines = append(lines, fmt.Sprintf("Baby: %s (ID %d)", section.Baby.Name, section.Baby.ID))
+		lines = append(lines, fmt.Sprintf("Generated at: %s", generatedAt))
+		lines = append(lines, "Weight entries:")
+		if len(section.Weights) == 0 {
+			lines = append(lines, "- none")
+		} else {
+			for _, entry := range section.Weights {
+				lines = append(lines, fmt.Sprintf("- %s: %s", entry.OccurredAt.UTC().Format(time.RFC3339), unit.format(entry.WeightKg)))
+			}
+		}
+		pages = append(pages, lines)
+	}
+
+	return renderSimplePDF(pages, branding.Footer)
+}
+
+// renderSimplePDF writes one page of text lines per element of pages, and
+// footer, when set, in small print at the bottom of each page. Objects
+// 1-3 are the catalog, the page tree and the font; each page then adds a
+// page object and its content stream.
+func renderSimplePDF(pages [][]string, footer string) ([]byte, error) {
+	const firstPageObject = 4
+
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPageObject+2*i))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Count %d /Kids [%s] >>", len(pages), strings.Join(kids, " ")),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+	for i, lines := range pages {
+		var content strings.Builder
+		content.WriteString("BT\n/F1 12 Tf\n72 760 Td\n")
+		for j, line := range lines {
+			escaped := escapePDFText(encodeWinAnsi(line))
+			if j == 0 {
+				content.WriteString(fmt.Sprintf("(%s) Tj\n", escaped))
+			} else {
+				content.WriteString(fmt.Sprintf("0 -18 Td (%s) Tj\n", escaped))
+			}
+		}
+		content.WriteString("ET\n")
+		if footer != "" {
+			content.WriteString(fmt.Sprintf("BT\n/F1 9 Tf\n72 40 Td\n(%s) Tj\nET\n", escapePDFText(encodeWinAnsi(footer))))
+		}
+
+		contentBody := content.String()
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", firstPageObject+2*i+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(contentBody), contentBody),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, 0, len(objects)+1)
+	offsets = append(offsets, 0)
+	for i, obj := range objects {
+		offsets = append(offsets, buf.Len())
+		buf.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, obj))
+	}
+
+	xrefOffset := buf.Len()
+	buf.WriteString(fmt.Sprintf("xref\n0 %d\n", len(objects)+1))
+	buf.WriteString("0000000000 65535 f \n")
+	for i := 1; i < len(offsets); i++ {
+		buf.WriteString(fmt.Sprintf("%010d 00000 n \n", offsets[i]))
+	}
+	buf.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset))
+
+	return buf.Bytes(), nil
+}
+
+// encodeWinAnsi converts text to the WinAnsiEncoding used by the report
+// font. That covers Western European names such as "Zoë" or "Ágata";
+// anything else, e.g. "日向", comes out as question marks, since the
+// built-in Helvetica font has no glyphs for it.
+func encodeWinAnsi(text string) string {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		out = append(out, b)
+	}
+	return string(out)
+}
+
+// escapePDFText escapes input for a PDF literal string. Besides the
+// delimiters, line breaks and every byte outside printable ASCII are
+// escaped, so user text can neither end the string nor inject operators.
+func escapePDFText(input string) string {
+	var out strings.Builder
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '\\' || c == '(' || c == ')':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&out, `\%03o`, c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+const (
+	defaultEventsLimit = 100
+	maxEventsLimit     = 1000
+
+	defaultRecentEventsLimit = 20
+	maxRecentEventsLimit     = 100
+)
+
+// listEvents pages through a baby's events in occurred_at order. A page
+// holds limit events, 100 by default; larger limits are capped at 1000
+// rather than rejected, so no request reads an unbounded history.
+// next_cursor resumes after the last event of the page and should be
+// preferred for feeds, since next_offset shifts when older events are
+// logged late. Both are null on the last page; next_offset is also null
+// when the page was requested by cursor. with_total=true adds the number
+// of matching events across all pages, at the cost of a second query.
+func listEvents(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		types, err := parseEventTypes(r.URL.Query()["type"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tags, err := parseTags(r.URL.Query()["tag"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultEventsLimit
+		if value := strings.TrimSpace(r.URL.Query().Get("limit")); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+		limit = min(limit, maxEventsLimit)
 
-type Event struct {
-	ID         int64           `json:"id"`
-	BabyID     int64           `json:"baby_id"`
-	Type       string          `json:"type"`
-	OccurredAt time.Time       `json:"occurred_at"`
-	Details    json.RawMessage `json:"details"`
-}
+		offset := 0
+		if value := strings.TrimSpace(r.URL.Query().Get("offset")); value != "" {
+			offset, err = strconv.Atoi(value)
+			if err != nil || offset < 0 {
+				http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+		}
 
-type CreateEventInput struct {
-	BabyID     int64
-	Type       string
-	OccurredAt time.Time
-	Details    json.RawMessage
-}
+		var after *EventCursor
+		if value := strings.TrimSpace(r.URL.Query().Get("cursor")); value != "" {
+			if offset > 0 {
+				http.Error(w, "cursor and offset cannot be combined", http.StatusBadRequest)
+				return
+			}
+			cursor, err := decodeEventCursor(value)
+			if err != nil {
+				http.Error(w, "invalid cursor", http.StatusBadRequest)
+				return
+			}
+			after = &cursor
+		}
 
-type WeightEntry struct {
-	OccurredAt time.Time `json:"occurred_at"`
-	WeightKg   float64   `json:"weight_kg"`
-}
+		withTotal := false
+		if value := strings.TrimSpace(r.URL.Query().Get("with_total")); value != "" {
+			withTotal, err = strconv.ParseBool(value)
+			if err != nil {
+				http.Error(w, "with_total must be true or false", http.StatusBadRequest)
+				return
+			}
+		}
 
-type BabyStore interface {
-	ListBabies(ctx context.Context) ([]Baby, error)
-	CreateEvent(ctx context.Context, input CreateEventInput) (Event, error)
-	ListWeightEntries(ctx context.Context, babyID int64) ([]WeightEntry, error)
-}
+		// One extra row tells whether another page follows.
+		filter := EventFilter{
+			Types:  types,
+			Tags:   tags,
+			Notes:  NormalizeText(r.URL.Query().Get("q"), true),
+			Limit:  limit + 1,
+			Offset: offset,
+			After:  after,
+		}
+		data, err := store.ListEvents(r.Context(), babyID, filter)
+		if err != nil {
+			writeStoreError(w, r, "list events", err)
+			return
+		}
 
-// NewRouter creates the HTTP router for the Baby Tracker API.
-func NewRouter(store BabyStore) http.Handler {
-	mux := http.NewServeMux()
+		var (
+			nextOffset *int
+			nextCursor *string
+		)
+		if len(data) > limit {
+			data = data[:limit]
+			last := data[len(data)-1]
+			cursor := encodeEventCursor(EventCursor{OccurredAt: last.OccurredAt, ID: last.ID})
+			nextCursor = &cursor
+			if after == nil {
+				next := offset + limit
+				nextOffset = &next
+			}
+		}
 
-	mux.HandleFunc("GET /healthz", healthz)
-	mux.HandleFunc("GET /v1/babies", listBabies(store))
-	mux.HandleFunc("GET /v1/babies/{id}/weights", listWeightEntries(store))
-	mux.HandleFunc("GET /v1/babies/{id}/report.pdf", getBabyReportPDF(store))
-	mux.HandleFunc("POST /v1/babies/{id}/events", createEvent(store))
-	mux.HandleFunc("GET /v1/profile", getProfile)
+		fields := map[string]any{"next_offset": nextOffset, "next_cursor": nextCursor}
+		if withTotal {
+			total, err := store.CountEvents(r.Context(), babyID, EventFilter{Types: filter.Types, Tags: filter.Tags, Notes: filter.Notes})
+			if err != nil {
+				writeStoreError(w, r, "count events", err)
+				return
+			}
+			fields["total"] = total
+		}
 
-	return mux
+		writeListPage(w, r, data, fields)
+	}
 }
 
-func healthz(w http.ResponseWriter, _ *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+// encodeEventCursor turns a sort key into an opaque cursor token. Clients
+// must not rely on its layout.
+func encodeEventCursor(cursor EventCursor) string {
+	key := cursor.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
 }
 
-func listBabies(store BabyStore) http.HandlerFunc {
+func decodeEventCursor(token string) (EventCursor, error) {
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return EventCursor{}, err
+	}
+	occurredAt, id, ok := strings.Cut(string(key), "|")
+	if !ok {
+		return EventCursor{}, errors.New("malformed cursor")
+	}
+	var cursor EventCursor
+	if cursor.OccurredAt, err = time.Parse(time.RFC3339Nano, occurredAt); err != nil {
+		return EventCursor{}, err
+	}
+	if cursor.ID, err = parseID(id); err != nil {
+		return EventCursor{}, err
+	}
+	return cursor, nil
+}
+
+type eventCount struct {
+	Count int64 `json:"count"`
+}
+
+// listRecentEvents lists a baby's latest events, newest first, for feeds
+// that only show the last few and need no paging. limit defaults to 20
+// and is capped at 100 like listEvents caps its pages. It is its own route
+// because /events?limit= already pages oldest first by cursor, and
+// flipping that order would break clients walking those pages.
+func listRecentEvents(store BabyStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		data, err := store.ListBabies(r.Context())
+		babyID, err := parseID(r.PathValue("id"))
 		if err != nil {
-			log.Printf("list babies failed: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
 			return
 		}
 
-		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+		types, err := parseEventTypes(r.URL.Query()["type"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultRecentEventsLimit
+		if value := strings.TrimSpace(r.URL.Query().Get("limit")); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		data, err := store.ListEvents(r.Context(), babyID, EventFilter{
+			Types:  types,
+			Limit:  min(limit, maxRecentEventsLimit),
+			Newest: true,
+		})
+		if err != nil {
+			writeStoreError(w, r, "list recent events", err)
+			return
+		}
+
+		writeList(w, r, data)
 	}
 }
 
-func listWeightEntries(store BabyStore) http.HandlerFunc {
+func countEvents(store BabyStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		babyID, err := parseID(r.PathValue("id"))
 		if err != nil {
@@ -83,18 +1805,120 @@ func listWeightEntries(store BabyStore) http.HandlerFunc {
 			return
 		}
 
-		data, err := store.ListWeightEntries(r.Context(), babyID)
+		count, err := store.CountEvents(r.Context(), babyID, EventFilter{})
 		if err != nil {
-			log.Printf("list weight entries failed: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeStoreError(w, r, "count events", err)
 			return
 		}
 
-		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+		writeJSON(w, http.StatusOK, map[string]any{"data": eventCount{Count: count}})
+	}
+}
+
+// parseEventTypes normalizes repeated type query values, rejecting any
+// type the API does not store.
+func parseEventTypes(values []string) ([]string, error) {
+	types := make([]string, 0, len(values))
+	for _, value := range values {
+		eventType := normalizeKeyword(value)
+		if !slices.Contains(eventTypes, eventType) {
+			return nil, fmt.Errorf("type must be one of %s", strings.Join(eventTypes, ", "))
+		}
+		if !slices.Contains(types, eventType) {
+			types = append(types, eventType)
+		}
+	}
+	return types, nil
+}
+
+// maxEventTags and maxTagLength bound the tags on one event.
+const (
+	maxEventTags = 10
+	maxTagLength = 32
+)
+
+// parseTags normalizes tags to trimmed lower case and drops duplicates.
+func parseTags(values []string) ([]string, error) {
+	tags := make([]string, 0, len(values))
+	for _, value := range values {
+		tag := normalizeKeyword(value)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("tags must be between 1 and %d characters", maxTagLength)
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxEventTags {
+		return nil, fmt.Errorf("at most %d tags are allowed per event", maxEventTags)
+	}
+	return tags, nil
+}
+
+type createEventRequest struct {
+	Type            string   `json:"type"`
+	OccurredAt      string   `json:"occurred_at"`
+	StartAt         string   `json:"start_at"`
+	EndAt           string   `json:"end_at"`
+	Side            string   `json:"side"`
+	Kind            string   `json:"kind"`
+	DurationMinutes int      `json:"duration_minutes"`
+	Notes           string   `json:"notes"`
+	Tags            []string `json:"tags"`
+}
+
+// errUnsupportedMediaType is returned for request bodies in a content type
+// the endpoint does not read.
+var errUnsupportedMediaType = errors.New("Content-Type must be application/json or application/x-www-form-urlencoded")
+
+// decodeCreateEventRequest reads a JSON body, the default when there is no
+// Content-Type, or a form-encoded one for integrations that cannot send
+// JSON. Form fields take the JSON names, and tags may repeat.
+func decodeCreateEventRequest(r *http.Request) (createEventRequest, error) {
+	var req createEventRequest
+
+	mediaType := "application/json"
+	if header := r.Header.Get("Content-Type"); header != "" {
+		parsed, _, err := mime.ParseMediaType(header)
+		if err != nil {
+			return req, errUnsupportedMediaType
+		}
+		mediaType = parsed
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		err := decodeJSONBody(r.Body, &req)
+		return req, err
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return req, errors.New("invalid form body")
+		}
+		form := r.PostForm
+		req = createEventRequest{
+			Type:       form.Get("type"),
+			OccurredAt: form.Get("occurred_at"),
+			StartAt:    form.Get("start_at"),
+			EndAt:      form.Get("end_at"),
+			Side:       form.Get("side"),
+			Kind:       form.Get("kind"),
+			Notes:      form.Get("notes"),
+			Tags:       form["tags"],
+		}
+		if value := strings.TrimSpace(form.Get("duration_minutes")); value != "" {
+			minutes, err := strconv.Atoi(value)
+			if err != nil {
+				return req, errors.New("duration_minutes must be a whole number")
+			}
+			req.DurationMinutes = minutes
+		}
+		return req, nil
+	default:
+		return req, errUnsupportedMediaType
 	}
 }
 
-func getBabyReportPDF(store BabyStore) http.HandlerFunc {
+func createEvent(store BabyStore, cfg Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		babyID, err := parseID(r.PathValue("id"))
 		if err != nil {
@@ -102,154 +1926,305 @@ func getBabyReportPDF(store BabyStore) http.HandlerFunc {
 			return
 		}
 
-		babies, err := store.ListBabies(r.Context())
+		req, err := decodeCreateEventRequest(r)
+		if errors.Is(err, errUnsupportedMediaType) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
 		if err != nil {
-			log.Printf("list babies for report failed: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		var baby *Baby
-		for i := range babies {
-			if babies[i].ID == babyID {
-				baby = &babies[i]
-				break
-			}
-		}
-		if baby == nil {
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		input, err := buildCreateEventInput(babyID, req, cfg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		weights, err := store.ListWeightEntries(r.Context(), babyID)
+		force, err := parseForce(r)
 		if err != nil {
-			log.Printf("list weight entries for report failed: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, fmt.Sprintf("Idempotency-Key must not exceed %d characters", maxIdempotencyKeyLength), http.StatusBadRequest)
+			return
+		}
+
+		// A replayed Idempotency-Key would find its own event, so the
+		// checks below only run for the first use of a key.
+		replay := false
+		if key != "" {
+			_, err := store.GetIdempotentEvent(r.Context(), babyID, key)
+			switch {
+			case err == nil:
+				replay = true
+			case !errors.Is(err, ErrNotFound):
+				writeStoreError(w, r, "get idempotent event", err)
+				return
+			}
+		}
+
+		if cfg.DuplicateWindow > 0 && !force && !replay {
+			existing, err := store.FindNearbyEvent(r.Context(), babyID, input.Type, input.OccurredAt, cfg.DuplicateWindow)
+			switch {
+			case err == nil:
+				msg := fmt.Sprintf("%s event %d is within %s of this one; set force=true to create it anyway", existing.Type, existing.ID, cfg.DuplicateWindow)
+				http.Error(w, msg, http.StatusConflict)
+				return
+			case !errors.Is(err, ErrNotFound):
+				writeStoreError(w, r, "find nearby event", err)
+				return
+			}
+		}
+
+		// Two sleeps or two feeds cannot happen at once, so an overlap is
+		// a forgotten end_at or a mistyped time. Replays are skipped as
+		// above, since they would overlap their own event.
+		if start, end, ok := eventSession(input); ok && !force && !replay {
+			existing, err := store.FindOverlappingEvent(r.Context(), babyID, input.Type, start, end)
+			switch {
+			case err == nil:
+				msg := fmt.Sprintf("%s event %d overlaps this one; set force=true to create it anyway", existing.Type, existing.ID)
+				http.Error(w, msg, http.StatusConflict)
+				return
+			case !errors.Is(err, ErrNotFound):
+				writeStoreError(w, r, "find overlapping event", err)
+				return
+			}
+		}
+
+		if key == "" {
+			event, err := store.CreateEvent(r.Context(), input)
+			if err != nil {
+				writeCreateEventError(w, r, "create event", err)
+				return
+			}
+
+			w.Header().Set("Location", eventLocation(cfg, event))
+			writeJSON(w, http.StatusCreated, map[string]any{"data": event})
 			return
 		}
 
-		pdf, err := buildBabyReportPDF(*baby, weights)
+		// A retried request gets the original event back with 200, so
+		// clients can tell a replay from a new insert.
+		event, created, err := store.CreateEventIdempotent(r.Context(), key, input)
 		if err != nil {
-			log.Printf("build baby report pdf failed: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeCreateEventError(w, r, "create event", err)
 			return
 		}
 
-		filename := fmt.Sprintf("baby-report-%d.pdf", babyID)
-		w.Header().Set("Content-Type", "application/pdf")
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write(pdf)
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		w.Header().Set("Location", eventLocation(cfg, event))
+		writeJSON(w, status, map[string]any{"data": event})
 	}
 }
 
-func buildBabyReportPDF(baby Baby, entries []WeightEntry) ([]byte, error) {
-	lines := make([]string, 0, len(entries)+5)
-	lines = append(lines, "Baby Tracker Report")
-	lines = append(lines, fmt.Sprintf("Baby: %s (ID %d)", baby.Name, baby.ID))
-	lines = append(lines, fmt.Sprintf("Generated at: %s", time.Now().UTC().Format(time.RFC3339)))
-	lines = append(lines, "Weight entries:")
-	if len(entries) == 0 {
-		lines = append(lines, "- none")
-	} else {
-		for _, entry := range entries {
-			lines = append(lines, fmt.Sprintf("- %s: %.2f kg", entry.OccurredAt.UTC().Format(time.RFC3339), entry.WeightKg))
-		}
+// eventSession returns the span of a sleep or nursing input as
+// FindOverlappingEvent compares it. ok is false for other event types.
+func eventSession(input CreateEventInput) (start time.Time, end *time.Time, ok bool) {
+	var details struct {
+		EndAt           *time.Time `json:"end_at"`
+		DurationMinutes int        `json:"duration_minutes"`
+	}
+	if err := json.Unmarshal(input.Details, &details); err != nil {
+		return time.Time{}, nil, false
 	}
 
-	return renderSimplePDF(lines)
+	switch input.Type {
+	case "sleep":
+		return input.OccurredAt, details.EndAt, true
+	case "nursing":
+		end := input.OccurredAt.Add(time.Duration(details.DurationMinutes) * time.Minute)
+		return input.OccurredAt, &end, true
+	default:
+		return time.Time{}, nil, false
+	}
 }
 
-func renderSimplePDF(lines []string) ([]byte, error) {
-	var content strings.Builder
-	content.WriteString("BT\n/F1 12 Tf\n72 760 Td\n")
-	for i, line := range lines {
-		escaped := escapePDFText(line)
-		if i == 0 {
-			content.WriteString(fmt.Sprintf("(%s) Tj\n", escaped))
-		} else {
-			content.WriteString(fmt.Sprintf("0 -18 Td (%s) Tj\n", escaped))
-		}
+// parseForce reads the force query parameter, which overrides a
+// plausibility check that would otherwise reject a write.
+func parseForce(r *http.Request) (bool, error) {
+	value := strings.TrimSpace(r.URL.Query().Get("force"))
+	if value == "" {
+		return false, nil
 	}
-	content.WriteString("ET\n")
-
-	contentBody := content.String()
-	objects := []string{
-		"<< /Type /Catalog /Pages 2 0 R >>",
-		"<< /Type /Pages /Count 1 /Kids [3 0 R] >>",
-		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
-		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
-		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(contentBody), contentBody),
+	force, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("force must be true or false")
 	}
+	return force, nil
+}
 
-	var buf bytes.Buffer
-	buf.WriteString("%PDF-1.4\n")
-	offsets := make([]int, 0, len(objects)+1)
-	offsets = append(offsets, 0)
-	for i, obj := range objects {
-		offsets = append(offsets, buf.Len())
-		buf.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, obj))
-	}
+// eventLocation is the URL path of an event, including the router prefix.
+func eventLocation(cfg Config, event Event) string {
+	return fmt.Sprintf("%s/v1/babies/%d/events/%d", cfg.PathPrefix, event.BabyID, event.ID)
+}
 
-	xrefOffset := buf.Len()
-	buf.WriteString(fmt.Sprintf("xref\n0 %d\n", len(objects)+1))
-	buf.WriteString("0000000000 65535 f \n")
-	for i := 1; i < len(offsets); i++ {
-		buf.WriteString(fmt.Sprintf("%010d 00000 n \n", offsets[i]))
+// writeCreateEventError answers a failed CreateEvent or CreateEvents call.
+// A foreign key violation means the baby does not exist.
+func writeCreateEventError(w http.ResponseWriter, r *http.Request, action string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, ErrConstraintViolation):
+		http.Error(w, ErrConstraintViolation.Error(), http.StatusBadRequest)
+	default:
+		writeStoreError(w, r, action, err)
 	}
-	buf.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset))
+}
 
-	return buf.Bytes(), nil
+// writeStoreError logs a failed store call and answers 504 when it timed
+// out, 500 otherwise.
+func writeStoreError(w http.ResponseWriter, r *http.Request, action string, err error) {
+	logf(r.Context(), "%s failed: %v", action, err)
+	if errors.Is(err, ErrTimeout) {
+		http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 }
 
-func escapePDFText(input string) string {
-	replacer := strings.NewReplacer("\\", "\\\\", "(", "\\(", ")", "\\)")
-	return replacer.Replace(input)
+type updateEventRequest struct {
+	EndAt string `json:"end_at"`
 }
 
-type createEventRequest struct {
-	Type            string `json:"type"`
-	OccurredAt      string `json:"occurred_at"`
-	StartAt         string `json:"start_at"`
-	EndAt           string `json:"end_at"`
-	Side            string `json:"side"`
-	DurationMinutes int    `json:"duration_minutes"`
-	Notes           string `json:"notes"`
+func getEvent(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+		eventID, err := parseID(r.PathValue("eventId"))
+		if err != nil {
+			http.Error(w, "invalid event id", http.StatusBadRequest)
+			return
+		}
+
+		event, err := store.GetEvent(r.Context(), babyID, eventID)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "get event", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": event})
+	}
 }
 
-func createEvent(store BabyStore) http.HandlerFunc {
+func getEventHistory(store BabyStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		babyID, err := parseID(r.PathValue("id"))
 		if err != nil {
 			http.Error(w, "invalid baby id", http.StatusBadRequest)
 			return
 		}
+		eventID, err := parseID(r.PathValue("eventId"))
+		if err != nil {
+			http.Error(w, "invalid event id", http.StatusBadRequest)
+			return
+		}
 
-		var req createEventRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "invalid json body", http.StatusBadRequest)
+		data, err := store.EventHistory(r.Context(), babyID, eventID)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeStoreError(w, r, "event history", err)
 			return
 		}
 
-		input, err := buildCreateEventInput(babyID, req)
+		writeList(w, r, data)
+	}
+}
+
+// updateEvent currently supports a single edit: closing a sleep in
+// progress by setting its end_at.
+func updateEvent(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+		eventID, err := parseID(r.PathValue("eventId"))
 		if err != nil {
+			http.Error(w, "invalid event id", http.StatusBadRequest)
+			return
+		}
+
+		var req updateEventRequest
+		if err := decodeJSONBody(r.Body, &req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		event, err := store.CreateEvent(r.Context(), input)
+		endAt, err := parseTimestamp(req.EndAt)
 		if err != nil {
-			log.Printf("create event failed: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			http.Error(w, "end_at is required", http.StatusBadRequest)
+			return
+		}
+
+		// Ending a sleep only shortens it, since it ran on indefinitely
+		// while open, so it cannot create an overlap that creating it did
+		// not already have.
+
+		event, err := store.EndSleep(r.Context(), babyID, eventID, endAt)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		case errors.Is(err, ErrSleepEnded):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case errors.Is(err, ErrSleepEndBeforeStart):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			writeStoreError(w, r, "end sleep", err)
 			return
 		}
 
-		writeJSON(w, http.StatusCreated, map[string]any{"data": event})
+		writeJSON(w, http.StatusOK, map[string]any{"data": event})
 	}
 }
 
-func buildCreateEventInput(babyID int64, req createEventRequest) (CreateEventInput, error) {
-	switch strings.ToLower(strings.TrimSpace(req.Type)) {
+// buildCreateEventInput validates req and builds its Details. When
+// cfg.EventTypes is not nil, only those types are accepted, and the
+// fields cfg.RequiredFields lists must be set.
+func buildCreateEventInput(babyID int64, req createEventRequest, cfg Config) (CreateEventInput, error) {
+	eventType := normalizeKeyword(req.Type)
+	if cfg.EventTypes != nil && slices.Contains(creatableEventTypes, eventType) && !slices.Contains(cfg.EventTypes, eventType) {
+		return CreateEventInput{}, fmt.Errorf("event type %s is disabled", eventType)
+	}
+
+	tags, err := parseTags(req.Tags)
+	if err != nil {
+		return CreateEventInput{}, err
+	}
+
+	spec, ok := cfg.eventTypeSpec(eventType)
+	if !ok {
+		return CreateEventInput{}, errors.New("type must be diaper, nursing, or sleep")
+	}
+	for _, field := range spec.Required {
+		if !req.hasField(field) {
+			return CreateEventInput{}, fmt.Errorf("%s is required for %s events", field, eventType)
+		}
+	}
+
+	switch eventType {
 	case "diaper":
 		occurredAt, err := parseTimestamp(req.OccurredAt)
 		if err != nil {
@@ -257,8 +2232,14 @@ func buildCreateEventInput(babyID int64, req createEventRequest) (CreateEventInp
 		}
 
 		details := map[string]any{}
-		if strings.TrimSpace(req.Notes) != "" {
-			details["notes"] = req.Notes
+		if notes := NormalizeText(req.Notes, false); notes != "" {
+			details["notes"] = notes
+		}
+		if kind := normalizeKeyword(req.Kind); kind != "" {
+			if !slices.Contains(diaperKinds, kind) {
+				return CreateEventInput{}, errors.New("kind must be wet, dirty, or mixed for diaper events")
+			}
+			details["kind"] = kind
 		}
 
 		payload, err := json.Marshal(details)
@@ -271,6 +2252,7 @@ func buildCreateEventInput(babyID int64, req createEventRequest) (CreateEventInp
 			Type:       "diaper",
 			OccurredAt: occurredAt,
 			Details:    payload,
+			Tags:       tags,
 		}, nil
 	case "nursing":
 		occurredAt, err := parseTimestamp(req.OccurredAt)
@@ -278,7 +2260,7 @@ func buildCreateEventInput(babyID int64, req createEventRequest) (CreateEventInp
 			return CreateEventInput{}, errors.New("occurred_at is required for nursing events")
 		}
 
-		side := strings.ToLower(strings.TrimSpace(req.Side))
+		side := normalizeKeyword(req.Side)
 		if side != "left" && side != "right" {
 			return CreateEventInput{}, errors.New("side must be left or right for nursing events")
 		}
@@ -299,24 +2281,31 @@ func buildCreateEventInput(babyID int64, req createEventRequest) (CreateEventInp
 			Type:       "nursing",
 			OccurredAt: occurredAt,
 			Details:    payload,
+			Tags:       tags,
 		}, nil
 	case "sleep":
 		startAt, err := parseTimestamp(req.StartAt)
 		if err != nil {
 			return CreateEventInput{}, errors.New("start_at is required for sleep events")
 		}
-		endAt, err := parseTimestamp(req.EndAt)
-		if err != nil {
-			return CreateEventInput{}, errors.New("end_at is required for sleep events")
+
+		// end_at may be omitted for a sleep in progress; it is set later
+		// through PATCH /v1/babies/{id}/events/{eventId}.
+		details := map[string]any{
+			"start_at": startAt.Format(time.RFC3339),
 		}
-		if !endAt.After(startAt) {
-			return CreateEventInput{}, errors.New("end_at must be after start_at for sleep events")
+		if strings.TrimSpace(req.EndAt) != "" {
+			endAt, err := parseTimestamp(req.EndAt)
+			if err != nil {
+				return CreateEventInput{}, errors.New("end_at must be an RFC3339 timestamp for sleep events")
+			}
+			if !endAt.After(startAt) {
+				return CreateEventInput{}, errors.New("end_at must be after start_at for sleep events")
+			}
+			details["end_at"] = endAt.Format(time.RFC3339)
 		}
 
-		payload, err := json.Marshal(map[string]any{
-			"start_at": startAt.Format(time.RFC3339),
-			"end_at":   endAt.Format(time.RFC3339),
-		})
+		payload, err := json.Marshal(details)
 		if err != nil {
 			return CreateEventInput{}, errors.New("failed to encode details")
 		}
@@ -326,6 +2315,7 @@ func buildCreateEventInput(babyID int64, req createEventRequest) (CreateEventInp
 			Type:       "sleep",
 			OccurredAt: startAt,
 			Details:    payload,
+			Tags:       tags,
 		}, nil
 	default:
 		return CreateEventInput{}, errors.New("type must be diaper, nursing, or sleep")
@@ -340,12 +2330,21 @@ func parseTimestamp(value string) (time.Time, error) {
 	return time.Parse(time.RFC3339, value)
 }
 
+// parseID reads a record id. Ids are positive, so zero and negative values
+// are rejected rather than looked up.
 func parseID(value string) (int64, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
 		return 0, errors.New("id required")
 	}
-	return strconv.ParseInt(value, 10, 64)
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, errors.New("id must be positive")
+	}
+	return id, nil
 }
 
 func getProfile(w http.ResponseWriter, _ *http.Request) {
@@ -356,9 +2355,61 @@ func getProfile(w http.ResponseWriter, _ *http.Request) {
 	})
 }
 
+// decodeJSONBody decodes a JSON request body into dst. A body that is
+// missing or only whitespace gets its own error, since "invalid json
+// body" would send the client looking for a syntax mistake.
+func decodeJSONBody(body io.Reader, dst any) error {
+	if err := json.NewDecoder(body).Decode(dst); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("request body required")
+		}
+		return errors.New("invalid json body")
+	}
+	return nil
+}
+
+// writeList writes a list response in the {"data": [...]} envelope, or as
+// the bare array when the request has envelope=false.
+func writeList(w http.ResponseWriter, r *http.Request, data any) {
+	writeListPage(w, r, data, nil)
+}
+
+// writeListPage is writeList with extra envelope fields, such as paging
+// cursors. A bare array has nowhere to carry them, so they are dropped.
+func writeListPage(w http.ResponseWriter, r *http.Request, data any, fields map[string]any) {
+	if value := strings.TrimSpace(r.URL.Query().Get("envelope")); value != "" {
+		envelope, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "envelope must be true or false", http.StatusBadRequest)
+			return
+		}
+		if !envelope {
+			writeJSON(w, http.StatusOK, data)
+			return
+		}
+	}
+	payload := map[string]any{"data": data}
+	for key, value := range fields {
+		payload[key] = value
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
+
+// writeJSON encodes payload before writing anything, so an encoding
+// failure becomes a 500 instead of a truncated body. It has no request to
+// hand, so it logs with the id withRequestID put on the response.
 func writeJSON(w http.ResponseWriter, status int, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logWithRequestID(w.Header().Get(requestIDHeader), "encode json response failed: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
-	_ = json.NewEncoder(w).Encode(payload)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		logWithRequestID(w.Header().Get(requestIDHeader), "write json response failed: %v", err)
+	}
 }
@@ -4,8 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -14,17 +19,116 @@ import (
 )
 
 type stubBabyStore struct {
-	data            []server.Baby
-	err             error
-	createEventFunc func(ctx context.Context, input server.CreateEventInput) (server.Event, error)
-	listWeightFunc  func(ctx context.Context, babyID int64) ([]server.WeightEntry, error)
+	data              []server.Baby
+	err               error
+	listBabiesFunc    func(ctx context.Context, filter server.BabyFilter) ([]server.Baby, error)
+	deleteBabyFunc    func(ctx context.Context, id int64) error
+	mergeBabiesFunc   func(ctx context.Context, targetID, sourceID int64) (server.Baby, error)
+	updateBabyFunc    func(ctx context.Context, id int64, input server.UpdateBabyInput) (server.Baby, error)
+	listEventsFunc    func(ctx context.Context, babyID int64, filter server.EventFilter) ([]server.Event, error)
+	streamEventsFunc  func(ctx context.Context, babyID int64, fn func(server.Event) error) error
+	countEventsFunc   func(ctx context.Context, babyID int64, filter server.EventFilter) (int64, error)
+	createEventFunc   func(ctx context.Context, input server.CreateEventInput) (server.Event, error)
+	createEventsFunc  func(ctx context.Context, inputs []server.CreateEventInput) ([]server.Event, error)
+	listWeightFunc    func(ctx context.Context, babyID int64) ([]server.WeightEntry, error)
+	createWeightsFunc func(ctx context.Context, babyID int64, entries []server.NewWeightEntry) ([]server.WeightEntry, error)
+	listSleepFunc     func(ctx context.Context, babyID int64, from, to time.Time) ([]server.SleepSession, error)
+	statusFunc        func(ctx context.Context, babyID int64) (server.BabyStatus, error)
+	endSleepFunc      func(ctx context.Context, babyID, eventID int64, endAt time.Time) (server.Event, error)
+	sideBalanceFunc   func(ctx context.Context, babyID int64, limit int) (server.SideBalance, error)
+	sideMinutesFunc   func(ctx context.Context, babyID int64, from, to time.Time) (server.SideMinutes, error)
+	intervalsFunc     func(ctx context.Context, babyID int64, from, to time.Time) (server.FeedingIntervals, error)
+	dailyCountsFunc   func(ctx context.Context, babyID int64, from, to time.Time, tz string) (map[string]server.DailyCounts, error)
+	countsByDateFunc  func(ctx context.Context, babyID int64, from, to time.Time, tz string) (map[string]int64, error)
+	statsFunc         func(ctx context.Context, babyID int64) (server.BabyStats, error)
+	latestWeightFunc  func(ctx context.Context, babyID int64) (server.LatestWeight, error)
+	idempotentFunc    func(ctx context.Context, key string, input server.CreateEventInput) (server.Event, bool, error)
+	getEventFunc      func(ctx context.Context, babyID, eventID int64) (server.Event, error)
+	pingFunc          func(ctx context.Context) error
+	historyFunc       func(ctx context.Context, babyID, eventID int64) ([]server.EventAuditEntry, error)
+	deleteWeightFunc  func(ctx context.Context, babyID, weightID int64) error
+	importBabyFunc    func(ctx context.Context, baby server.NewBabyInput, inputs []server.CreateEventInput) (server.Baby, error)
+	nearbyEventFunc   func(ctx context.Context, babyID int64, eventType string, at time.Time, window time.Duration) (server.Event, error)
+	prevWeightFunc    func(ctx context.Context, babyID int64, at time.Time) (server.WeightEntry, error)
+	overlapFunc       func(ctx context.Context, babyID int64, eventType string, start time.Time, end *time.Time) (server.Event, error)
+	claimedFunc       func(ctx context.Context, babyID int64, key string) (server.Event, error)
 }
 
-func (s stubBabyStore) ListBabies(_ context.Context) ([]server.Baby, error) {
+func (s stubBabyStore) ListBabies(ctx context.Context, filter server.BabyFilter) ([]server.Baby, error) {
+	if s.listBabiesFunc != nil {
+		return s.listBabiesFunc(ctx, filter)
+	}
 	if s.err != nil {
 		return nil, s.err
 	}
-	return s.data, nil
+	data := make([]server.Baby, 0, len(s.data))
+	for _, baby := range s.data {
+		if baby.DeletedAt == nil || filter.IncludeDeleted {
+			data = append(data, baby)
+		}
+	}
+	return data, nil
+}
+
+func (s stubBabyStore) GetBaby(_ context.Context, id int64, filter server.BabyFilter) (server.Baby, error) {
+	if s.err != nil {
+		return server.Baby{}, s.err
+	}
+	for _, baby := range s.data {
+		if baby.ID == id && (baby.DeletedAt == nil || filter.IncludeDeleted) {
+			return baby, nil
+		}
+	}
+	return server.Baby{}, server.ErrNotFound
+}
+
+func (s stubBabyStore) UpdateBaby(ctx context.Context, id int64, input server.UpdateBabyInput) (server.Baby, error) {
+	if s.updateBabyFunc == nil {
+		return server.Baby{}, errors.New("update baby not implemented")
+	}
+	return s.updateBabyFunc(ctx, id, input)
+}
+
+func (s stubBabyStore) DeleteBaby(ctx context.Context, id int64) error {
+	if s.deleteBabyFunc == nil {
+		return errors.New("delete baby not implemented")
+	}
+	return s.deleteBabyFunc(ctx, id)
+}
+
+func (s stubBabyStore) MergeBabies(ctx context.Context, targetID, sourceID int64) (server.Baby, error) {
+	if s.mergeBabiesFunc == nil {
+		return server.Baby{}, errors.New("merge babies not implemented")
+	}
+	return s.mergeBabiesFunc(ctx, targetID, sourceID)
+}
+
+func (s stubBabyStore) ListEvents(ctx context.Context, babyID int64, filter server.EventFilter) ([]server.Event, error) {
+	if s.listEventsFunc == nil {
+		return nil, errors.New("list events not implemented")
+	}
+	return s.listEventsFunc(ctx, babyID, filter)
+}
+
+func (s stubBabyStore) GetEvent(ctx context.Context, babyID, eventID int64) (server.Event, error) {
+	if s.getEventFunc == nil {
+		return server.Event{}, errors.New("get event not implemented")
+	}
+	return s.getEventFunc(ctx, babyID, eventID)
+}
+
+func (s stubBabyStore) StreamEvents(ctx context.Context, babyID int64, fn func(server.Event) error) error {
+	if s.streamEventsFunc == nil {
+		return errors.New("stream events not implemented")
+	}
+	return s.streamEventsFunc(ctx, babyID, fn)
+}
+
+func (s stubBabyStore) CountEvents(ctx context.Context, babyID int64, filter server.EventFilter) (int64, error) {
+	if s.countEventsFunc == nil {
+		return 0, errors.New("count events not implemented")
+	}
+	return s.countEventsFunc(ctx, babyID, filter)
 }
 
 func (s stubBabyStore) CreateEvent(ctx context.Context, input server.CreateEventInput) (server.Event, error) {
@@ -34,6 +138,29 @@ func (s stubBabyStore) CreateEvent(ctx context.Context, input server.CreateEvent
 	return s.createEventFunc(ctx, input)
 }
 
+func (s stubBabyStore) CreateEventIdempotent(ctx context.Context, key string, input server.CreateEventInput) (server.Event, bool, error) {
+	if s.idempotentFunc == nil {
+		return server.Event{}, false, errors.New("create event idempotent not implemented")
+	}
+	return s.idempotentFunc(ctx, key, input)
+}
+
+// GetIdempotentEvent treats every key as unused unless claimedFunc says
+// otherwise, so tests only stub it for replays.
+func (s stubBabyStore) GetIdempotentEvent(ctx context.Context, babyID int64, key string) (server.Event, error) {
+	if s.claimedFunc == nil {
+		return server.Event{}, server.ErrNotFound
+	}
+	return s.claimedFunc(ctx, babyID, key)
+}
+
+func (s stubBabyStore) CreateEvents(ctx context.Context, inputs []server.CreateEventInput) ([]server.Event, error) {
+	if s.createEventsFunc == nil {
+		return nil, errors.New("create events not implemented")
+	}
+	return s.createEventsFunc(ctx, inputs)
+}
+
 func (s stubBabyStore) ListWeightEntries(ctx context.Context, babyID int64) ([]server.WeightEntry, error) {
 	if s.listWeightFunc == nil {
 		return nil, errors.New("list weight entries not implemented")
@@ -41,6 +168,134 @@ func (s stubBabyStore) ListWeightEntries(ctx context.Context, babyID int64) ([]s
 	return s.listWeightFunc(ctx, babyID)
 }
 
+func (s stubBabyStore) CreateWeightEntries(ctx context.Context, babyID int64, entries []server.NewWeightEntry) ([]server.WeightEntry, error) {
+	if s.createWeightsFunc == nil {
+		return nil, errors.New("create weight entries not implemented")
+	}
+	return s.createWeightsFunc(ctx, babyID, entries)
+}
+
+func (s stubBabyStore) DeleteWeightEntry(ctx context.Context, babyID, weightID int64) error {
+	if s.deleteWeightFunc == nil {
+		return errors.New("delete weight entry not implemented")
+	}
+	return s.deleteWeightFunc(ctx, babyID, weightID)
+}
+
+func (s stubBabyStore) ImportBaby(ctx context.Context, baby server.NewBabyInput, inputs []server.CreateEventInput) (server.Baby, error) {
+	if s.importBabyFunc == nil {
+		return server.Baby{}, errors.New("import baby not implemented")
+	}
+	return s.importBabyFunc(ctx, baby, inputs)
+}
+
+func (s stubBabyStore) FindNearbyEvent(ctx context.Context, babyID int64, eventType string, at time.Time, window time.Duration) (server.Event, error) {
+	if s.nearbyEventFunc == nil {
+		return server.Event{}, errors.New("find nearby event not implemented")
+	}
+	return s.nearbyEventFunc(ctx, babyID, eventType, at, window)
+}
+
+// FindOverlappingEvent finds nothing when overlapFunc is nil, since every
+// sleep and nursing event created runs the check.
+func (s stubBabyStore) FindOverlappingEvent(ctx context.Context, babyID int64, eventType string, start time.Time, end *time.Time) (server.Event, error) {
+	if s.overlapFunc == nil {
+		return server.Event{}, server.ErrNotFound
+	}
+	return s.overlapFunc(ctx, babyID, eventType, start, end)
+}
+
+func (s stubBabyStore) PreviousWeight(ctx context.Context, babyID int64, at time.Time) (server.WeightEntry, error) {
+	if s.prevWeightFunc == nil {
+		return server.WeightEntry{}, errors.New("previous weight not implemented")
+	}
+	return s.prevWeightFunc(ctx, babyID, at)
+}
+
+func (s stubBabyStore) ListSleepSessions(ctx context.Context, babyID int64, from, to time.Time) ([]server.SleepSession, error) {
+	if s.listSleepFunc == nil {
+		return nil, errors.New("list sleep sessions not implemented")
+	}
+	return s.listSleepFunc(ctx, babyID, from, to)
+}
+
+func (s stubBabyStore) GetBabyStatus(ctx context.Context, babyID int64) (server.BabyStatus, error) {
+	if s.statusFunc == nil {
+		return server.BabyStatus{}, errors.New("get baby status not implemented")
+	}
+	return s.statusFunc(ctx, babyID)
+}
+
+func (s stubBabyStore) EndSleep(ctx context.Context, babyID, eventID int64, endAt time.Time) (server.Event, error) {
+	if s.endSleepFunc == nil {
+		return server.Event{}, errors.New("end sleep not implemented")
+	}
+	return s.endSleepFunc(ctx, babyID, eventID, endAt)
+}
+
+func (s stubBabyStore) RecentSideBalance(ctx context.Context, babyID int64, limit int) (server.SideBalance, error) {
+	if s.sideBalanceFunc == nil {
+		return server.SideBalance{}, errors.New("recent side balance not implemented")
+	}
+	return s.sideBalanceFunc(ctx, babyID, limit)
+}
+
+func (s stubBabyStore) NursingMinutesBySide(ctx context.Context, babyID int64, from, to time.Time) (server.SideMinutes, error) {
+	if s.sideMinutesFunc == nil {
+		return server.SideMinutes{}, errors.New("nursing minutes by side not implemented")
+	}
+	return s.sideMinutesFunc(ctx, babyID, from, to)
+}
+
+func (s stubBabyStore) FeedingIntervals(ctx context.Context, babyID int64, from, to time.Time) (server.FeedingIntervals, error) {
+	if s.intervalsFunc == nil {
+		return server.FeedingIntervals{}, errors.New("feeding intervals not implemented")
+	}
+	return s.intervalsFunc(ctx, babyID, from, to)
+}
+
+func (s stubBabyStore) DailyCounts(ctx context.Context, babyID int64, from, to time.Time, tz string) (map[string]server.DailyCounts, error) {
+	if s.dailyCountsFunc == nil {
+		return nil, errors.New("daily counts not implemented")
+	}
+	return s.dailyCountsFunc(ctx, babyID, from, to, tz)
+}
+
+func (s stubBabyStore) EventCountsByLocalDate(ctx context.Context, babyID int64, from, to time.Time, tz string) (map[string]int64, error) {
+	if s.countsByDateFunc == nil {
+		return nil, errors.New("event counts by local date not implemented")
+	}
+	return s.countsByDateFunc(ctx, babyID, from, to, tz)
+}
+
+func (s stubBabyStore) BabyStats(ctx context.Context, babyID int64) (server.BabyStats, error) {
+	if s.statsFunc == nil {
+		return server.BabyStats{}, errors.New("baby stats not implemented")
+	}
+	return s.statsFunc(ctx, babyID)
+}
+
+func (s stubBabyStore) Ping(ctx context.Context) error {
+	if s.pingFunc == nil {
+		return errors.New("ping not implemented")
+	}
+	return s.pingFunc(ctx)
+}
+
+func (s stubBabyStore) EventHistory(ctx context.Context, babyID, eventID int64) ([]server.EventAuditEntry, error) {
+	if s.historyFunc == nil {
+		return nil, errors.New("event history not implemented")
+	}
+	return s.historyFunc(ctx, babyID, eventID)
+}
+
+func (s stubBabyStore) LatestWeight(ctx context.Context, babyID int64) (server.LatestWeight, error) {
+	if s.latestWeightFunc == nil {
+		return server.LatestWeight{}, errors.New("latest weight not implemented")
+	}
+	return s.latestWeightFunc(ctx, babyID)
+}
+
 func TestHealthz(t *testing.T) {
 	t.Parallel()
 
@@ -63,66 +318,144 @@ func TestHealthz(t *testing.T) {
 	}
 }
 
-func TestListBabies(t *testing.T) {
+func TestReadyz(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodGet, "/v1/babies", nil)
+	tests := []struct {
+		name       string
+		ping       func(context.Context) error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "ready",
+			ping:       func(context.Context) error { return nil },
+			wantStatus: http.StatusOK,
+			wantBody:   `"status":"ready"`,
+		},
+		{
+			name:       "unreachable",
+			ping:       func(context.Context) error { return errors.New("connection refused") },
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `"error":"database unreachable"`,
+		},
+		{
+			name: "hanging",
+			ping: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `"error":"database did not answer within 20ms"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouterWithConfig(stubBabyStore{pingFunc: tt.ping}, server.Config{ReadyTimeout: 20 * time.Millisecond}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) || !strings.Contains(rr.Body.String(), `"duration_ms":`) {
+				t.Fatalf("expected %s and duration_ms in %s", tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
 	rr := httptest.NewRecorder()
 
-	server.NewRouter(stubBabyStore{
-		data: []server.Baby{{ID: 1, Name: "Alice"}},
-	}).ServeHTTP(rr, req)
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
 	}
 
-	var got struct {
-		Data []server.Baby `json:"data"`
-	}
+	var got map[string]string
 	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
-
-	if len(got.Data) != 1 {
-		t.Fatalf("expected 1 baby, got %d", len(got.Data))
+	for _, key := range []string{"commit", "build_time", "go_version"} {
+		if _, ok := got[key]; !ok {
+			t.Fatalf("expected %q in %v", key, got)
+		}
 	}
-	if got.Data[0].Name != "Alice" {
-		t.Fatalf("expected baby name Alice, got %q", got.Data[0].Name)
+	if got["go_version"] != runtime.Version() {
+		t.Fatalf("expected go_version %q, got %q", runtime.Version(), got["go_version"])
 	}
 }
 
-func TestListBabiesStoreError(t *testing.T) {
+func TestRouterPathPrefix(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodGet, "/v1/babies", nil)
-	rr := httptest.NewRecorder()
+	store := stubBabyStore{data: []server.Baby{{ID: 1, Name: "Mila"}}}
+
+	for _, tc := range []struct {
+		name   string
+		cfg    server.Config
+		path   string
+		status int
+	}{
+		{name: "prefixed route", cfg: server.Config{PathPrefix: "/api"}, path: "/api/v1/babies", status: http.StatusOK},
+		{name: "unprefixed route", cfg: server.Config{PathPrefix: "/api"}, path: "/v1/babies", status: http.StatusNotFound},
+		{name: "healthz follows prefix", cfg: server.Config{PathPrefix: "/api"}, path: "/api/healthz", status: http.StatusOK},
+		{name: "openapi follows prefix", cfg: server.Config{PathPrefix: "/api"}, path: "/api/openapi.json", status: http.StatusOK},
+		{name: "healthz override", cfg: server.Config{PathPrefix: "/api", HealthzPath: "/healthz"}, path: "/healthz", status: http.StatusOK},
+		{name: "healthz override drops prefixed", cfg: server.Config{PathPrefix: "/api", HealthzPath: "/healthz"}, path: "/api/healthz", status: http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		rr := httptest.NewRecorder()
+
+		server.NewRouterWithConfig(store, tc.cfg).ServeHTTP(rr, req)
+
+		if rr.Code != tc.status {
+			t.Fatalf("%s: expected status %d, got %d", tc.name, tc.status, rr.Code)
+		}
+	}
+}
 
-	server.NewRouter(stubBabyStore{err: errors.New("boom")}).ServeHTTP(rr, req)
+func TestRouterMethodNotAllowed(t *testing.T) {
+	t.Parallel()
 
-	if rr.Code != http.StatusInternalServerError {
-		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	for _, tc := range []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		{method: http.MethodPost, path: "/v1/babies", allow: "GET, HEAD"},
+		{method: http.MethodPut, path: "/v1/babies/42", allow: "DELETE, GET, HEAD, PATCH"},
+		{method: http.MethodDelete, path: "/v1/babies/42/events", allow: "GET, HEAD, POST"},
+	} {
+		rr := httptest.NewRecorder()
+		server.NewRouter(stubBabyStore{}).ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, nil))
+
+		if rr.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("%s %s: expected status %d, got %d", tc.method, tc.path, http.StatusMethodNotAllowed, rr.Code)
+		}
+		if got := rr.Header().Get("Allow"); got != tc.allow {
+			t.Fatalf("%s %s: expected Allow %q, got %q", tc.method, tc.path, tc.allow, got)
+		}
 	}
 }
 
-func TestListWeightEntries(t *testing.T) {
+func TestListBabies(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/weights", nil)
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies", nil)
 	rr := httptest.NewRecorder()
 
 	server.NewRouter(stubBabyStore{
-		listWeightFunc: func(_ context.Context, babyID int64) ([]server.WeightEntry, error) {
-			if babyID != 42 {
-				t.Fatalf("expected baby id 42, got %d", babyID)
-			}
-			return []server.WeightEntry{
-				{
-					OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"),
-					WeightKg:   3.45,
-				},
-			}, nil
-		},
+		data: []server.Baby{{ID: 1, Name: "Alice", CreatedAt: mustParseRFC3339(t, "2026-02-20T08:30:00Z")}},
 	}).ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusOK {
@@ -130,211 +463,2037 @@ func TestListWeightEntries(t *testing.T) {
 	}
 
 	var got struct {
-		Data []server.WeightEntry `json:"data"`
+		Data []server.Baby `json:"data"`
 	}
 	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
+
 	if len(got.Data) != 1 {
-		t.Fatalf("expected 1 weight entry, got %d", len(got.Data))
+		t.Fatalf("expected 1 baby, got %d", len(got.Data))
 	}
-	if got.Data[0].WeightKg != 3.45 {
-		t.Fatalf("expected weight 3.45, got %f", got.Data[0].WeightKg)
+	if got.Data[0].Name != "Alice" {
+		t.Fatalf("expected baby name Alice, got %q", got.Data[0].Name)
+	}
+	if !got.Data[0].CreatedAt.Equal(mustParseRFC3339(t, "2026-02-20T08:30:00Z")) {
+		t.Fatalf("expected created_at 2026-02-20T08:30:00Z, got %s", got.Data[0].CreatedAt)
 	}
 }
 
-func TestListWeightEntriesInvalidBabyID(t *testing.T) {
+func TestListBabiesEnvelope(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodGet, "/v1/babies/not-a-number/weights", nil)
-	rr := httptest.NewRecorder()
+	router := server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 1, Name: "Alice", CreatedAt: mustParseRFC3339(t, "2026-02-20T08:30:00Z")}},
+	})
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{query: "", wantStatus: http.StatusOK, wantBody: `{"data":[{"id":1,"name":"Alice","created_at":"2026-02-20T08:30:00Z","deleted_at":null,"color":null,"avatar_url":null,"timezone":null}]}`},
+		{query: "?envelope=true", wantStatus: http.StatusOK, wantBody: `{"data":[{"id":1,"name":"Alice","created_at":"2026-02-20T08:30:00Z","deleted_at":null,"color":null,"avatar_url":null,"timezone":null}]}`},
+		{query: "?envelope=false", wantStatus: http.StatusOK, wantBody: `[{"id":1,"name":"Alice","created_at":"2026-02-20T08:30:00Z","deleted_at":null,"color":null,"avatar_url":null,"timezone":null}]`},
+		{query: "?envelope=nope", wantStatus: http.StatusBadRequest},
+	}
 
-	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies"+tt.query, nil))
 
-	if rr.Code != http.StatusBadRequest {
-		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		if rr.Code != tt.wantStatus {
+			t.Fatalf("%q: expected status %d, got %d", tt.query, tt.wantStatus, rr.Code)
+		}
+		if tt.wantBody != "" && strings.TrimSpace(rr.Body.String()) != tt.wantBody {
+			t.Fatalf("%q: expected body %s, got %s", tt.query, tt.wantBody, rr.Body.String())
+		}
 	}
 }
 
-func TestListWeightEntriesStoreError(t *testing.T) {
+func TestListBabiesStoreError(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/weights", nil)
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies", nil)
 	rr := httptest.NewRecorder()
 
-	server.NewRouter(stubBabyStore{
-		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
-			return nil, errors.New("boom")
-		},
-	}).ServeHTTP(rr, req)
+	server.NewRouter(stubBabyStore{err: errors.New("boom")}).ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusInternalServerError {
 		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
 	}
 }
 
-func TestGetProfile(t *testing.T) {
+func TestGetBaby(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodGet, "/v1/profile", nil)
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/2", nil)
 	rr := httptest.NewRecorder()
 
-	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+	server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}},
+	}).ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
 	}
 
 	var got struct {
-		ID    string `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
+		Data server.Baby `json:"data"`
 	}
 	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
-
-	if got.ID == "" {
-		t.Fatal("expected id to be present")
+	if got.Data.ID != 2 || got.Data.Name != "Bob" {
+		t.Fatalf("unexpected baby %+v", got.Data)
 	}
-	if got.Name == "" {
-		t.Fatal("expected name to be present")
+}
+
+func TestGetBabyErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		err  error
+		want int
+	}{
+		{name: "invalid id", path: "/v1/babies/nope", want: http.StatusBadRequest},
+		{name: "zero id", path: "/v1/babies/0", want: http.StatusBadRequest},
+		{name: "negative id", path: "/v1/babies/-5", want: http.StatusBadRequest},
+		{name: "not found", path: "/v1/babies/77", want: http.StatusNotFound},
+		{name: "store failure", path: "/v1/babies/1", err: errors.New("boom"), want: http.StatusInternalServerError},
 	}
-	if got.Email == "" {
-		t.Fatal("expected email to be present")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				data: []server.Baby{{ID: 1, Name: "Alice"}},
+				err:  tt.err,
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
 	}
 }
 
-func TestCreateEventDiaper(t *testing.T) {
+func TestListBabiesSort(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
-		"type": "diaper",
-		"occurred_at": "2026-02-26T10:00:00Z",
-		"notes": "quick change"
-	}`))
-	req.Header.Set("Content-Type", "application/json")
-	rr := httptest.NewRecorder()
+	tests := []struct {
+		query      string
+		want       int
+		sort       server.BabySort
+		descending bool
+	}{
+		{query: "", want: http.StatusOK, sort: server.BabySortID},
+		{query: "?sort=name", want: http.StatusOK, sort: server.BabySortName},
+		{query: "?sort=created_at&order=desc", want: http.StatusOK, sort: server.BabySortCreatedAt, descending: true},
+		{query: "?order=DESC", want: http.StatusOK, sort: server.BabySortID, descending: true},
+		{query: "?sort=name DESC", want: http.StatusBadRequest},
+		{query: "?sort=deleted_at", want: http.StatusBadRequest},
+		{query: "?order=up", want: http.StatusBadRequest},
+	}
 
-	store := stubBabyStore{
-		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
-			if input.BabyID != 42 {
-				t.Fatalf("expected baby id 42, got %d", input.BabyID)
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			var got server.BabyFilter
+			server.NewRouter(stubBabyStore{
+				listBabiesFunc: func(_ context.Context, filter server.BabyFilter) ([]server.Baby, error) {
+					got = filter
+					return []server.Baby{}, nil
+				},
+			}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies"+strings.ReplaceAll(tt.query, " ", "%20"), nil))
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
 			}
-			if input.Type != "diaper" {
-				t.Fatalf("expected type diaper, got %q", input.Type)
+			if tt.want == http.StatusOK && (got.Sort != tt.sort || got.Descending != tt.descending) {
+				t.Fatalf("expected sort %q descending %v, got %+v", tt.sort, tt.descending, got)
 			}
-			return server.Event{
-				ID:         100,
-				BabyID:     input.BabyID,
-				Type:       input.Type,
-				OccurredAt: input.OccurredAt,
-				Details:    input.Details,
-			}, nil
-		},
+		})
 	}
+}
 
-	server.NewRouter(store).ServeHTTP(rr, req)
+func TestListBabiesHidesDeleted(t *testing.T) {
+	t.Parallel()
 
-	if rr.Code != http.StatusCreated {
-		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	deletedAt := mustParseRFC3339(t, "2026-03-01T12:00:00Z")
+	router := server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 1, Name: "Alice", DeletedAt: &deletedAt}, {ID: 2, Name: "Bob"}},
+	})
+
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{query: "", want: 1},
+		{query: "?include_deleted=true", want: 2},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies"+tt.query, nil))
+
+		var got struct {
+			Data []server.Baby `json:"data"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if len(got.Data) != tt.want {
+			t.Fatalf("%q: expected %d babies, got %d", tt.query, tt.want, len(got.Data))
+		}
 	}
 
-	var got struct {
-		Data server.Event `json:"data"`
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies?include_deleted=maybe", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
 	}
-	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
-		t.Fatalf("failed to unmarshal response: %v", err)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/1", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected archived baby to be hidden, got status %d", rr.Code)
 	}
-	if got.Data.Type != "diaper" {
-		t.Fatalf("expected type diaper, got %q", got.Data.Type)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/1?include_deleted=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected archived baby with include_deleted, got status %d", rr.Code)
 	}
 }
 
-func TestCreateEventNursing(t *testing.T) {
+func TestUpdateBaby(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodPost, "/v1/babies/7/events", strings.NewReader(`{
-		"type": "nursing",
-		"occurred_at": "2026-02-26T11:15:00Z",
-		"side": "left",
-		"duration_minutes": 18
-	}`))
-	req.Header.Set("Content-Type", "application/json")
+	req := httptest.NewRequest(http.MethodPatch, "/v1/babies/42", strings.NewReader(`{"name": "  Mila   Rose "}`))
 	rr := httptest.NewRecorder()
 
-	store := stubBabyStore{
-		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
-			if input.Type != "nursing" {
-				t.Fatalf("expected type nursing, got %q", input.Type)
+	server.NewRouter(stubBabyStore{
+		updateBabyFunc: func(_ context.Context, id int64, input server.UpdateBabyInput) (server.Baby, error) {
+			if id != 42 || input.Name == nil || *input.Name != "Mila Rose" {
+				t.Fatalf("expected baby 42 renamed to Mila Rose, got %d %+v", id, input)
 			}
-			return server.Event{
-				ID:         101,
-				BabyID:     input.BabyID,
-				Type:       input.Type,
-				OccurredAt: input.OccurredAt,
+			return server.Baby{ID: id, Name: *input.Name}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"name":"Mila Rose"`) {
+		t.Fatalf("expected the updated baby, got %s", rr.Body.String())
+	}
+}
+
+func TestUpdateBabyColorAndAvatar(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/babies/42", strings.NewReader(`{"color": " #F80 ", "avatar_url": ""}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		updateBabyFunc: func(_ context.Context, id int64, input server.UpdateBabyInput) (server.Baby, error) {
+			if input.Name != nil || input.Color == nil || *input.Color != "#ff8800" || input.AvatarURL == nil || *input.AvatarURL != "" {
+				t.Fatalf("expected color #ff8800 and a cleared avatar, got %+v", input)
+			}
+			return server.Baby{ID: id, Name: "Mila", Color: input.Color}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), `"color":"#ff8800","avatar_url":null`) {
+		t.Fatalf("expected the color and no avatar, got %s", rr.Body.String())
+	}
+}
+
+func TestUpdateBabyTimezone(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/babies/42", strings.NewReader(`{"timezone": " Europe/Lisbon "}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		updateBabyFunc: func(_ context.Context, id int64, input server.UpdateBabyInput) (server.Baby, error) {
+			if input.Timezone == nil || *input.Timezone != "Europe/Lisbon" {
+				t.Fatalf("expected timezone Europe/Lisbon, got %+v", input)
+			}
+			return server.Baby{ID: id, Name: "Mila", Timezone: input.Timezone}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), `"timezone":"Europe/Lisbon"`) {
+		t.Fatalf("expected the timezone, got %s", rr.Body.String())
+	}
+}
+
+func TestUpdateBabyKeepsOmittedFields(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/babies/42", strings.NewReader(`{}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		updateBabyFunc: func(_ context.Context, id int64, input server.UpdateBabyInput) (server.Baby, error) {
+			if input.Name != nil {
+				t.Fatalf("expected no name change, got %q", *input.Name)
+			}
+			return server.Baby{ID: id, Name: "Mila"}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestUpdateBabyErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		storeErr error
+		want     int
+	}{
+		{name: "blank name", body: `{"name": "  "}`, want: http.StatusBadRequest},
+		{name: "long name", body: `{"name": "` + strings.Repeat("a", 101) + `"}`, want: http.StatusBadRequest},
+		{name: "color name", body: `{"color": "orange"}`, want: http.StatusBadRequest},
+		{name: "color without hash", body: `{"color": "ff8800"}`, want: http.StatusBadRequest},
+		{name: "color not hex", body: `{"color": "#ff88zz"}`, want: http.StatusBadRequest},
+		{name: "avatar not a url", body: `{"avatar_url": "avatar.png"}`, want: http.StatusBadRequest},
+		{name: "avatar scheme", body: `{"avatar_url": "javascript:alert(1)"}`, want: http.StatusBadRequest},
+		{name: "unknown timezone", body: `{"timezone": "Mars/Olympus"}`, want: http.StatusBadRequest},
+		{name: "local timezone", body: `{"timezone": "Local"}`, want: http.StatusBadRequest},
+		{name: "malformed", body: `{"name":`, want: http.StatusBadRequest},
+		{name: "not found", body: `{"name": "Mila"}`, storeErr: server.ErrNotFound, want: http.StatusNotFound},
+		{name: "store failure", body: `{"name": "Mila"}`, storeErr: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPatch, "/v1/babies/42", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				updateBabyFunc: func(context.Context, int64, server.UpdateBabyInput) (server.Baby, error) {
+					return server.Baby{}, tt.storeErr
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteBaby(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		storeErr error
+		want     int
+	}{
+		{name: "archived", want: http.StatusNoContent},
+		{name: "not found", storeErr: server.ErrNotFound, want: http.StatusNotFound},
+		{name: "store failure", storeErr: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/v1/babies/42", nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				deleteBabyFunc: func(_ context.Context, id int64) error {
+					if id != 42 {
+						t.Fatalf("expected baby id 42, got %d", id)
+					}
+					return tt.storeErr
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
+	}
+}
+
+func TestMergeBabies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		storeErr error
+		want     int
+	}{
+		{name: "merged", body: `{"source_id": 43}`, want: http.StatusOK},
+		{name: "into itself", body: `{"source_id": 42}`, want: http.StatusBadRequest},
+		{name: "missing source", body: `{}`, want: http.StatusBadRequest},
+		{name: "negative source", body: `{"source_id": -1}`, want: http.StatusBadRequest},
+		{name: "malformed", body: `{"source_id": "43"}`, want: http.StatusBadRequest},
+		{name: "not found", body: `{"source_id": 43}`, storeErr: server.ErrNotFound, want: http.StatusNotFound},
+		{name: "store failure", body: `{"source_id": 43}`, storeErr: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/merge", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				mergeBabiesFunc: func(_ context.Context, targetID, sourceID int64) (server.Baby, error) {
+					if targetID != 42 || sourceID != 43 {
+						t.Fatalf("expected baby 43 merged into 42, got %d into %d", sourceID, targetID)
+					}
+					return server.Baby{ID: 42, Name: "Mila"}, tt.storeErr
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+			if tt.want == http.StatusOK && !strings.Contains(rr.Body.String(), `"data":{"id":42,"name":"Mila"`) {
+				t.Fatalf("expected the target baby, got %s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestStoreTimeoutReturnsGatewayTimeout(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listEventsFunc: func(context.Context, int64, server.EventFilter) ([]server.Event, error) {
+			return nil, fmt.Errorf("query events: %w: %w", server.ErrTimeout, context.DeadlineExceeded)
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status %d, got %d", http.StatusGatewayTimeout, rr.Code)
+	}
+}
+
+func TestListWeightEntries(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/weights", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listWeightFunc: func(_ context.Context, babyID int64) ([]server.WeightEntry, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			return []server.WeightEntry{
+				{
+					OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"),
+					WeightKg:   3.45,
+				},
+			}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data []server.WeightEntry `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got.Data) != 1 {
+		t.Fatalf("expected 1 weight entry, got %d", len(got.Data))
+	}
+	if got.Data[0].WeightKg != 3.45 {
+		t.Fatalf("expected weight 3.45, got %f", got.Data[0].WeightKg)
+	}
+}
+
+func TestListWeightEntriesInPounds(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/weights?unit=lb", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return []server.WeightEntry{
+				{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: 3.44},
+			}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got.Data) != 1 {
+		t.Fatalf("expected 1 weight entry, got %d", len(got.Data))
+	}
+	if got.Data[0]["weight_lb"] != 7.58 {
+		t.Fatalf("expected weight_lb 7.58, got %v", got.Data[0]["weight_lb"])
+	}
+	if _, ok := got.Data[0]["weight_kg"]; ok {
+		t.Fatalf("expected no weight_kg in pound response, got %v", got.Data[0])
+	}
+}
+
+func TestListWeightEntriesInvalidUnit(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/weights?unit=stone", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestListWeightEntriesInvalidBabyID(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/not-a-number/weights", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestListWeightEntriesNonPositiveBabyID(t *testing.T) {
+	t.Parallel()
+
+	for _, id := range []string{"0", "-5"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/babies/"+id+"/weights", nil)
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{
+			listWeightFunc: func(context.Context, int64) ([]server.WeightEntry, error) {
+				t.Fatal("expected the store not to be queried")
+				return nil, nil
+			},
+		}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status %d, got %d", id, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestListWeightEntriesStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/weights", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return nil, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestListWeightEntriesEncodingError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/weights", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return []server.WeightEntry{{WeightKg: math.NaN()}}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if strings.Contains(rr.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("expected no JSON content type on encoding failure, got %q", rr.Header().Get("Content-Type"))
+	}
+}
+
+func TestDeleteWeightEntry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		path     string
+		storeErr error
+		want     int
+	}{
+		{name: "deleted", path: "/v1/babies/42/weights/7", want: http.StatusNoContent},
+		{name: "not found", path: "/v1/babies/42/weights/7", storeErr: server.ErrNotFound, want: http.StatusNotFound},
+		{name: "store failure", path: "/v1/babies/42/weights/7", storeErr: errors.New("boom"), want: http.StatusInternalServerError},
+		{name: "invalid weight id", path: "/v1/babies/42/weights/0", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				deleteWeightFunc: func(_ context.Context, babyID, weightID int64) error {
+					if babyID != 42 || weightID != 7 {
+						t.Fatalf("expected baby 42 weight 7, got %d %d", babyID, weightID)
+					}
+					return tt.storeErr
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/profile", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if got.ID == "" {
+		t.Fatal("expected id to be present")
+	}
+	if got.Name == "" {
+		t.Fatal("expected name to be present")
+	}
+	if got.Email == "" {
+		t.Fatal("expected email to be present")
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listEventsFunc: func(_ context.Context, babyID int64, filter server.EventFilter) ([]server.Event, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			if len(filter.Types) != 0 {
+				t.Fatalf("expected no type filter, got %v", filter.Types)
+			}
+			return []server.Event{{ID: 1, BabyID: 42, Type: "diaper", Details: json.RawMessage(`{}`)}}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data []server.Event `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got.Data) != 1 || got.Data[0].Type != "diaper" {
+		t.Fatalf("expected one diaper event, got %+v", got.Data)
+	}
+}
+
+func TestListEventsTypeFilter(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events?type=nursing&type=Sleep&type=nursing", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listEventsFunc: func(_ context.Context, _ int64, filter server.EventFilter) ([]server.Event, error) {
+			if strings.Join(filter.Types, ",") != "nursing,sleep" {
+				t.Fatalf("expected types nursing,sleep, got %v", filter.Types)
+			}
+			return []server.Event{}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestListEventsNotesSearch(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events?q=+Red++rash+&type=diaper", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listEventsFunc: func(_ context.Context, _ int64, filter server.EventFilter) ([]server.Event, error) {
+			if filter.Notes != "Red rash" || strings.Join(filter.Types, ",") != "diaper" {
+				t.Fatalf("expected a diaper search for %q, got %+v", "Red rash", filter)
+			}
+			return []server.Event{}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestListEventsTagFilter(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events?tag=Teething&tag=+travel+&tag=teething", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listEventsFunc: func(_ context.Context, _ int64, filter server.EventFilter) ([]server.Event, error) {
+			if strings.Join(filter.Tags, ",") != "teething,travel" {
+				t.Fatalf("expected tags teething,travel, got %v", filter.Tags)
+			}
+			return []server.Event{}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestListEventsPaging(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		query          string
+		stored         int
+		wantLimit      int
+		wantOffset     int
+		wantEvents     int
+		wantNextOffset string
+	}{
+		{name: "default limit", query: "", stored: 250, wantLimit: 101, wantEvents: 100, wantNextOffset: "100"},
+		{name: "capped limit", query: "?limit=5000", stored: 1500, wantLimit: 1001, wantEvents: 1000, wantNextOffset: "1000"},
+		{name: "last page", query: "?limit=2&offset=4", stored: 2, wantLimit: 3, wantOffset: 4, wantEvents: 2, wantNextOffset: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				listEventsFunc: func(_ context.Context, _ int64, filter server.EventFilter) ([]server.Event, error) {
+					if filter.Limit != tt.wantLimit || filter.Offset != tt.wantOffset {
+						t.Fatalf("expected limit %d offset %d, got %d %d", tt.wantLimit, tt.wantOffset, filter.Limit, filter.Offset)
+					}
+					events := make([]server.Event, min(tt.stored, filter.Limit))
+					for i := range events {
+						events[i] = server.Event{ID: int64(filter.Offset + i + 1), BabyID: 42, Type: "diaper"}
+					}
+					return events, nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+
+			var got struct {
+				Data       []server.Event  `json:"data"`
+				NextOffset json.RawMessage `json:"next_offset"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if len(got.Data) != tt.wantEvents {
+				t.Fatalf("expected %d events, got %d", tt.wantEvents, len(got.Data))
+			}
+			if string(got.NextOffset) != tt.wantNextOffset {
+				t.Fatalf("expected next_offset %s, got %s", tt.wantNextOffset, got.NextOffset)
+			}
+		})
+	}
+}
+
+func TestListEventsWithTotal(t *testing.T) {
+	t.Parallel()
+
+	counted := false
+	store := stubBabyStore{
+		listEventsFunc: func(context.Context, int64, server.EventFilter) ([]server.Event, error) {
+			return []server.Event{{ID: 1, BabyID: 42, Type: "diaper"}}, nil
+		},
+		countEventsFunc: func(_ context.Context, babyID int64, filter server.EventFilter) (int64, error) {
+			counted = true
+			if babyID != 42 || strings.Join(filter.Types, ",") != "diaper" || filter.Limit != 0 || filter.Offset != 0 {
+				t.Fatalf("expected an unpaged diaper count for baby 42, got %d %+v", babyID, filter)
+			}
+			return 37, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events?type=diaper&offset=3&with_total=true", nil)
+	rr := httptest.NewRecorder()
+	server.NewRouter(store).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"total":37`) {
+		t.Fatalf("expected total 37, got %s", rr.Body.String())
+	}
+
+	counted = false
+	req = httptest.NewRequest(http.MethodGet, "/v1/babies/42/events?type=diaper", nil)
+	rr = httptest.NewRecorder()
+	server.NewRouter(store).ServeHTTP(rr, req)
+
+	if counted || strings.Contains(rr.Body.String(), `"total"`) {
+		t.Fatalf("expected no count without with_total, got %s", rr.Body.String())
+	}
+}
+
+func TestListEventsCursor(t *testing.T) {
+	t.Parallel()
+
+	occurredAt := mustParseRFC3339(t, "2026-02-26T08:00:00.123456Z")
+	router := server.NewRouter(stubBabyStore{
+		listEventsFunc: func(_ context.Context, _ int64, filter server.EventFilter) ([]server.Event, error) {
+			if filter.After == nil {
+				return []server.Event{
+					{ID: 1, BabyID: 42, Type: "diaper", OccurredAt: occurredAt},
+					{ID: 7, BabyID: 42, Type: "diaper", OccurredAt: occurredAt},
+					{ID: 3, BabyID: 42, Type: "diaper", OccurredAt: occurredAt.Add(time.Hour)},
+				}, nil
+			}
+			if !filter.After.OccurredAt.Equal(occurredAt) || filter.After.ID != 7 || filter.Offset != 0 {
+				t.Fatalf("expected to resume after event 7, got %+v", filter)
+			}
+			return []server.Event{{ID: 3, BabyID: 42, Type: "diaper", OccurredAt: occurredAt.Add(time.Hour)}}, nil
+		},
+	})
+
+	type page struct {
+		Data       []server.Event `json:"data"`
+		NextOffset *int           `json:"next_offset"`
+		NextCursor *string        `json:"next_cursor"`
+	}
+	get := func(query string) page {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/events"+query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", query, http.StatusOK, rr.Code)
+		}
+		var got page
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		return got
+	}
+
+	first := get("?limit=2")
+	if len(first.Data) != 2 || first.NextCursor == nil || first.NextOffset == nil || *first.NextOffset != 2 {
+		t.Fatalf("expected two events and both paging fields, got %+v", first)
+	}
+
+	second := get("?limit=2&cursor=" + *first.NextCursor)
+	if len(second.Data) != 1 || second.Data[0].ID != 3 || second.NextCursor != nil || second.NextOffset != nil {
+		t.Fatalf("expected the last event and no further page, got %+v", second)
+	}
+}
+
+func TestListEventsInvalidPaging(t *testing.T) {
+	t.Parallel()
+
+	for _, query := range []string{"?limit=0", "?limit=-1", "?limit=ten", "?offset=-1", "?offset=x", "?cursor=!!", "?cursor=bm9wZQ", "?cursor=MjAyNi0wMi0yNlQwODowMDowMFp8MQ&offset=5", "?with_total=maybe"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events"+query, nil)
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status %d, got %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestListEventsUnknownType(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events?type=nursing&type=bottle", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestCountEvents(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events/count", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		countEventsFunc: func(_ context.Context, babyID int64, _ server.EventFilter) (int64, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			return 17, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data struct {
+			Count int64 `json:"count"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Data.Count != 17 {
+		t.Fatalf("expected count 17, got %d", got.Data.Count)
+	}
+}
+
+func TestCountEventsStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events/count", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		countEventsFunc: func(context.Context, int64, server.EventFilter) (int64, error) {
+			return 0, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestListEventsStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listEventsFunc: func(_ context.Context, _ int64, _ server.EventFilter) ([]server.Event, error) {
+			return nil, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestGetEvent(t *testing.T) {
+	t.Parallel()
+
+	router := server.NewRouter(stubBabyStore{
+		getEventFunc: func(_ context.Context, babyID, eventID int64) (server.Event, error) {
+			if babyID != 42 || eventID != 100 {
+				return server.Event{}, server.ErrNotFound
+			}
+			return server.Event{ID: 100, BabyID: 42, Type: "diaper", Details: json.RawMessage(`{}`)}, nil
+		},
+	})
+
+	for _, tc := range []struct {
+		path   string
+		status int
+	}{
+		{path: "/v1/babies/42/events/100", status: http.StatusOK},
+		{path: "/v1/babies/43/events/100", status: http.StatusNotFound},
+		{path: "/v1/babies/42/events/101", status: http.StatusNotFound},
+		{path: "/v1/babies/42/events/abc", status: http.StatusBadRequest},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		if rr.Code != tc.status {
+			t.Fatalf("%s: expected status %d, got %d", tc.path, tc.status, rr.Code)
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+
+		var got struct {
+			Data server.Event `json:"data"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if got.Data.ID != 100 || got.Data.BabyID != 42 {
+			t.Fatalf("unexpected event %+v", got.Data)
+		}
+	}
+}
+
+func TestGetEventHistory(t *testing.T) {
+	t.Parallel()
+
+	actor := "dad"
+	router := server.NewRouter(stubBabyStore{
+		historyFunc: func(_ context.Context, babyID, eventID int64) ([]server.EventAuditEntry, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			if eventID == 9 {
+				return nil, server.ErrNotFound
+			}
+			return []server.EventAuditEntry{
+				{Action: "create", ChangedAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z")},
+				{Action: "update", Actor: &actor, ChangedAt: mustParseRFC3339(t, "2026-02-26T11:00:00Z")},
+			}, nil
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/events/7/history", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	want := `{"data":[{"action":"create","actor":null,"changed_at":"2026-02-26T10:00:00Z"},{"action":"update","actor":"dad","changed_at":"2026-02-26T11:00:00Z"}]}`
+	if got := strings.TrimSpace(rr.Body.String()); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/events/9/history", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d for a missing event, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestCreateEventDiaper(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+		"type": "diaper",
+		"occurred_at": "2026-02-26T10:00:00Z",
+		"notes": "quick change"
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	store := stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			if input.BabyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", input.BabyID)
+			}
+			if input.Type != "diaper" {
+				t.Fatalf("expected type diaper, got %q", input.Type)
+			}
+			if string(input.Details) != `{"notes":"quick change"}` {
+				t.Fatalf("unexpected details %s", input.Details)
+			}
+			return server.Event{
+				ID:         100,
+				BabyID:     input.BabyID,
+				Type:       input.Type,
+				OccurredAt: input.OccurredAt,
+				Details:    input.Details,
+			}, nil
+		},
+	}
+
+	server.NewRouter(store).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+	if got := rr.Header().Get("Location"); got != "/v1/babies/42/events/100" {
+		t.Fatalf("expected Location /v1/babies/42/events/100, got %q", got)
+	}
+
+	var got struct {
+		Data server.Event `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Data.Type != "diaper" {
+		t.Fatalf("expected type diaper, got %q", got.Data.Type)
+	}
+}
+
+func TestCreateEventDiaperKind(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+		"type": "diaper",
+		"occurred_at": "2026-02-26T10:00:00Z",
+		"kind": "Dirty",
+		"notes": "quick change"
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	store := stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			if string(input.Details) != `{"kind":"dirty","notes":"quick change"}` {
+				t.Fatalf("unexpected details %s", input.Details)
+			}
+			return server.Event{ID: 100, BabyID: input.BabyID, Type: input.Type, OccurredAt: input.OccurredAt, Details: input.Details}, nil
+		},
+	}
+
+	server.NewRouter(store).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+}
+
+func TestCreateEventLocationIncludesPathPrefix(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/babies/42/events", strings.NewReader(`{
+		"type": "diaper",
+		"occurred_at": "2026-02-26T10:00:00Z"
+	}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouterWithConfig(stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			return server.Event{ID: 7, BabyID: input.BabyID, Type: input.Type}, nil
+		},
+	}, server.Config{PathPrefix: "/api"}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+	if got := rr.Header().Get("Location"); got != "/api/v1/babies/42/events/7" {
+		t.Fatalf("expected prefixed Location, got %q", got)
+	}
+}
+
+func TestCreateEventBodyErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty", body: "", want: "request body required"},
+		{name: "whitespace", body: " \n\t ", want: "request body required"},
+		{name: "malformed", body: `{"type":`, want: "invalid json body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+			if got := strings.TrimSpace(rr.Body.String()); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCreateEventFormEncoded(t *testing.T) {
+	t.Parallel()
+
+	form := url.Values{
+		"type":        {"diaper"},
+		"occurred_at": {"2026-02-26T10:00:00Z"},
+		"kind":        {"wet"},
+		"notes":       {"after the bath"},
+		"tags":        {"home", "night"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	rr := httptest.NewRecorder()
+
+	var got server.CreateEventInput
+	server.NewRouter(stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			got = input
+			return server.Event{ID: 100, BabyID: input.BabyID, Type: input.Type}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	if got.Type != "diaper" || !got.OccurredAt.Equal(mustParseRFC3339(t, "2026-02-26T10:00:00Z")) {
+		t.Fatalf("unexpected input %+v", got)
+	}
+	if string(got.Details) != `{"kind":"wet","notes":"after the bath"}` {
+		t.Fatalf("unexpected details %s", got.Details)
+	}
+	if !slices.Equal(got.Tags, []string{"home", "night"}) {
+		t.Fatalf("expected both tags, got %v", got.Tags)
+	}
+}
+
+func TestCreateEventContentType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		contentType string
+		body        string
+		want        int
+	}{
+		{contentType: "application/json; charset=utf-8", body: `{"type":"diaper","occurred_at":"2026-02-26T10:00:00Z"}`, want: http.StatusCreated},
+		{contentType: "application/merge-patch+json", body: `{"type":"diaper","occurred_at":"2026-02-26T10:00:00Z"}`, want: http.StatusCreated},
+		{contentType: "application/x-www-form-urlencoded", body: "type=nursing&occurred_at=2026-02-26T10:00:00Z&side=left&duration_minutes=ten", want: http.StatusBadRequest},
+		{contentType: "text/plain", body: `{"type":"diaper","occurred_at":"2026-02-26T10:00:00Z"}`, want: http.StatusUnsupportedMediaType},
+		{contentType: "multipart/form-data; boundary=x", body: "--x--", want: http.StatusUnsupportedMediaType},
+		{contentType: "not a media type", body: "{}", want: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+					return server.Event{ID: 100, BabyID: input.BabyID, Type: input.Type}, nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateEventDiaperInvalidKind(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+		"type": "diaper",
+		"occurred_at": "2026-02-26T10:00:00Z",
+		"kind": "soaked"
+	}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "kind must be wet, dirty, or mixed") {
+		t.Fatalf("expected kind error, got %q", rr.Body.String())
+	}
+}
+
+func TestCreateEventTags(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+		"type": "nursing",
+		"occurred_at": "2026-02-26T10:00:00Z",
+		"side": "left",
+		"duration_minutes": 10,
+		"tags": [" Teething", "travel", "TRAVEL"]
+	}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			if strings.Join(input.Tags, ",") != "teething,travel" {
+				t.Fatalf("expected tags teething,travel, got %v", input.Tags)
+			}
+			return server.Event{ID: 100, BabyID: input.BabyID, Type: input.Type, Tags: input.Tags}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"tags":["teething","travel"]`) {
+		t.Fatalf("expected tags in the response, got %s", rr.Body.String())
+	}
+}
+
+func TestCreateEventInvalidTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tags string
+		want string
+	}{
+		{name: "empty", tags: `["teething", " "]`, want: "tags must be between 1 and 32 characters"},
+		{name: "too long", tags: `["` + strings.Repeat("a", 33) + `"]`, want: "tags must be between 1 and 32 characters"},
+		{name: "too many", tags: `["a","b","c","d","e","f","g","h","i","j","k"]`, want: "at most 10 tags are allowed per event"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+				"type": "diaper",
+				"occurred_at": "2026-02-26T10:00:00Z",
+				"tags": `+tt.tags+`
+			}`))
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+			if got := strings.TrimSpace(rr.Body.String()); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestListRecentEvents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		query     string
+		wantLimit int
+		wantTypes []string
+		want      int
+	}{
+		{name: "default", wantLimit: 20, want: http.StatusOK},
+		{name: "explicit", query: "?limit=5&type=diaper", wantLimit: 5, wantTypes: []string{"diaper"}, want: http.StatusOK},
+		{name: "capped", query: "?limit=500", wantLimit: 100, want: http.StatusOK},
+		{name: "zero", query: "?limit=0", want: http.StatusBadRequest},
+		{name: "not a number", query: "?limit=ten", want: http.StatusBadRequest},
+		{name: "unknown type", query: "?type=bath", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/events/recent"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				listEventsFunc: func(_ context.Context, babyID int64, filter server.EventFilter) ([]server.Event, error) {
+					if babyID != 42 || !filter.Newest || filter.Limit != tt.wantLimit || !slices.Equal(filter.Types, tt.wantTypes) || filter.Offset != 0 || filter.After != nil {
+						t.Fatalf("unexpected filter for baby %d: %+v", babyID, filter)
+					}
+					return []server.Event{
+						{ID: 2, BabyID: 42, Type: "diaper", OccurredAt: mustParseRFC3339(t, "2026-02-26T12:00:00Z")},
+						{ID: 1, BabyID: 42, Type: "diaper", OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z")},
+					}, nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			// No paging fields: the feed asks again rather than paging.
+			var got map[string]json.RawMessage
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if len(got) != 1 || !strings.HasPrefix(string(got["data"]), `[{"id":2,`) {
+				t.Fatalf("expected only the newest-first events, got %s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateEventDisabledType(t *testing.T) {
+	t.Parallel()
+
+	router := server.NewRouterWithConfig(stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			return server.Event{ID: 1, BabyID: input.BabyID, Type: input.Type}, nil
+		},
+	}, server.Config{EventTypes: []string{"diaper"}})
+
+	disabled := httptest.NewRecorder()
+	router.ServeHTTP(disabled, httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+		"type": "nursing",
+		"occurred_at": "2026-02-26T10:00:00Z",
+		"side": "left",
+		"duration_minutes": 10
+	}`)))
+	if disabled.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, disabled.Code)
+	}
+	if !strings.Contains(disabled.Body.String(), "event type nursing is disabled") {
+		t.Fatalf("expected disabled type error, got %q", disabled.Body.String())
+	}
+
+	enabled := httptest.NewRecorder()
+	router.ServeHTTP(enabled, httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+		"type": "diaper",
+		"occurred_at": "2026-02-26T10:00:00Z"
+	}`)))
+	if enabled.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, enabled.Code)
+	}
+}
+
+func TestCreateEventRequiredFields(t *testing.T) {
+	t.Parallel()
+
+	router := server.NewRouterWithConfig(stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			return server.Event{ID: 1, BabyID: input.BabyID, Type: input.Type}, nil
+		},
+	}, server.Config{RequiredFields: map[string][]string{"diaper": {"kind", "notes"}}})
+
+	for body, want := range map[string]int{
+		`{"type":"diaper","occurred_at":"2026-02-26T10:00:00Z","notes":"after feed"}`:              http.StatusBadRequest,
+		`{"type":"diaper","occurred_at":"2026-02-26T10:00:00Z","kind":"wet","notes":"  "}`:         http.StatusBadRequest,
+		`{"type":"diaper","occurred_at":"2026-02-26T10:00:00Z","kind":"wet","notes":"after feed"}`: http.StatusCreated,
+		`{"type":"sleep","start_at":"2026-02-26T10:00:00Z"}`:                                       http.StatusCreated,
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(body)))
+		if rr.Code != want {
+			t.Fatalf("expected status %d for %s, got %d: %s", want, body, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := (server.Config{}).Validate(); err != nil {
+		t.Fatalf("expected zero config to be valid, got %v", err)
+	}
+	if err := (server.Config{EventTypes: []string{"diaper", "sleep"}}).Validate(); err != nil {
+		t.Fatalf("expected known event types to be valid, got %v", err)
+	}
+	if err := (server.Config{EventTypes: []string{"bottle"}}).Validate(); err == nil {
+		t.Fatal("expected an unknown event type to be rejected")
+	}
+	if err := (server.Config{MinWeightKg: 1, MaxWeightKg: 30}).Validate(); err != nil {
+		t.Fatalf("expected weight bounds to be valid, got %v", err)
+	}
+	for _, cfg := range []server.Config{{MinWeightKg: 60}, {MaxWeightKg: 0.2}, {MinWeightKg: 5, MaxWeightKg: 5}} {
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected weight bounds %g-%g to be rejected", cfg.MinWeightKg, cfg.MaxWeightKg)
+		}
+	}
+	if err := (server.Config{RequiredFields: map[string][]string{"diaper": {"kind", "notes"}}}).Validate(); err != nil {
+		t.Fatalf("expected optional diaper fields to be valid required fields, got %v", err)
+	}
+	for _, fields := range []map[string][]string{
+		{"bottle": {"notes"}},
+		{"diaper": {"side"}},
+		{"diaper": {"occurred_at"}},
+	} {
+		if err := (server.Config{RequiredFields: fields}).Validate(); err == nil {
+			t.Fatalf("expected required fields %v to be rejected", fields)
+		}
+	}
+}
+
+func TestCreateEventDuplicateWindow(t *testing.T) {
+	t.Parallel()
+
+	body := `{"type": "diaper", "occurred_at": "2026-02-26T10:00:00Z"}`
+	tests := []struct {
+		name    string
+		query   string
+		key     string
+		claimed bool
+		nearby  error
+		want    int
+		checked bool
+	}{
+		{name: "duplicate", want: http.StatusConflict, checked: true},
+		{name: "no duplicate", nearby: server.ErrNotFound, want: http.StatusCreated, checked: true},
+		{name: "forced", query: "?force=true", want: http.StatusCreated},
+		{name: "new idempotency key", key: "abc", want: http.StatusConflict, checked: true},
+		{name: "replayed idempotency key", key: "abc", claimed: true, want: http.StatusOK},
+		{name: "oversized idempotency key", key: strings.Repeat("k", 256), want: http.StatusBadRequest},
+		{name: "invalid force", query: "?force=maybe", want: http.StatusBadRequest},
+		{name: "lookup failure", nearby: errors.New("boom"), want: http.StatusInternalServerError, checked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events"+tt.query, strings.NewReader(body))
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			rr := httptest.NewRecorder()
+
+			checked := false
+			created := func(input server.CreateEventInput) server.Event {
+				return server.Event{ID: 8, BabyID: input.BabyID, Type: input.Type, OccurredAt: input.OccurredAt}
+			}
+			server.NewRouterWithConfig(stubBabyStore{
+				nearbyEventFunc: func(_ context.Context, babyID int64, eventType string, at time.Time, window time.Duration) (server.Event, error) {
+					checked = true
+					if babyID != 42 || eventType != "diaper" || !at.Equal(mustParseRFC3339(t, "2026-02-26T10:00:00Z")) || window != time.Minute {
+						t.Fatalf("unexpected lookup %d %s %s %s", babyID, eventType, at, window)
+					}
+					return server.Event{ID: 7, Type: "diaper"}, tt.nearby
+				},
+				createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+					return created(input), nil
+				},
+				idempotentFunc: func(_ context.Context, _ string, input server.CreateEventInput) (server.Event, bool, error) {
+					return created(input), !tt.claimed, nil
+				},
+				claimedFunc: func(_ context.Context, babyID int64, key string) (server.Event, error) {
+					if babyID != 42 || key != tt.key {
+						t.Fatalf("unexpected key lookup %d %q", babyID, key)
+					}
+					if !tt.claimed {
+						return server.Event{}, server.ErrNotFound
+					}
+					return created(server.CreateEventInput{BabyID: babyID, Type: "diaper"}), nil
+				},
+			}, server.Config{DuplicateWindow: time.Minute}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+			if checked != tt.checked {
+				t.Fatalf("expected duplicate lookup %v, got %v", tt.checked, checked)
+			}
+			if tt.want == http.StatusConflict && !strings.Contains(rr.Body.String(), "diaper event 7") {
+				t.Fatalf("expected the duplicate to be named, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateEventOverlap(t *testing.T) {
+	t.Parallel()
+
+	openSleep := `{"type": "sleep", "start_at": "2026-02-26T10:00:00Z"}`
+	tests := []struct {
+		name      string
+		body      string
+		query     string
+		key       string
+		claimed   bool
+		overlap   error
+		wantType  string
+		wantStart string
+		wantEnd   string
+		want      int
+		checked   bool
+	}{
+		{name: "sleep overlaps", body: openSleep, wantType: "sleep", wantStart: "2026-02-26T10:00:00Z", want: http.StatusConflict, checked: true},
+		{
+			name:      "ended sleep is free",
+			body:      `{"type": "sleep", "start_at": "2026-02-26T10:00:00Z", "end_at": "2026-02-26T11:30:00Z"}`,
+			overlap:   server.ErrNotFound,
+			wantType:  "sleep",
+			wantStart: "2026-02-26T10:00:00Z",
+			wantEnd:   "2026-02-26T11:30:00Z",
+			want:      http.StatusCreated,
+			checked:   true,
+		},
+		{
+			name:      "nursing spans its duration",
+			body:      `{"type": "nursing", "occurred_at": "2026-02-26T10:00:00Z", "side": "left", "duration_minutes": 15}`,
+			wantType:  "nursing",
+			wantStart: "2026-02-26T10:00:00Z",
+			wantEnd:   "2026-02-26T10:15:00Z",
+			want:      http.StatusConflict,
+			checked:   true,
+		},
+		{name: "diapers are instants", body: `{"type": "diaper", "occurred_at": "2026-02-26T10:00:00Z"}`, want: http.StatusCreated},
+		{name: "forced", body: openSleep, query: "?force=true", want: http.StatusCreated},
+		{name: "new idempotency key", body: openSleep, key: "abc", wantType: "sleep", wantStart: "2026-02-26T10:00:00Z", want: http.StatusConflict, checked: true},
+		{name: "replayed idempotency key", body: openSleep, key: "abc", claimed: true, want: http.StatusOK},
+		{name: "lookup failure", body: openSleep, overlap: errors.New("boom"), wantType: "sleep", wantStart: "2026-02-26T10:00:00Z", want: http.StatusInternalServerError, checked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events"+tt.query, strings.NewReader(tt.body))
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			rr := httptest.NewRecorder()
+
+			checked := false
+			created := func(input server.CreateEventInput) server.Event {
+				return server.Event{ID: 8, BabyID: input.BabyID, Type: input.Type, OccurredAt: input.OccurredAt}
+			}
+			server.NewRouter(stubBabyStore{
+				overlapFunc: func(_ context.Context, babyID int64, eventType string, start time.Time, end *time.Time) (server.Event, error) {
+					checked = true
+					gotEnd := ""
+					if end != nil {
+						gotEnd = end.UTC().Format(time.RFC3339)
+					}
+					if babyID != 42 || eventType != tt.wantType || !start.Equal(mustParseRFC3339(t, tt.wantStart)) || gotEnd != tt.wantEnd {
+						t.Fatalf("unexpected lookup %d %s %s %q", babyID, eventType, start, gotEnd)
+					}
+					return server.Event{ID: 7, Type: eventType}, tt.overlap
+				},
+				createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+					return created(input), nil
+				},
+				idempotentFunc: func(_ context.Context, _ string, input server.CreateEventInput) (server.Event, bool, error) {
+					return created(input), !tt.claimed, nil
+				},
+				claimedFunc: func(_ context.Context, babyID int64, _ string) (server.Event, error) {
+					if !tt.claimed {
+						return server.Event{}, server.ErrNotFound
+					}
+					return created(server.CreateEventInput{BabyID: babyID, Type: "sleep"}), nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+			if checked != tt.checked {
+				t.Fatalf("expected overlap lookup %v, got %v", tt.checked, checked)
+			}
+			if tt.want == http.StatusConflict && !strings.Contains(rr.Body.String(), tt.wantType+" event 7 overlaps") {
+				t.Fatalf("expected the overlapping event to be named, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateEventIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	seen := map[string]server.Event{}
+	router := server.NewRouter(stubBabyStore{
+		idempotentFunc: func(_ context.Context, key string, input server.CreateEventInput) (server.Event, bool, error) {
+			if event, ok := seen[key]; ok {
+				return event, false, nil
+			}
+			event := server.Event{ID: int64(len(seen) + 1), BabyID: input.BabyID, Type: input.Type}
+			seen[key] = event
+			return event, true, nil
+		},
+	})
+
+	post := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{
+			"type": "diaper",
+			"occurred_at": "2026-02-26T10:00:00Z"
+		}`))
+		req.Header.Set("Idempotency-Key", key)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := post("retry-1")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, first.Code)
+	}
+	replay := post("retry-1")
+	if replay.Code != http.StatusOK {
+		t.Fatalf("expected status %d for a replay, got %d", http.StatusOK, replay.Code)
+	}
+	if replay.Body.String() != first.Body.String() {
+		t.Fatalf("expected the original event, got %s", replay.Body.String())
+	}
+	if other := post("retry-2"); other.Code != http.StatusCreated {
+		t.Fatalf("expected status %d for a new key, got %d", http.StatusCreated, other.Code)
+	}
+	if long := post(strings.Repeat("k", 256)); long.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for an oversized key, got %d", http.StatusBadRequest, long.Code)
+	}
+}
+
+func TestCreateEventNursing(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/7/events", strings.NewReader(`{
+		"type": "nursing",
+		"occurred_at": "2026-02-26T11:15:00Z",
+		"side": "left",
+		"duration_minutes": 18
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	store := stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			if input.Type != "nursing" {
+				t.Fatalf("expected type nursing, got %q", input.Type)
+			}
+			return server.Event{
+				ID:         101,
+				BabyID:     input.BabyID,
+				Type:       input.Type,
+				OccurredAt: input.OccurredAt,
+				Details:    input.Details,
+			}, nil
+		},
+	}
+
+	server.NewRouter(store).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	var got struct {
+		Data server.Event `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Data.Type != "nursing" {
+		t.Fatalf("expected type nursing, got %q", got.Data.Type)
+	}
+}
+
+func TestCreateEventSleep(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/9/events", strings.NewReader(`{
+		"type": "sleep",
+		"start_at": "2026-02-26T12:00:00Z",
+		"end_at": "2026-02-26T13:30:00Z"
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	store := stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			if input.Type != "sleep" {
+				t.Fatalf("expected type sleep, got %q", input.Type)
+			}
+			return server.Event{
+				ID:         102,
+				BabyID:     input.BabyID,
+				Type:       input.Type,
+				OccurredAt: input.OccurredAt,
 				Details:    input.Details,
 			}, nil
 		},
 	}
 
-	server.NewRouter(store).ServeHTTP(rr, req)
+	server.NewRouter(store).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	var got struct {
+		Data server.Event `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Data.Type != "sleep" {
+		t.Fatalf("expected type sleep, got %q", got.Data.Type)
+	}
+}
+
+func TestCreateEventSleepInProgress(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/9/events", strings.NewReader(`{
+		"type": "sleep",
+		"start_at": "2026-02-26T12:00:00Z"
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	store := stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			var details map[string]any
+			if err := json.Unmarshal(input.Details, &details); err != nil {
+				t.Fatalf("failed to unmarshal details: %v", err)
+			}
+			if _, ok := details["end_at"]; ok {
+				t.Fatalf("expected no end_at for a sleep in progress, got %v", details)
+			}
+			return server.Event{ID: 103, BabyID: input.BabyID, Type: input.Type, OccurredAt: input.OccurredAt, Details: input.Details}, nil
+		},
+	}
+
+	server.NewRouter(store).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+}
+
+func TestCreateEventSleepEndBeforeStart(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/9/events", strings.NewReader(`{
+		"type": "sleep",
+		"start_at": "2026-02-26T12:00:00Z",
+		"end_at": "2026-02-26T11:00:00Z"
+	}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestUpdateEventEndsSleep(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/babies/9/events/103", strings.NewReader(`{"end_at": "2026-02-26T13:30:00Z"}`))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		endSleepFunc: func(_ context.Context, babyID, eventID int64, endAt time.Time) (server.Event, error) {
+			if babyID != 9 || eventID != 103 {
+				t.Fatalf("expected baby 9 event 103, got baby %d event %d", babyID, eventID)
+			}
+			if !endAt.Equal(mustParseRFC3339(t, "2026-02-26T13:30:00Z")) {
+				t.Fatalf("unexpected end_at %s", endAt)
+			}
+			return server.Event{ID: eventID, BabyID: babyID, Type: "sleep"}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestCreateEventStoreErrors(t *testing.T) {
+	t.Parallel()
 
-	if rr.Code != http.StatusCreated {
-		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	tests := []struct {
+		name     string
+		storeErr error
+		want     int
+	}{
+		{name: "unknown baby", storeErr: fmt.Errorf("insert event: %w", server.ErrNotFound), want: http.StatusNotFound},
+		{name: "check violation", storeErr: fmt.Errorf("insert event: %w", server.ErrConstraintViolation), want: http.StatusBadRequest},
+		{name: "store failure", storeErr: errors.New("boom"), want: http.StatusInternalServerError},
 	}
 
-	var got struct {
-		Data server.Event `json:"data"`
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/events", strings.NewReader(`{"type":"diaper","occurred_at":"2026-02-26T08:00:00Z"}`))
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				createEventFunc: func(context.Context, server.CreateEventInput) (server.Event, error) {
+					return server.Event{}, tt.storeErr
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
 	}
-	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
-		t.Fatalf("failed to unmarshal response: %v", err)
+}
+
+func TestUpdateEventErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		storeErr error
+		want     int
+	}{
+		{name: "missing end_at", body: `{}`, want: http.StatusBadRequest},
+		{name: "not found", body: `{"end_at":"2026-02-26T13:30:00Z"}`, storeErr: server.ErrNotFound, want: http.StatusNotFound},
+		{name: "already ended", body: `{"end_at":"2026-02-26T13:30:00Z"}`, storeErr: server.ErrSleepEnded, want: http.StatusConflict},
+		{name: "end before start", body: `{"end_at":"2026-02-26T13:30:00Z"}`, storeErr: server.ErrSleepEndBeforeStart, want: http.StatusBadRequest},
+		{name: "store failure", body: `{"end_at":"2026-02-26T13:30:00Z"}`, storeErr: errors.New("boom"), want: http.StatusInternalServerError},
 	}
-	if got.Data.Type != "nursing" {
-		t.Fatalf("expected type nursing, got %q", got.Data.Type)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/v1/babies/9/events/103", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				endSleepFunc: func(_ context.Context, _, _ int64, _ time.Time) (server.Event, error) {
+					return server.Event{}, tt.storeErr
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
 	}
 }
 
-func TestCreateEventSleep(t *testing.T) {
+func TestGetBabyStatus(t *testing.T) {
 	t.Parallel()
 
-	req := httptest.NewRequest(http.MethodPost, "/v1/babies/9/events", strings.NewReader(`{
-		"type": "sleep",
-		"start_at": "2026-02-26T12:00:00Z",
-		"end_at": "2026-02-26T13:30:00Z"
-	}`))
-	req.Header.Set("Content-Type", "application/json")
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/status", nil)
 	rr := httptest.NewRecorder()
 
-	store := stubBabyStore{
-		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
-			if input.Type != "sleep" {
-				t.Fatalf("expected type sleep, got %q", input.Type)
+	lastFeedingAt := mustParseRFC3339(t, "2026-02-26T10:00:00Z")
+	server.NewRouter(stubBabyStore{
+		statusFunc: func(_ context.Context, babyID int64) (server.BabyStatus, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
 			}
-			return server.Event{
-				ID:         102,
-				BabyID:     input.BabyID,
-				Type:       input.Type,
-				OccurredAt: input.OccurredAt,
-				Details:    input.Details,
-			}, nil
+			return server.BabyStatus{LastFeedingAt: &lastFeedingAt}, nil
 		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
 	}
 
-	server.NewRouter(store).ServeHTTP(rr, req)
+	var got struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Data["last_feeding_at"] != "2026-02-26T10:00:00Z" {
+		t.Fatalf("expected last_feeding_at to be set, got %v", got.Data["last_feeding_at"])
+	}
+	if value, ok := got.Data["last_diaper_at"]; !ok || value != nil {
+		t.Fatalf("expected last_diaper_at to be null, got %v", value)
+	}
+	if got.Data["asleep"] != false {
+		t.Fatalf("expected asleep to be false, got %v", got.Data["asleep"])
+	}
+	if value, ok := got.Data["elapsed_minutes"]; !ok || value != nil {
+		t.Fatalf("expected elapsed_minutes to be null when awake, got %v", value)
+	}
+}
 
-	if rr.Code != http.StatusCreated {
-		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+func TestGetBabyStatusSleepElapsedMinutes(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/status", nil)
+	rr := httptest.NewRecorder()
+
+	asleepSince := time.Now().Add(-95 * time.Minute)
+	server.NewRouter(stubBabyStore{
+		statusFunc: func(_ context.Context, _ int64) (server.BabyStatus, error) {
+			return server.BabyStatus{Asleep: true, AsleepSince: &asleepSince}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
 	}
 
 	var got struct {
-		Data server.Event `json:"data"`
+		Data server.BabyStatus `json:"data"`
 	}
 	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
-	if got.Data.Type != "sleep" {
-		t.Fatalf("expected type sleep, got %q", got.Data.Type)
+	if got.Data.ElapsedMinutes == nil || *got.Data.ElapsedMinutes != 95 {
+		t.Fatalf("expected 95 elapsed minutes, got %v", got.Data.ElapsedMinutes)
+	}
+}
+
+func TestGetBabyStatusStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/status", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		statusFunc: func(_ context.Context, _ int64) (server.BabyStatus, error) {
+			return server.BabyStatus{}, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
 	}
 }
 
@@ -373,6 +2532,368 @@ func TestGetBabyReportPDF(t *testing.T) {
 	if !strings.HasPrefix(rr.Body.String(), "%PDF-1.4") {
 		t.Fatalf("expected PDF body header, got %q", rr.Body.String())
 	}
+	checkDownloadHeaders(t, rr)
+}
+
+func TestGetBabyReportPDFInPounds(t *testing.T) {
+	t.Parallel()
+
+	newRouter := func() http.Handler {
+		return server.NewRouter(stubBabyStore{
+			data: []server.Baby{{ID: 42, Name: "Mila"}},
+			listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+				return []server.WeightEntry{
+					{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: 3.44},
+				}, nil
+			},
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	newRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf?unit=lb", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "7.58 lb") {
+		t.Fatalf("expected pound weight in PDF, got %q", rr.Body.String())
+	}
+
+	kg := httptest.NewRecorder()
+	newRouter().ServeHTTP(kg, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil))
+	if !strings.Contains(kg.Body.String(), "3.44 kg") {
+		t.Fatalf("expected kilogram weight in PDF, got %q", kg.Body.String())
+	}
+	if kg.Header().Get("ETag") == rr.Header().Get("ETag") {
+		t.Fatal("expected ETag to differ between units")
+	}
+
+	invalid := httptest.NewRecorder()
+	newRouter().ServeHTTP(invalid, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf?unit=stone", nil))
+	if invalid.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, invalid.Code)
+	}
+}
+
+func TestGetLatestWeight(t *testing.T) {
+	t.Parallel()
+
+	delta := 0.12000000000000011
+	router := server.NewRouter(stubBabyStore{
+		latestWeightFunc: func(_ context.Context, babyID int64) (server.LatestWeight, error) {
+			switch babyID {
+			case 42:
+				return server.LatestWeight{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: 3.56, DeltaKg: &delta}, nil
+			case 43:
+				return server.LatestWeight{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: 3.44}, nil
+			default:
+				return server.LatestWeight{}, server.ErrNotFound
+			}
+		},
+	})
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/v1/babies/42/weights/latest", status: http.StatusOK, body: `{"data":{"occurred_at":"2026-02-26T10:00:00Z","weight_kg":3.56,"delta_kg":0.12}}`},
+		{path: "/v1/babies/42/weights/latest?unit=lb", status: http.StatusOK, body: `{"data":{"occurred_at":"2026-02-26T10:00:00Z","weight_lb":7.85,"delta_lb":0.26}}`},
+		{path: "/v1/babies/43/weights/latest", status: http.StatusOK, body: `{"data":{"occurred_at":"2026-02-26T10:00:00Z","weight_kg":3.44,"delta_kg":null}}`},
+		{path: "/v1/babies/44/weights/latest", status: http.StatusNotFound},
+		{path: "/v1/babies/42/weights/latest?unit=stone", status: http.StatusBadRequest},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		if rr.Code != tc.status {
+			t.Fatalf("%s: expected status %d, got %d", tc.path, tc.status, rr.Code)
+		}
+		if tc.body != "" && strings.TrimSpace(rr.Body.String()) != tc.body {
+			t.Fatalf("%s: expected %s, got %s", tc.path, tc.body, rr.Body.String())
+		}
+	}
+}
+
+func TestGetBabyReportPDFEscapesName(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+	server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Zoë\n) Tj ET (x"}},
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return nil, nil
+		},
+	}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	want := `(Baby: Zo\353\n\) Tj ET \(x \(ID 42\)) Tj`
+	if !strings.Contains(rr.Body.String(), want) {
+		t.Fatalf("expected escaped name %q in PDF, got %q", want, rr.Body.String())
+	}
+}
+
+func TestGetBabyReportBranding(t *testing.T) {
+	t.Parallel()
+
+	store := stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return nil, nil
+		},
+	}
+	router := server.NewRouterWithConfig(store, server.Config{
+		ReportTitle:  "Clínica (Norte)",
+		ReportFooter: "Call 555-0100 ) Tj",
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil))
+	body := rr.Body.String()
+	if !strings.Contains(body, `(Cl\355nica \(Norte\)) Tj`) {
+		t.Fatalf("expected the escaped title in PDF, got %q", body)
+	}
+	if strings.Contains(body, "(Baby Tracker Report)") {
+		t.Fatal("expected the default title to be replaced")
+	}
+	if !strings.Contains(body, `(Call 555-0100 \) Tj) Tj`) {
+		t.Fatalf("expected the escaped footer in PDF, got %q", body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/report", nil)
+	req.Header.Set("Accept", "text/html")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	page := rr.Body.String()
+	if !strings.Contains(page, "<h1>Clínica (Norte)</h1>") || !strings.Contains(page, "<footer>Call 555-0100 ) Tj</footer>") {
+		t.Fatalf("expected the title and footer in HTML, got %q", page)
+	}
+
+	// Branding is part of the content, so it changes the ETag.
+	etags := make(map[string]bool)
+	for _, cfg := range []server.Config{{}, {ReportTitle: "Clinic"}, {ReportFooter: "Footer"}} {
+		rr := httptest.NewRecorder()
+		server.NewRouterWithConfig(store, cfg).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil))
+		etags[rr.Header().Get("ETag")] = true
+	}
+	if len(etags) != 3 {
+		t.Fatalf("expected a distinct ETag per branding, got %v", etags)
+	}
+}
+
+func TestGetBabyReportPDFDefaultTitle(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+	server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return nil, nil
+		},
+	}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil))
+
+	body := rr.Body.String()
+	if !strings.Contains(body, "(Baby Tracker Report) Tj") {
+		t.Fatalf("expected the default title in PDF, got %q", body)
+	}
+	if strings.Contains(body, "/F1 9 Tf") {
+		t.Fatal("expected no footer by default")
+	}
+}
+
+func TestGetBabyReportPDFUsesWinAnsiEncoding(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]string{
+		"Ágata Müller": `(Baby: \301gata M\374ller \(ID 7\)) Tj`,
+		"日向":           `(Baby: ?? \(ID 7\)) Tj`,
+	} {
+		rr := httptest.NewRecorder()
+		server.NewRouter(stubBabyStore{
+			data: []server.Baby{{ID: 7, Name: name}},
+			listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+				return nil, nil
+			},
+		}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/7/report.pdf", nil))
+
+		body := rr.Body.String()
+		if !strings.Contains(body, "/Encoding /WinAnsiEncoding") {
+			t.Fatal("expected the font to declare WinAnsiEncoding")
+		}
+		if !strings.Contains(body, want) {
+			t.Fatalf("%s: expected %q in PDF, got %q", name, want, body)
+		}
+	}
+}
+
+func TestGetCombinedReportPDF(t *testing.T) {
+	t.Parallel()
+
+	router := server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 1, Name: "Mila"}, {ID: 2, Name: "Noah"}},
+		listWeightFunc: func(_ context.Context, babyID int64) ([]server.WeightEntry, error) {
+			return []server.WeightEntry{
+				{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: 3 + float64(babyID)/10},
+			}, nil
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/report.pdf?baby_id=2&baby_id=1&baby_id=2", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Fatalf("expected application/pdf, got %q", got)
+	}
+
+	body := rr.Body.String()
+	if !strings.Contains(body, "/Count 2 /Kids [4 0 R 6 0 R]") {
+		t.Fatalf("expected two pages, got %q", body)
+	}
+	noah, mila := strings.Index(body, `(Baby: Noah \(ID 2\))`), strings.Index(body, `(Baby: Mila \(ID 1\))`)
+	if noah == -1 || mila == -1 || noah > mila {
+		t.Fatalf("expected Noah's page before Mila's, got %q", body)
+	}
+	if !strings.Contains(body, "3.20 kg") || !strings.Contains(body, "3.10 kg") {
+		t.Fatalf("expected both babies' weights, got %q", body)
+	}
+
+	for _, tc := range []struct {
+		query  string
+		status int
+	}{
+		{query: "", status: http.StatusBadRequest},
+		{query: "?baby_id=abc", status: http.StatusBadRequest},
+		{query: "?baby_id=1&unit=stone", status: http.StatusBadRequest},
+		{query: "?baby_id=1&baby_id=3", status: http.StatusNotFound},
+		{query: "?baby_id=1&baby_id=2&baby_id=3&baby_id=4&baby_id=5&baby_id=6&baby_id=7&baby_id=8&baby_id=9&baby_id=10&baby_id=11", status: http.StatusBadRequest},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/report.pdf"+tc.query, nil))
+		if rr.Code != tc.status {
+			t.Fatalf("%q: expected status %d, got %d", tc.query, tc.status, rr.Code)
+		}
+	}
+}
+
+func TestGetBabyReportNegotiatesHTML(t *testing.T) {
+	t.Parallel()
+
+	router := server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: `<script>alert("x")</script>`}},
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return []server.WeightEntry{
+				{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: 3.44},
+			}, nil
+		},
+	})
+
+	for _, tc := range []struct {
+		path        string
+		accept      string
+		contentType string
+	}{
+		{path: "/v1/babies/42/report", accept: "text/html,application/xhtml+xml,*/*;q=0.8", contentType: "text/html; charset=utf-8"},
+		{path: "/v1/babies/42/report", accept: "application/pdf, text/html;q=0.5", contentType: "application/pdf"},
+		{path: "/v1/babies/42/report", accept: "", contentType: "application/pdf"},
+		{path: "/v1/babies/42/report", accept: "*/*", contentType: "application/pdf"},
+		{path: "/v1/babies/42/report.pdf", accept: "text/html", contentType: "application/pdf"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s %q: expected status %d, got %d", tc.path, tc.accept, http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != tc.contentType {
+			t.Fatalf("%s %q: expected content type %q, got %q", tc.path, tc.accept, tc.contentType, got)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/report", nil)
+	req.Header.Set("Accept", "text/html")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	if strings.Contains(body, "<script>") || !strings.Contains(body, "&lt;script&gt;") {
+		t.Fatalf("expected the baby name to be escaped, got %q", body)
+	}
+	if !strings.Contains(body, "3.44 kg") {
+		t.Fatalf("expected weight entry in HTML, got %q", body)
+	}
+	if got := rr.Header().Get("Vary"); !strings.Contains(got, "Accept") {
+		t.Fatalf("expected Vary to include Accept, got %q", got)
+	}
+}
+
+func TestGetBabyReportPDFETag(t *testing.T) {
+	t.Parallel()
+
+	weight := 3.44
+	router := server.NewRouter(stubBabyStore{
+		data: []server.Baby{{ID: 42, Name: "Mila"}},
+		listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+			return []server.WeightEntry{
+				{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: weight},
+			}, nil
+		},
+	})
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil))
+
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil))
+	if got := second.Header().Get("ETag"); got != etag {
+		t.Fatalf("expected stable ETag %q, got %q", etag, got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	cached := httptest.NewRecorder()
+	router.ServeHTTP(cached, req)
+
+	if cached.Code != http.StatusNotModified {
+		t.Fatalf("expected status %d, got %d", http.StatusNotModified, cached.Code)
+	}
+	if cached.Body.Len() != 0 {
+		t.Fatalf("expected empty body for 304, got %d bytes", cached.Body.Len())
+	}
+}
+
+func TestGetBabyReportPDFETagChangesWithData(t *testing.T) {
+	t.Parallel()
+
+	etagFor := func(weightKg float64) string {
+		rr := httptest.NewRecorder()
+		server.NewRouter(stubBabyStore{
+			data: []server.Baby{{ID: 42, Name: "Mila"}},
+			listWeightFunc: func(_ context.Context, _ int64) ([]server.WeightEntry, error) {
+				return []server.WeightEntry{
+					{OccurredAt: mustParseRFC3339(t, "2026-02-26T10:00:00Z"), WeightKg: weightKg},
+				}, nil
+			},
+		}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/report.pdf", nil))
+		return rr.Header().Get("ETag")
+	}
+
+	if etagFor(3.44) == etagFor(3.45) {
+		t.Fatal("expected ETag to change when weight data changes")
+	}
 }
 
 func TestGetBabyReportPDFInvalidBabyID(t *testing.T) {
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxDayRange = 366
+
+type awakeDay struct {
+	Date       string   `json:"date"`
+	AwakeHours *float64 `json:"awake_hours"`
+}
+
+func getAwakeTime(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		fallback, err := babyLocation(r, store, babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby", err)
+			return
+		}
+
+		days, err := parseDayRange(r, fallback)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sessions, err := store.ListSleepSessions(r.Context(), babyID, days.start(), days.end())
+		if err != nil {
+			writeStoreError(w, r, "list sleep sessions", err)
+			return
+		}
+
+		sleepByDay := splitSleepByLocalDay(sessions, days)
+
+		data := make([]awakeDay, 0, days.count)
+		for i := 0; i < days.count; i++ {
+			dayStart, dayEnd := days.day(i)
+			entry := awakeDay{Date: dayStart.Format(time.DateOnly)}
+			if slept, ok := sleepByDay[i]; ok {
+				// Overlapping sessions can add up to more than the day itself.
+				awake := max(dayEnd.Sub(dayStart)-slept, 0)
+				hours := math.Round(awake.Hours()*100) / 100
+				entry.AwakeHours = &hours
+			}
+			data = append(data, entry)
+		}
+
+		writeList(w, r, data)
+	}
+}
+
+// dayRange is an inclusive range of calendar days in a location.
+type dayRange struct {
+	loc   *time.Location
+	first time.Time
+	count int
+}
+
+// parseDayRange reads the from/to (YYYY-MM-DD, inclusive) and tz query
+// parameters. tz defaults to fallback.
+func parseDayRange(r *http.Request, fallback *time.Location) (dayRange, error) {
+	query := r.URL.Query()
+
+	loc := fallback
+	if tz := query.Get("tz"); strings.TrimSpace(tz) != "" {
+		var err error
+		if loc, err = parseLocation(tz); err != nil {
+			return dayRange{}, errors.New("tz must be a valid IANA time zone")
+		}
+	}
+
+	from, err := parseDate(query.Get("from"), loc)
+	if err != nil {
+		return dayRange{}, errors.New("from must be a date in YYYY-MM-DD format")
+	}
+	to, err := parseDate(query.Get("to"), loc)
+	if err != nil {
+		return dayRange{}, errors.New("to must be a date in YYYY-MM-DD format")
+	}
+	if to.Before(from) {
+		return dayRange{}, errors.New("to must not be before from")
+	}
+
+	count := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		count++
+		if count > maxDayRange {
+			return dayRange{}, fmt.Errorf("date range must not exceed %d days", maxDayRange)
+		}
+	}
+
+	return dayRange{loc: loc, first: from, count: count}, nil
+}
+
+// day returns the bounds of the i-th day. Days are not always 24 hours
+// long because of daylight saving transitions.
+func (d dayRange) day(i int) (time.Time, time.Time) {
+	start := time.Date(d.first.Year(), d.first.Month(), d.first.Day()+i, 0, 0, 0, 0, d.loc)
+	end := time.Date(d.first.Year(), d.first.Month(), d.first.Day()+i+1, 0, 0, 0, 0, d.loc)
+	return start, end
+}
+
+func (d dayRange) start() time.Time {
+	start, _ := d.day(0)
+	return start
+}
+
+func (d dayRange) end() time.Time {
+	_, end := d.day(d.count - 1)
+	return end
+}
+
+// splitSleepByLocalDay attributes each session's duration to the local
+// days it spans, splitting at midnight. Days that no session touches are
+// absent from the result.
+func splitSleepByLocalDay(sessions []SleepSession, days dayRange) map[int]time.Duration {
+	totals := make(map[int]time.Duration)
+	for _, session := range sessions {
+		for i := 0; i < days.count; i++ {
+			dayStart, dayEnd := days.day(i)
+			start := maxTime(session.StartAt, dayStart)
+			end := minTime(session.EndAt, dayEnd)
+			if end.After(start) {
+				totals[i] += end.Sub(start)
+			}
+		}
+	}
+	return totals
+}
+
+// sleepDayStats describes the sleeps that started on one local day.
+type sleepDayStats struct {
+	sessions int
+	longest  time.Duration
+}
+
+// sessionsByLocalDay counts each session once, on the local day it
+// started, with its whole duration, so a night's sleep reads as one
+// consolidated session rather than two halves split at midnight. Sessions
+// that started before days are left out.
+func sessionsByLocalDay(sessions []SleepSession, days dayRange) map[int]sleepDayStats {
+	stats := make(map[int]sleepDayStats)
+	for _, session := range sessions {
+		for i := 0; i < days.count; i++ {
+			dayStart, dayEnd := days.day(i)
+			if session.StartAt.Before(dayStart) || !session.StartAt.Before(dayEnd) {
+				continue
+			}
+			day := stats[i]
+			day.sessions++
+			day.longest = max(day.longest, session.EndAt.Sub(session.StartAt))
+			stats[i] = day
+			break
+		}
+	}
+	return stats
+}
+
+// babyLocation returns the location a per-day endpoint uses when the
+// request has no tz: the baby's timezone, or UTC when it has none. An
+// unknown baby also gets UTC, leaving its 404, if any, to the handler.
+// Requests with a tz skip the lookup.
+func babyLocation(r *http.Request, store BabyStore, babyID int64) (*time.Location, error) {
+	if strings.TrimSpace(r.URL.Query().Get("tz")) != "" {
+		return time.UTC, nil
+	}
+	baby, err := store.GetBaby(r.Context(), babyID, BabyFilter{})
+	if errors.Is(err, ErrNotFound) {
+		return time.UTC, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if baby.Timezone == nil {
+		return time.UTC, nil
+	}
+	// Timezones are validated on write, but a tzdata update could still
+	// drop one.
+	loc, err := parseLocation(*baby.Timezone)
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+func parseLocation(value string) (*time.Location, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.UTC, nil
+	}
+	if strings.EqualFold(value, "local") {
+		return nil, errors.New("local time zone is not allowed")
+	}
+	return time.LoadLocation(value)
+}
+
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("date required")
+	}
+	return time.ParseInLocation(time.DateOnly, value, loc)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,140 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestGetAwakeTime(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/awake-time?from=2026-03-01&to=2026-03-03", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listSleepFunc: func(_ context.Context, babyID int64, from, to time.Time) ([]server.SleepSession, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			if !from.Equal(mustParseRFC3339(t, "2026-03-01T00:00:00Z")) || !to.Equal(mustParseRFC3339(t, "2026-03-04T00:00:00Z")) {
+				t.Fatalf("unexpected window %s - %s", from, to)
+			}
+			return []server.SleepSession{
+				{
+					StartAt: mustParseRFC3339(t, "2026-03-01T22:00:00Z"),
+					EndAt:   mustParseRFC3339(t, "2026-03-02T06:00:00Z"),
+				},
+			}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data []struct {
+			Date       string   `json:"date"`
+			AwakeHours *float64 `json:"awake_hours"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(got.Data) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(got.Data))
+	}
+	if got.Data[0].Date != "2026-03-01" || got.Data[0].AwakeHours == nil || *got.Data[0].AwakeHours != 22 {
+		t.Fatalf("expected 22 awake hours on 2026-03-01, got %+v", got.Data[0])
+	}
+	if got.Data[1].Date != "2026-03-02" || got.Data[1].AwakeHours == nil || *got.Data[1].AwakeHours != 18 {
+		t.Fatalf("expected 18 awake hours on 2026-03-02, got %+v", got.Data[1])
+	}
+	if got.Data[2].AwakeHours != nil {
+		t.Fatalf("expected null awake hours for a day without sleep data, got %v", *got.Data[2].AwakeHours)
+	}
+}
+
+func TestGetAwakeTimeSplitsAtLocalMidnight(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/awake-time?from=2026-01-10&to=2026-01-11&tz=America/New_York", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listSleepFunc: func(_ context.Context, _ int64, _, _ time.Time) ([]server.SleepSession, error) {
+			// 22:00-02:00 New York time.
+			return []server.SleepSession{
+				{
+					StartAt: mustParseRFC3339(t, "2026-01-11T03:00:00Z"),
+					EndAt:   mustParseRFC3339(t, "2026-01-11T07:00:00Z"),
+				},
+			}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data []struct {
+			AwakeHours *float64 `json:"awake_hours"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(got.Data) != 2 || got.Data[0].AwakeHours == nil || got.Data[1].AwakeHours == nil {
+		t.Fatalf("expected awake hours for both days, got %s", rr.Body.String())
+	}
+	if *got.Data[0].AwakeHours != 22 || *got.Data[1].AwakeHours != 22 {
+		t.Fatalf("expected 22 awake hours on both days, got %v and %v", *got.Data[0].AwakeHours, *got.Data[1].AwakeHours)
+	}
+}
+
+func TestGetAwakeTimeInvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	for _, query := range []string{
+		"",
+		"?from=2026-03-01",
+		"?from=2026-03-02&to=2026-03-01",
+		"?from=2026-03-01&to=2026-03-02&tz=Mars/Olympus",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/awake-time"+query, nil)
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d for %q, got %d", http.StatusBadRequest, query, rr.Code)
+		}
+	}
+}
+
+func TestGetAwakeTimeStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/awake-time?from=2026-03-01&to=2026-03-01", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listSleepFunc: func(_ context.Context, _ int64, _, _ time.Time) ([]server.SleepSession, error) {
+			return nil, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
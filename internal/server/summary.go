@@ -0,0 +1,258 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type dailySummary struct {
+	Date           string `json:"date"`
+	Diapers        int    `json:"diapers"`
+	WetDiapers     int    `json:"wet_diapers"`
+	DirtyDiapers   int    `json:"dirty_diapers"`
+	MixedDiapers   int    `json:"mixed_diapers"`
+	Feeds          int    `json:"feeds"`
+	NursingMinutes int    `json:"nursing_minutes"`
+	SleepMinutes   int    `json:"sleep_minutes"`
+	// SleepSessions and LongestSleepMinutes cover sleeps that started in
+	// the period; a sleep still in progress counts as lasting until now.
+	// Few long sessions mean consolidated sleep, many short ones
+	// fragmented sleep.
+	SleepSessions       int `json:"sleep_sessions"`
+	LongestSleepMinutes int `json:"longest_sleep_minutes"`
+}
+
+// summaryBucket is the period summary rows cover.
+type summaryBucket string
+
+const (
+	bucketDay   summaryBucket = "day"
+	bucketWeek  summaryBucket = "week"
+	bucketMonth summaryBucket = "month"
+)
+
+// parseSummaryBucket reads the bucket query parameter. It defaults to day.
+func parseSummaryBucket(value string) (summaryBucket, error) {
+	switch bucket := summaryBucket(normalizeKeyword(value)); bucket {
+	case "", bucketDay:
+		return bucketDay, nil
+	case bucketWeek, bucketMonth:
+		return bucket, nil
+	default:
+		return "", fmt.Errorf("bucket must be one of %s, %s, %s", bucketDay, bucketWeek, bucketMonth)
+	}
+}
+
+// start returns the first day of the bucket holding day, matching
+// Postgres date_trunc: weeks start on Monday.
+func (b summaryBucket) start(day time.Time) time.Time {
+	switch b {
+	case bucketWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case bucketMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// activityDay is the number of events logged on one local day.
+type activityDay struct {
+	Date   string `json:"date"`
+	Events int64  `json:"events"`
+}
+
+// getActivity counts every event per local day over an inclusive range,
+// listing days without events with a zero so heatmaps need no gap
+// filling.
+func getActivity(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		fallback, err := babyLocation(r, store, babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby", err)
+			return
+		}
+
+		days, err := parseDayRange(r, fallback)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		counts, err := store.EventCountsByLocalDate(r.Context(), babyID, days.start(), days.end(), days.loc.String())
+		if err != nil {
+			writeStoreError(w, r, "event counts by local date", err)
+			return
+		}
+
+		data := make([]activityDay, 0, days.count)
+		for i := 0; i < days.count; i++ {
+			dayStart, _ := days.day(i)
+			date := dayStart.Format(time.DateOnly)
+			data = append(data, activityDay{Date: date, Events: counts[date]})
+		}
+
+		writeList(w, r, data)
+	}
+}
+
+// getDailySummary reports per-day totals over an inclusive range of local
+// days, so a feed at 11pm in New York counts towards that day rather than
+// the next UTC one. Every day in the range is listed, with zeros when
+// nothing was logged. With bucket=week or bucket=month the days are
+// summed per week or month instead, dated by the bucket's first day even
+// when that falls before from.
+func getDailySummary(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		fallback, err := babyLocation(r, store, babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby", err)
+			return
+		}
+
+		days, err := parseDayRange(r, fallback)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		bucket, err := parseSummaryBucket(r.URL.Query().Get("bucket"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := summarizeDays(r.Context(), store, babyID, days, bucket)
+		if err != nil {
+			writeStoreError(w, r, "daily summary", err)
+			return
+		}
+
+		writeList(w, r, data)
+	}
+}
+
+// getTodaySummary reports the summary totals for the current local day in
+// tz, which defaults to the baby's timezone and then to UTC.
+func getTodaySummary(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		loc, err := babyLocation(r, store, babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby", err)
+			return
+		}
+		if tz := r.URL.Query().Get("tz"); strings.TrimSpace(tz) != "" {
+			if loc, err = parseLocation(tz); err != nil {
+				http.Error(w, "tz must be a valid IANA time zone", http.StatusBadRequest)
+				return
+			}
+		}
+
+		now := time.Now().In(loc)
+		today := dayRange{loc: loc, first: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), count: 1}
+		data, err := summarizeDays(r.Context(), store, babyID, today, bucketDay)
+		if err != nil {
+			writeStoreError(w, r, "today summary", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data[0]})
+	}
+}
+
+// summarizeDays builds one summary row per bucket over days, with zeros
+// for days nothing was logged on.
+func summarizeDays(ctx context.Context, store BabyStore, babyID int64, days dayRange, bucket summaryBucket) ([]dailySummary, error) {
+	counts, err := store.DailyCounts(ctx, babyID, days.start(), days.end(), days.loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("daily counts: %w", err)
+	}
+
+	sessions, err := store.ListSleepSessions(ctx, babyID, days.start(), days.end())
+	if err != nil {
+		return nil, fmt.Errorf("list sleep sessions: %w", err)
+	}
+	sleepByDay := splitSleepByLocalDay(sessions, days)
+	sessionsByDay := sessionsByLocalDay(sessions, days)
+
+	data := make([]dailySummary, 0, days.count)
+	for i := 0; i < days.count; i++ {
+		dayStart, _ := days.day(i)
+		day := counts[dayStart.Format(time.DateOnly)]
+
+		// Days are consecutive, so a bucket's days are too.
+		date := bucket.start(dayStart).Format(time.DateOnly)
+		if len(data) == 0 || data[len(data)-1].Date != date {
+			data = append(data, dailySummary{Date: date})
+		}
+		row := &data[len(data)-1]
+		row.Diapers += day.Diapers
+		row.WetDiapers += day.WetDiapers
+		row.DirtyDiapers += day.DirtyDiapers
+		row.MixedDiapers += day.MixedDiapers
+		row.Feeds += day.Feeds
+		row.NursingMinutes += day.NursingMinutes
+		row.SleepMinutes += int(sleepByDay[i].Minutes())
+		row.SleepSessions += sessionsByDay[i].sessions
+		row.LongestSleepMinutes = max(row.LongestSleepMinutes, int(sessionsByDay[i].longest.Minutes()))
+	}
+
+	return data, nil
+}
+
+// getBabyStats reports lifetime totals for a baby. Minutes are rounded to
+// one decimal.
+func getBabyStats(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		data, err := store.BabyStats(r.Context(), babyID)
+		if err != nil {
+			writeStoreError(w, r, "baby stats", err)
+			return
+		}
+
+		if data.EventCounts == nil {
+			data.EventCounts = make(map[string]int64, len(eventTypes))
+		}
+		for _, eventType := range eventTypes {
+			if _, ok := data.EventCounts[eventType]; !ok {
+				data.EventCounts[eventType] = 0
+			}
+		}
+		for _, minutes := range []*float64{data.AverageNursingMinutes, data.LongestSleepMinutes} {
+			if minutes != nil {
+				*minutes = math.Round(*minutes*10) / 10
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
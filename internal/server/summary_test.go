@@ -0,0 +1,398 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestGetDailySummary(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/summary?from=2026-01-10&to=2026-01-11&tz=America/New_York", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		dailyCountsFunc: func(_ context.Context, babyID int64, from, to time.Time, tz string) (map[string]server.DailyCounts, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			if tz != "America/New_York" {
+				t.Fatalf("expected tz America/New_York, got %q", tz)
+			}
+			if !from.Equal(mustParseRFC3339(t, "2026-01-10T05:00:00Z")) || !to.Equal(mustParseRFC3339(t, "2026-01-12T05:00:00Z")) {
+				t.Fatalf("unexpected window %s - %s", from, to)
+			}
+			return map[string]server.DailyCounts{
+				"2026-01-10": {Diapers: 3, WetDiapers: 2, DirtyDiapers: 1, Feeds: 2, NursingMinutes: 35},
+			}, nil
+		},
+		listSleepFunc: func(_ context.Context, _ int64, _, _ time.Time) ([]server.SleepSession, error) {
+			// 22:00-02:00 New York time.
+			return []server.SleepSession{
+				{
+					StartAt: mustParseRFC3339(t, "2026-01-11T03:00:00Z"),
+					EndAt:   mustParseRFC3339(t, "2026-01-11T07:00:00Z"),
+				},
+			}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data []struct {
+			Date           string `json:"date"`
+			Diapers        int    `json:"diapers"`
+			WetDiapers     int    `json:"wet_diapers"`
+			DirtyDiapers   int    `json:"dirty_diapers"`
+			MixedDiapers   int    `json:"mixed_diapers"`
+			Feeds          int    `json:"feeds"`
+			NursingMinutes int    `json:"nursing_minutes"`
+			SleepMinutes   int    `json:"sleep_minutes"`
+			SleepSessions  int    `json:"sleep_sessions"`
+			LongestSleep   int    `json:"longest_sleep_minutes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(got.Data) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(got.Data))
+	}
+	first, second := got.Data[0], got.Data[1]
+	if first.Date != "2026-01-10" || first.Diapers != 3 || first.WetDiapers != 2 || first.DirtyDiapers != 1 || first.MixedDiapers != 0 || first.Feeds != 2 || first.NursingMinutes != 35 || first.SleepMinutes != 120 || first.SleepSessions != 1 || first.LongestSleep != 240 {
+		t.Fatalf("unexpected first day %+v", first)
+	}
+	if second.Date != "2026-01-11" || second.Diapers != 0 || second.Feeds != 0 || second.SleepMinutes != 120 || second.SleepSessions != 0 || second.LongestSleep != 0 {
+		t.Fatalf("unexpected second day %+v", second)
+	}
+}
+
+func TestGetDailySummaryBuckets(t *testing.T) {
+	t.Parallel()
+
+	router := server.NewRouter(stubBabyStore{
+		dailyCountsFunc: func(context.Context, int64, time.Time, time.Time, string) (map[string]server.DailyCounts, error) {
+			return map[string]server.DailyCounts{
+				"2026-01-31": {Diapers: 2, Feeds: 1},
+				"2026-02-01": {Diapers: 1},
+				"2026-02-03": {Diapers: 4, NursingMinutes: 20},
+			}, nil
+		},
+		listSleepFunc: func(context.Context, int64, time.Time, time.Time) ([]server.SleepSession, error) {
+			return []server.SleepSession{
+				{StartAt: mustParseRFC3339(t, "2026-02-01T23:00:00Z"), EndAt: mustParseRFC3339(t, "2026-02-02T01:00:00Z")},
+			}, nil
+		},
+	})
+
+	// 2026-01-30 is a Friday.
+	for bucket, want := range map[string]string{
+		"week":  `[{"date":"2026-01-26","diapers":3,"wet_diapers":0,"dirty_diapers":0,"mixed_diapers":0,"feeds":1,"nursing_minutes":0,"sleep_minutes":60,"sleep_sessions":1,"longest_sleep_minutes":120},{"date":"2026-02-02","diapers":4,"wet_diapers":0,"dirty_diapers":0,"mixed_diapers":0,"feeds":0,"nursing_minutes":20,"sleep_minutes":60,"sleep_sessions":0,"longest_sleep_minutes":0}]`,
+		"month": `[{"date":"2026-01-01","diapers":2,"wet_diapers":0,"dirty_diapers":0,"mixed_diapers":0,"feeds":1,"nursing_minutes":0,"sleep_minutes":0,"sleep_sessions":0,"longest_sleep_minutes":0},{"date":"2026-02-01","diapers":5,"wet_diapers":0,"dirty_diapers":0,"mixed_diapers":0,"feeds":0,"nursing_minutes":20,"sleep_minutes":120,"sleep_sessions":1,"longest_sleep_minutes":120}]`,
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/summary?from=2026-01-30&to=2026-02-03&bucket="+bucket, nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", bucket, http.StatusOK, rr.Code)
+		}
+		var got struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: failed to unmarshal response: %v", bucket, err)
+		}
+		if string(got.Data) != want {
+			t.Fatalf("%s: expected %s, got %s", bucket, want, got.Data)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/babies/42/summary?from=2026-01-30&to=2026-02-03&bucket=year", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for an unknown bucket, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestGetDailySummaryDefaultsToUTC(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/summary?from=2026-01-10&to=2026-01-10", nil)
+	rr := httptest.NewRecorder()
+
+	var gotTZ string
+	server.NewRouter(stubBabyStore{
+		dailyCountsFunc: func(_ context.Context, _ int64, _, _ time.Time, tz string) (map[string]server.DailyCounts, error) {
+			gotTZ = tz
+			return nil, nil
+		},
+		listSleepFunc: func(_ context.Context, _ int64, _, _ time.Time) ([]server.SleepSession, error) {
+			return nil, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if gotTZ != "UTC" {
+		t.Fatalf("expected tz UTC, got %q", gotTZ)
+	}
+}
+
+func TestSummaryDefaultsToBabyTimezone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "summary", path: "/v1/babies/42/summary?from=2026-01-10&to=2026-01-10", want: "America/New_York"},
+		{name: "today", path: "/v1/babies/42/today", want: "America/New_York"},
+		{name: "tz wins", path: "/v1/babies/42/summary?from=2026-01-10&to=2026-01-10&tz=Asia/Tokyo", want: "Asia/Tokyo"},
+		{name: "no timezone", path: "/v1/babies/7/summary?from=2026-01-10&to=2026-01-10", want: "UTC"},
+	}
+
+	timezone := "America/New_York"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			var gotTZ string
+			server.NewRouter(stubBabyStore{
+				data: []server.Baby{{ID: 42, Name: "Mila", Timezone: &timezone}, {ID: 7, Name: "Noah"}},
+				dailyCountsFunc: func(_ context.Context, _ int64, _, _ time.Time, tz string) (map[string]server.DailyCounts, error) {
+					gotTZ = tz
+					return nil, nil
+				},
+				listSleepFunc: func(_ context.Context, _ int64, _, _ time.Time) ([]server.SleepSession, error) {
+					return nil, nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+			if gotTZ != tt.want {
+				t.Fatalf("expected tz %q, got %q", tt.want, gotTZ)
+			}
+		})
+	}
+}
+
+func TestGetDailySummaryInvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	for _, query := range []string{
+		"from=2026-01-10&to=2026-01-11&tz=Mars/Olympus",
+		"from=2026-01-10&to=2026-01-11&tz=Local",
+		"to=2026-01-11",
+		"from=2026-01-11&to=2026-01-10",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/summary?"+query, nil)
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status %d, got %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestGetDailySummaryStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/summary?from=2026-01-10&to=2026-01-11", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		dailyCountsFunc: func(context.Context, int64, time.Time, time.Time, string) (map[string]server.DailyCounts, error) {
+			return nil, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestGetActivity(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/activity?from=2026-03-01&to=2026-03-03&tz=Europe/Lisbon", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		countsByDateFunc: func(_ context.Context, babyID int64, from, to time.Time, tz string) (map[string]int64, error) {
+			if babyID != 42 || tz != "Europe/Lisbon" {
+				t.Fatalf("unexpected baby %d or tz %q", babyID, tz)
+			}
+			if !from.Equal(mustParseRFC3339(t, "2026-03-01T00:00:00Z")) || !to.Equal(mustParseRFC3339(t, "2026-03-04T00:00:00Z")) {
+				t.Fatalf("unexpected window %s - %s", from, to)
+			}
+			return map[string]int64{"2026-03-01": 7, "2026-03-03": 2}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	want := `{"data":[{"date":"2026-03-01","events":7},{"date":"2026-03-02","events":0},{"date":"2026-03-03","events":2}]}`
+	if got := strings.TrimSpace(rr.Body.String()); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestGetActivityErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		query    string
+		storeErr error
+		want     int
+	}{
+		{name: "missing range", want: http.StatusBadRequest},
+		{name: "invalid tz", query: "?from=2026-03-01&to=2026-03-03&tz=Mars/Olympus", want: http.StatusBadRequest},
+		{name: "store failure", query: "?from=2026-03-01&to=2026-03-03&tz=UTC", storeErr: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/activity"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				countsByDateFunc: func(context.Context, int64, time.Time, time.Time, string) (map[string]int64, error) {
+					return nil, tt.storeErr
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetTodaySummary(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	now := time.Now().In(loc)
+	today := now.Format(time.DateOnly)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/today?tz=Asia/Tokyo", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		dailyCountsFunc: func(_ context.Context, babyID int64, from, to time.Time, tz string) (map[string]server.DailyCounts, error) {
+			if babyID != 42 || tz != "Asia/Tokyo" {
+				t.Fatalf("unexpected baby %d or tz %q", babyID, tz)
+			}
+			if !from.Equal(midnight) || !to.Equal(midnight.AddDate(0, 0, 1)) {
+				t.Fatalf("expected today's window, got %s - %s", from, to)
+			}
+			return map[string]server.DailyCounts{today: {Diapers: 3, WetDiapers: 2, Feeds: 1, NursingMinutes: 15}}, nil
+		},
+		listSleepFunc: func(context.Context, int64, time.Time, time.Time) ([]server.SleepSession, error) {
+			return nil, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	want := `{"date":"` + today + `","diapers":3,"wet_diapers":2,"dirty_diapers":0,"mixed_diapers":0,"feeds":1,"nursing_minutes":15,"sleep_minutes":0,"sleep_sessions":0,"longest_sleep_minutes":0}`
+	if string(got.Data) != want {
+		t.Fatalf("expected %s, got %s", want, got.Data)
+	}
+}
+
+func TestGetTodaySummaryInvalidTZ(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/today?tz=Mars/Olympus", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestGetBabyStats(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/stats", nil)
+	rr := httptest.NewRecorder()
+
+	average := 13.333333
+	server.NewRouter(stubBabyStore{
+		statsFunc: func(_ context.Context, babyID int64) (server.BabyStats, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby id 42, got %d", babyID)
+			}
+			return server.BabyStats{
+				EventCounts:           map[string]int64{"diaper": 5, "nursing": 3},
+				AverageNursingMinutes: &average,
+			}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	want := `{"data":{"event_counts":{"diaper":5,"nursing":3,"sleep":0,"weight":0},"average_nursing_minutes":13.3,"longest_sleep_minutes":null}}`
+	if got := strings.TrimSpace(rr.Body.String()); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestGetBabyStatsStoreError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/stats", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		statsFunc: func(context.Context, int64) (server.BabyStats, error) {
+			return server.BabyStats{}, errors.New("boom")
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
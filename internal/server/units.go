@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const poundsPerKilogram = 2.20462262185
+
+// weightUnit is the unit weights are reported in. Weights are always
+// stored in kilograms.
+type weightUnit string
+
+const (
+	unitKilograms weightUnit = "kg"
+	unitPounds    weightUnit = "lb"
+)
+
+// weightEntryLb is a WeightEntry converted to pounds.
+type weightEntryLb struct {
+	ID         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	WeightLb   float64   `json:"weight_lb"`
+}
+
+// latestWeightLb is a LatestWeight converted to pounds.
+type latestWeightLb struct {
+	OccurredAt time.Time `json:"occurred_at"`
+	WeightLb   float64   `json:"weight_lb"`
+	DeltaLb    *float64  `json:"delta_lb"`
+}
+
+// parseWeightUnit reads the unit query parameter. It defaults to kg.
+func parseWeightUnit(value string) (weightUnit, error) {
+	switch unit := weightUnit(strings.ToLower(strings.TrimSpace(value))); unit {
+	case "", unitKilograms:
+		return unitKilograms, nil
+	case unitPounds:
+		return unitPounds, nil
+	default:
+		return "", fmt.Errorf("unit must be one of %s, %s", unitKilograms, unitPounds)
+	}
+}
+
+// fromKilograms converts kg to the unit. Pounds are rounded to two
+// decimals; kilograms are returned as stored.
+func (u weightUnit) fromKilograms(kg float64) float64 {
+	if u == unitPounds {
+		return math.Round(kg*poundsPerKilogram*100) / 100
+	}
+	return kg
+}
+
+// format renders kg in the unit with its label, e.g. "7.58 lb".
+func (u weightUnit) format(kg float64) string {
+	return fmt.Sprintf("%.2f %s", u.fromKilograms(kg), u)
+}
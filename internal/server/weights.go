@@ -0,0 +1,286 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+// growthVelocity is the average daily weight gain over a range of days,
+// between its first and last weight entries, and between each pair of
+// consecutive entries in Intervals.
+type growthVelocity struct {
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Days        float64            `json:"days"`
+	GainGrams   float64            `json:"gain_grams"`
+	GramsPerDay *float64           `json:"grams_per_day"`
+	Intervals   []velocityInterval `json:"intervals"`
+}
+
+// velocityInterval is the gain between two consecutive weight entries.
+// GramsPerDay is null when both were logged at the same instant.
+type velocityInterval struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Days        float64   `json:"days"`
+	GainGrams   float64   `json:"gain_grams"`
+	GramsPerDay *float64  `json:"grams_per_day"`
+}
+
+const (
+	maxBatchWeights = 1000
+
+	// The default weight bounds cover a premature newborn up to any
+	// realistic child. They only catch gross typos, like grams entered
+	// as kilograms: a baby's weight in pounds entered as kilograms still
+	// falls inside them. Only Config.MaxWeightChangePerDay, which is off
+	// by default and skipped for batches and imports, can catch that.
+	defaultMinWeightKg = 0.3
+	defaultMaxWeightKg = 50
+)
+
+type createWeightRequest struct {
+	OccurredAt string  `json:"occurred_at"`
+	WeightKg   float64 `json:"weight_kg"`
+}
+
+// parse validates req against cfg's weight bounds.
+func (req createWeightRequest) parse(cfg Config) (NewWeightEntry, error) {
+	occurredAt, err := parseTimestamp(req.OccurredAt)
+	if err != nil {
+		return NewWeightEntry{}, errors.New("occurred_at must be an RFC3339 timestamp")
+	}
+	if err := cfg.checkWeight(req.WeightKg); err != nil {
+		return NewWeightEntry{}, err
+	}
+	return NewWeightEntry{OccurredAt: occurredAt, WeightKg: req.WeightKg}, nil
+}
+
+// weightBounds returns the smallest and largest weight, in kg, that may
+// be logged.
+func (cfg Config) weightBounds() (float64, float64) {
+	return cmp.Or(cfg.MinWeightKg, defaultMinWeightKg), cmp.Or(cfg.MaxWeightKg, defaultMaxWeightKg)
+}
+
+// checkWeight rejects a weight outside cfg's bounds.
+func (cfg Config) checkWeight(weightKg float64) error {
+	minKg, maxKg := cfg.weightBounds()
+	if weightKg < minKg || weightKg > maxKg {
+		return fmt.Errorf("weight_kg must be between %g and %g", minKg, maxKg)
+	}
+	return nil
+}
+
+// createWeightEntry logs a weight as a weight event. With
+// Config.MaxWeightChangePerDay set, a weight that moved too fast since the
+// previous entry is rejected with 409 unless force=true.
+func createWeightEntry(store BabyStore, cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		var req createWeightRequest
+		if err := decodeJSONBody(r.Body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		entry, err := req.parse(cfg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		force, err := parseForce(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if cfg.MaxWeightChangePerDay > 0 && !force {
+			previous, err := store.PreviousWeight(r.Context(), babyID, entry.OccurredAt)
+			switch {
+			case err == nil:
+				if msg := implausibleWeightChange(previous, entry.WeightKg, entry.OccurredAt, cfg.MaxWeightChangePerDay); msg != "" {
+					http.Error(w, msg, http.StatusConflict)
+					return
+				}
+			case !errors.Is(err, ErrNotFound):
+				writeStoreError(w, r, "previous weight", err)
+				return
+			}
+		}
+
+		details, err := json.Marshal(map[string]any{"weight_kg": entry.WeightKg})
+		if err != nil {
+			http.Error(w, "failed to encode details", http.StatusBadRequest)
+			return
+		}
+
+		event, err := store.CreateEvent(r.Context(), CreateEventInput{
+			BabyID:     babyID,
+			Type:       "weight",
+			OccurredAt: entry.OccurredAt,
+			Details:    details,
+		})
+		if err != nil {
+			writeCreateEventError(w, r, "create weight entry", err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, map[string]any{"data": WeightEntry{
+			ID:         event.ID,
+			OccurredAt: event.OccurredAt,
+			WeightKg:   entry.WeightKg,
+		}})
+	}
+}
+
+// createWeightEntriesBatch logs weights exported from a scale app. Like
+// createEventsBatch, it validates every element first and answers 400
+// with the index of each invalid one, inserting nothing; otherwise all
+// are inserted in one transaction. Config.MaxWeightChangePerDay is not
+// applied, as the entries are measurements already taken.
+func createWeightEntriesBatch(store BabyStore, cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		var reqs []createWeightRequest
+		if err := decodeJSONBody(r.Body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(reqs) == 0 {
+			http.Error(w, "batch must contain at least one weight", http.StatusBadRequest)
+			return
+		}
+		if len(reqs) > maxBatchWeights {
+			http.Error(w, fmt.Sprintf("batch must not exceed %d weights", maxBatchWeights), http.StatusBadRequest)
+			return
+		}
+
+		entries := make([]NewWeightEntry, 0, len(reqs))
+		var failures []batchItemError
+		for i, req := range reqs {
+			entry, err := req.parse(cfg)
+			if err != nil {
+				failures = append(failures, batchItemError{Index: i, Error: err.Error()})
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		if len(failures) > 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"errors": failures})
+			return
+		}
+
+		data, err := store.CreateWeightEntries(r.Context(), babyID, entries)
+		if err != nil {
+			writeCreateEventError(w, r, "create weight entries", err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, map[string]any{"data": data})
+	}
+}
+
+// implausibleWeightChange describes why weightKg at occurredAt is too far
+// from previous, or returns "" when it is within maxPercentPerDay for the
+// days between them, counting at least one.
+func implausibleWeightChange(previous WeightEntry, weightKg float64, occurredAt time.Time, maxPercentPerDay float64) string {
+	days := max(occurredAt.Sub(previous.OccurredAt).Hours()/24, 1)
+	change := (weightKg - previous.WeightKg) / previous.WeightKg * 100
+	if math.Abs(change) <= maxPercentPerDay*days {
+		return ""
+	}
+	return fmt.Sprintf("weight changed %+.1f%% from %.3f kg on %s, more than %g%% per day; set force=true to log it anyway",
+		change, previous.WeightKg, previous.OccurredAt.UTC().Format(time.DateOnly), maxPercentPerDay)
+}
+
+// getGrowthVelocity reports weight gain in g/day over an inclusive range
+// of local days, as pediatricians track it. It needs two weight entries
+// in the range and answers 422 otherwise. Days and grams are rounded to
+// one decimal.
+func getGrowthVelocity(store BabyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		babyID, err := parseID(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid baby id", http.StatusBadRequest)
+			return
+		}
+
+		fallback, err := babyLocation(r, store, babyID)
+		if err != nil {
+			writeStoreError(w, r, "get baby", err)
+			return
+		}
+
+		days, err := parseDayRange(r, fallback)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		entries, err := store.ListWeightEntries(r.Context(), babyID)
+		if err != nil {
+			writeStoreError(w, r, "list weight entries", err)
+			return
+		}
+
+		var inRange []WeightEntry
+		for _, entry := range entries {
+			if !entry.OccurredAt.Before(days.start()) && entry.OccurredAt.Before(days.end()) {
+				inRange = append(inRange, entry)
+			}
+		}
+		if len(inRange) < 2 {
+			http.Error(w, fmt.Sprintf("growth velocity needs at least two weight entries between from and to, found %d", len(inRange)),
+				http.StatusUnprocessableEntity)
+			return
+		}
+
+		overall := velocityBetween(inRange[0], inRange[len(inRange)-1])
+		data := growthVelocity{
+			From:        overall.From,
+			To:          overall.To,
+			Days:        overall.Days,
+			GainGrams:   overall.GainGrams,
+			GramsPerDay: overall.GramsPerDay,
+			Intervals:   make([]velocityInterval, 0, len(inRange)-1),
+		}
+		for i := 1; i < len(inRange); i++ {
+			data.Intervals = append(data.Intervals, velocityBetween(inRange[i-1], inRange[i]))
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+}
+
+// velocityBetween computes the gain from one weight entry to a later one.
+func velocityBetween(from, to WeightEntry) velocityInterval {
+	elapsed := to.OccurredAt.Sub(from.OccurredAt).Hours() / 24
+	gain := (to.WeightKg - from.WeightKg) * 1000
+	interval := velocityInterval{
+		From:      from.OccurredAt,
+		To:        to.OccurredAt,
+		Days:      math.Round(elapsed*10) / 10,
+		GainGrams: math.Round(gain*10) / 10,
+	}
+	if elapsed > 0 {
+		perDay := math.Round(gain/elapsed*10) / 10
+		interval.GramsPerDay = &perDay
+	}
+	return interval
+}
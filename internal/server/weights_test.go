@@ -0,0 +1,313 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"baby-tracker-server/internal/server"
+)
+
+func TestCreateWeightEntry(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/weights", strings.NewReader(`{"occurred_at":"2026-02-26T10:00:00Z","weight_kg":4.2}`))
+	rr := httptest.NewRecorder()
+
+	var got server.CreateEventInput
+	server.NewRouter(stubBabyStore{
+		createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+			got = input
+			return server.Event{ID: 9, BabyID: input.BabyID, Type: input.Type, OccurredAt: input.OccurredAt}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	if got.BabyID != 42 || got.Type != "weight" || string(got.Details) != `{"weight_kg":4.2}` {
+		t.Fatalf("unexpected input %+v", got)
+	}
+
+	var body struct {
+		Data server.WeightEntry `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if body.Data.ID != 9 || body.Data.WeightKg != 4.2 {
+		t.Fatalf("unexpected weight entry %+v", body.Data)
+	}
+}
+
+func TestCreateWeightEntryInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{
+		`{"weight_kg":4.2}`,
+		`{"occurred_at":"yesterday","weight_kg":4.2}`,
+		`{"occurred_at":"2026-02-26T10:00:00Z"}`,
+		`{"occurred_at":"2026-02-26T10:00:00Z","weight_kg":-1}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/weights", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+
+		server.NewRouter(stubBabyStore{}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d for %s, got %d", http.StatusBadRequest, body, rr.Code)
+		}
+	}
+}
+
+func TestCreateWeightEntryBounds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		cfg    server.Config
+		weight string
+		want   int
+	}{
+		{name: "newborn", weight: "3.2", want: http.StatusCreated},
+		{name: "premature", weight: "0.3", want: http.StatusCreated},
+		{name: "too light", weight: "0.05", want: http.StatusBadRequest},
+		{name: "too heavy", weight: "500", want: http.StatusBadRequest},
+		{name: "pounds as kg within custom bounds", cfg: server.Config{MaxWeightKg: 20}, weight: "22", want: http.StatusBadRequest},
+		{name: "custom minimum", cfg: server.Config{MinWeightKg: 1}, weight: "0.5", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			body := `{"occurred_at":"2026-02-26T10:00:00Z","weight_kg":` + tt.weight + `}`
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/weights", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			server.NewRouterWithConfig(stubBabyStore{
+				createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+					return server.Event{ID: 9, BabyID: input.BabyID, Type: input.Type, OccurredAt: input.OccurredAt}, nil
+				},
+			}, tt.cfg).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+			if tt.want == http.StatusBadRequest && !strings.Contains(rr.Body.String(), "weight_kg must be between") {
+				t.Fatalf("expected the bounds in the error, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateWeightEntryMaxChange(t *testing.T) {
+	t.Parallel()
+
+	// 10% per day against 4.0 kg logged the day before, or a week before.
+	tests := []struct {
+		name     string
+		query    string
+		weight   string
+		previous time.Time
+		lookup   error
+		want     int
+		checked  bool
+	}{
+		{name: "within a day", weight: "4.3", previous: mustParseRFC3339(t, "2026-02-25T10:00:00Z"), want: http.StatusCreated, checked: true},
+		{name: "too fast", weight: "4.5", previous: mustParseRFC3339(t, "2026-02-25T10:00:00Z"), want: http.StatusConflict, checked: true},
+		{name: "too fast down", weight: "3.5", previous: mustParseRFC3339(t, "2026-02-25T10:00:00Z"), want: http.StatusConflict, checked: true},
+		{name: "same hour gets a day", weight: "4.3", previous: mustParseRFC3339(t, "2026-02-26T09:30:00Z"), want: http.StatusCreated, checked: true},
+		{name: "spread over a week", weight: "5.5", previous: mustParseRFC3339(t, "2026-02-19T10:00:00Z"), want: http.StatusCreated, checked: true},
+		{name: "forced", query: "?force=true", weight: "8", want: http.StatusCreated},
+		{name: "first weight", weight: "8", lookup: server.ErrNotFound, want: http.StatusCreated, checked: true},
+		{name: "lookup failure", weight: "4.1", lookup: errors.New("boom"), want: http.StatusInternalServerError, checked: true},
+		{name: "invalid force", query: "?force=maybe", weight: "4.1", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			body := `{"occurred_at":"2026-02-26T10:00:00Z","weight_kg":` + tt.weight + `}`
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/weights"+tt.query, strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			checked := false
+			server.NewRouterWithConfig(stubBabyStore{
+				prevWeightFunc: func(_ context.Context, babyID int64, at time.Time) (server.WeightEntry, error) {
+					checked = true
+					if babyID != 42 || !at.Equal(mustParseRFC3339(t, "2026-02-26T10:00:00Z")) {
+						t.Fatalf("unexpected lookup %d %s", babyID, at)
+					}
+					return server.WeightEntry{ID: 3, OccurredAt: tt.previous, WeightKg: 4}, tt.lookup
+				},
+				createEventFunc: func(_ context.Context, input server.CreateEventInput) (server.Event, error) {
+					return server.Event{ID: 9, BabyID: input.BabyID, Type: input.Type, OccurredAt: input.OccurredAt}, nil
+				},
+			}, server.Config{MaxWeightChangePerDay: 10}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+			if checked != tt.checked {
+				t.Fatalf("expected previous weight lookup %v, got %v", tt.checked, checked)
+			}
+			if tt.want == http.StatusConflict && !strings.Contains(rr.Body.String(), "force=true") {
+				t.Fatalf("expected the conflict to mention force, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateWeightEntriesBatch(t *testing.T) {
+	t.Parallel()
+
+	body := `[{"occurred_at":"2026-02-20T08:00:00Z","weight_kg":3.9},{"occurred_at":"2026-02-26T08:00:00Z","weight_kg":4.2}]`
+	req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/weights:batch", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		createWeightsFunc: func(_ context.Context, babyID int64, entries []server.NewWeightEntry) ([]server.WeightEntry, error) {
+			if babyID != 42 || len(entries) != 2 || entries[1].WeightKg != 4.2 || !entries[0].OccurredAt.Equal(mustParseRFC3339(t, "2026-02-20T08:00:00Z")) {
+				t.Fatalf("unexpected entries for baby %d: %+v", babyID, entries)
+			}
+			data := make([]server.WeightEntry, len(entries))
+			for i, entry := range entries {
+				data[i] = server.WeightEntry{ID: int64(i + 1), OccurredAt: entry.OccurredAt, WeightKg: entry.WeightKg}
+			}
+			return data, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	want := `{"data":[{"id":1,"occurred_at":"2026-02-20T08:00:00Z","weight_kg":3.9},{"id":2,"occurred_at":"2026-02-26T08:00:00Z","weight_kg":4.2}]}`
+	if got := strings.TrimSpace(rr.Body.String()); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestCreateWeightEntriesBatchErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		storeErr error
+		want     int
+		errors   string
+	}{
+		{name: "invalid entries", body: `[{"occurred_at":"2026-02-20T08:00:00Z","weight_kg":3.9},{"weight_kg":4},{"occurred_at":"2026-02-26T08:00:00Z","weight_kg":0}]`, want: http.StatusBadRequest, errors: `{"errors":[{"index":1,"error":"occurred_at must be an RFC3339 timestamp"},{"index":2,"error":"weight_kg must be between 0.3 and 50"}]}`},
+		{name: "empty", body: `[]`, want: http.StatusBadRequest},
+		{name: "too many", body: "[" + strings.Repeat(`{"occurred_at":"2026-02-20T08:00:00Z","weight_kg":3.9},`, 1000) + `{"occurred_at":"2026-02-20T08:00:00Z","weight_kg":3.9}]`, want: http.StatusBadRequest},
+		{name: "not an array", body: `{"occurred_at":"2026-02-20T08:00:00Z","weight_kg":3.9}`, want: http.StatusBadRequest},
+		{name: "unknown baby", body: `[{"occurred_at":"2026-02-20T08:00:00Z","weight_kg":3.9}]`, storeErr: server.ErrNotFound, want: http.StatusNotFound},
+		{name: "store failure", body: `[{"occurred_at":"2026-02-20T08:00:00Z","weight_kg":3.9}]`, storeErr: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/babies/42/weights:batch", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				createWeightsFunc: func(context.Context, int64, []server.NewWeightEntry) ([]server.WeightEntry, error) {
+					if tt.storeErr == nil {
+						t.Fatal("expected nothing to be inserted")
+					}
+					return nil, tt.storeErr
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+			if tt.errors != "" && strings.TrimSpace(rr.Body.String()) != tt.errors {
+				t.Fatalf("expected %s, got %s", tt.errors, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetGrowthVelocity(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/growth-velocity?from=2026-03-01&to=2026-03-11", nil)
+	rr := httptest.NewRecorder()
+
+	server.NewRouter(stubBabyStore{
+		listWeightFunc: func(_ context.Context, babyID int64) ([]server.WeightEntry, error) {
+			if babyID != 42 {
+				t.Fatalf("expected baby 42, got %d", babyID)
+			}
+			return []server.WeightEntry{
+				{ID: 1, OccurredAt: mustParseRFC3339(t, "2026-02-20T10:00:00Z"), WeightKg: 3.2},
+				{ID: 2, OccurredAt: mustParseRFC3339(t, "2026-03-01T10:00:00Z"), WeightKg: 3.5},
+				{ID: 3, OccurredAt: mustParseRFC3339(t, "2026-03-05T10:00:00Z"), WeightKg: 3.62},
+				{ID: 4, OccurredAt: mustParseRFC3339(t, "2026-03-05T10:00:00Z"), WeightKg: 3.64},
+				{ID: 5, OccurredAt: mustParseRFC3339(t, "2026-03-11T22:00:00Z"), WeightKg: 3.89},
+				{ID: 6, OccurredAt: mustParseRFC3339(t, "2026-03-12T08:00:00Z"), WeightKg: 4.5},
+			}, nil
+		},
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	want := `{"data":{"from":"2026-03-01T10:00:00Z","to":"2026-03-11T22:00:00Z","days":10.5,"gain_grams":390,"grams_per_day":37.1,"intervals":[` +
+		`{"from":"2026-03-01T10:00:00Z","to":"2026-03-05T10:00:00Z","days":4,"gain_grams":120,"grams_per_day":30},` +
+		`{"from":"2026-03-05T10:00:00Z","to":"2026-03-05T10:00:00Z","days":0,"gain_grams":20,"grams_per_day":null},` +
+		`{"from":"2026-03-05T10:00:00Z","to":"2026-03-11T22:00:00Z","days":6.5,"gain_grams":250,"grams_per_day":38.5}]}}`
+	if got := strings.TrimSpace(rr.Body.String()); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestGetGrowthVelocityNeedsTwoEntries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "one entry in range", query: "?from=2026-03-01&to=2026-03-02", want: http.StatusUnprocessableEntity},
+		{name: "none in range", query: "?from=2026-04-01&to=2026-04-02", want: http.StatusUnprocessableEntity},
+		{name: "missing range", want: http.StatusBadRequest},
+		{name: "reversed range", query: "?from=2026-03-02&to=2026-03-01", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/babies/42/growth-velocity"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			server.NewRouter(stubBabyStore{
+				listWeightFunc: func(context.Context, int64) ([]server.WeightEntry, error) {
+					return []server.WeightEntry{
+						{ID: 1, OccurredAt: mustParseRFC3339(t, "2026-03-01T10:00:00Z"), WeightKg: 3.5},
+						{ID: 2, OccurredAt: mustParseRFC3339(t, "2026-03-05T10:00:00Z"), WeightKg: 3.6},
+					}, nil
+				},
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+			if tt.want == http.StatusUnprocessableEntity && !strings.Contains(rr.Body.String(), "at least two weight entries") {
+				t.Fatalf("expected an explanation, got %q", rr.Body.String())
+			}
+		})
+	}
+}